@@ -18,7 +18,15 @@ func init() {
 		"\"production\".\n\n" +
 		"The `skeema diff` command is equivalent to running `skeema push` with its --dry-run option enabled.\n\n" +
 		"An exit code of 0 will be returned if no differences were found; 1 if some " +
-		"differences were found; or 2+ if an error occurred."
+		"differences were found; or 2+ if an error occurred.\n\n" +
+		"With --emit-migration, the generated DDL for each schema is also written to a new " +
+		"timestamped migration file in the specified directory, along with a corresponding " +
+		"file of DDL reversing the changes. Use --migration-format to select the migration tool's " +
+		"file naming conventions: \"golang-migrate\" (the default), \"flyway\", or \"goose\". " +
+		"(This option is named --migration-format rather than --format, since option files are " +
+		"shared between commands, and the lint and format commands already have a boolean --format " +
+		"option.) If multiple sharded targets with the same schema name would result in differing " +
+		"migrations, only the first is written, and an error is logged for the others."
 
 	cmd := mybase.NewCommand("diff", summary, desc, DiffHandler)
 	cmd.AddArg("environment", "production", false)
@@ -44,15 +52,20 @@ func clonePushOptionsToDiff() {
 	}
 
 	descRewrites := map[string]string{
-		"allow-unsafe":    "Permit generating ALTER or DROP operations that are potentially destructive",
-		"alter-wrapper":   "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
-		"brief":           "Don't output DDL to STDOUT; instead output list of database servers with at least one difference",
-		"safe-below-size": "Always permit generating destructive operations for tables below this size in bytes",
+		"allow-unsafe":     "Permit generating ALTER or DROP operations that are potentially destructive",
+		"alter-wrapper":    "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
+		"brief":            "Don't output DDL to STDOUT; instead output list of database servers with at least one difference",
+		"safe-below-size":  "Always permit generating destructive operations for tables below this size in bytes",
+		"emit-migration":   "Also write generated DDL to a new versioned migration file pair (up and down) in this directory",
+		"migration-format": `File format for --emit-migration (valid values: "golang-migrate", "flyway", "goose")`,
 	}
 	hiddenRewrites := map[string]bool{
 		"brief":              false,
+		"emit-migration":     false,
+		"migration-format":   false,
 		"dry-run":            true,
 		"foreign-key-checks": true,
+		"history":            true,
 	}
 
 	diffOptions := diff.Options()
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Generate documentation from the filesystem"
+	desc := "Generates documentation of the database objects in the filesystem representation " +
+		"of all schema dirs in the current directory tree, using the comments on tables, " +
+		"columns, indexes, and routines. Documentation is written to the directory " +
+		"specified by --output-dir, either as Markdown files (the default) or static HTML " +
+		"pages. Each schema gets its own subdirectory, containing an index page listing " +
+		"the schema's tables and routines, and one page per table describing its columns, " +
+		"indexes, foreign keys, check constraints, and partitioning.\n\n" +
+		"This command relies on accessing a database server to test the SQL DDL in a " +
+		"temporary location. See the --workspace option for more information.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for workspace selection. If no " +
+		"environment name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("doc", summary, desc, DocHandler)
+	cmd.AddOption(mybase.StringOption("format", 0, "markdown", `Output format (valid values: "markdown", "html")`))
+	cmd.AddOption(mybase.StringOption("output-dir", 0, "", "Directory to write documentation files to (required)"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// DocHandler is the handler method for `skeema doc`
+func DocHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	format, err := dir.Config.GetEnum("format", "markdown", "html")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	outputDir := dir.Config.Get("output-dir")
+	if outputDir == "" {
+		return NewExitValue(CodeBadConfig, "Option --output-dir is required")
+	}
+	newWriter := newMarkdownDocWriter
+	if format == "html" {
+		newWriter = newHTMLDocWriter
+	}
+
+	var schemas []namedSchema
+	if err := namedSchemaWalker(dir, &schemas, 5); err != nil {
+		return err
+	}
+
+	// Schemas with the same name may be present in multiple dirs, for example
+	// when sharding; only the first one is documented.
+	seen := make(map[string]bool, len(schemas))
+	var documented []namedSchema
+	for _, s := range schemas {
+		if seen[s.name] {
+			log.Warnf("Skipping documentation of duplicate schema name %s", s.name)
+			continue
+		}
+		seen[s.name] = true
+		documented = append(documented, s)
+	}
+
+	names := newDocFileNames(documented)
+	w := newWriter()
+	writeDocIndex(w, documented, names)
+	if err := writeDocFile(filepath.Join(outputDir, "index"+w.ext()), w); err != nil {
+		return err
+	}
+	for _, s := range documented {
+		schemaDir := filepath.Join(outputDir, names.schema(s.name))
+		w := newWriter()
+		writeDocSchema(w, s, names)
+		if err := writeDocFile(filepath.Join(schemaDir, "index"+w.ext()), w); err != nil {
+			return err
+		}
+		for _, table := range s.schema.Tables {
+			w := newWriter()
+			writeDocTable(w, s.name, table, names)
+			if err := writeDocFile(filepath.Join(schemaDir, names.table(s.name, table.Name)+w.ext()), w); err != nil {
+				return err
+			}
+		}
+		log.Infof("Wrote documentation for schema %s to %s", s.name, schemaDir)
+	}
+	return nil
+}
+
+// docFileBase returns a file or directory name, without extension, for
+// documentation of the supplied schema or table name. The result is not
+// necessarily unique, since special characters are removed.
+func docFileBase(name string) string {
+	return strings.TrimSuffix(fs.FileNameForObject(name), ".sql")
+}
+
+// docFileNames tracks unique file or directory names, without extension, for
+// the documentation of each schema and table. Names are compared case-
+// insensitively, since some filesystems are case-insensitive.
+type docFileNames struct {
+	schemas map[string]string            // schema name -> dir name
+	tables  map[string]map[string]string // schema name -> table name -> file name
+}
+
+// newDocFileNames assigns a unique file or directory name to each schema in
+// schemas, and to each table within each schema. Within a schema dir, the name
+// "index" is reserved for the schema's index page.
+func newDocFileNames(schemas []namedSchema) docFileNames {
+	names := docFileNames{
+		schemas: make(map[string]string, len(schemas)),
+		tables:  make(map[string]map[string]string, len(schemas)),
+	}
+	usedSchemaNames := make(map[string]bool, len(schemas))
+	for _, s := range schemas {
+		names.schemas[s.name] = uniqueDocFileBase(s.name, usedSchemaNames)
+		usedTableNames := map[string]bool{"index": true}
+		names.tables[s.name] = make(map[string]string, len(s.schema.Tables))
+		for _, table := range s.schema.Tables {
+			names.tables[s.name][table.Name] = uniqueDocFileBase(table.Name, usedTableNames)
+		}
+	}
+	return names
+}
+
+// uniqueDocFileBase returns docFileBase(name), adding a numeric suffix if
+// needed to avoid any name already in used. The returned name is then added to
+// used. Since docFileBase removes dashes, a suffix beginning with a dash cannot
+// conflict with any other unsuffixed name.
+func uniqueDocFileBase(name string, used map[string]bool) string {
+	base := docFileBase(name)
+	result := base
+	for n := 2; used[strings.ToLower(result)]; n++ {
+		result = fmt.Sprintf("%s-%d", base, n)
+	}
+	used[strings.ToLower(result)] = true
+	return result
+}
+
+// schema returns the dir name for documentation of the supplied schema name.
+func (names docFileNames) schema(schemaName string) string {
+	if base, ok := names.schemas[schemaName]; ok {
+		return base
+	}
+	return docFileBase(schemaName)
+}
+
+// table returns the file name, without extension, for documentation of the
+// supplied table name in the supplied schema name.
+func (names docFileNames) table(schemaName, tableName string) string {
+	if base, ok := names.tables[schemaName][tableName]; ok {
+		return base
+	}
+	return docFileBase(tableName)
+}
+
+// writeDocFile writes the contents of w to filePath, creating its parent
+// directory if needed.
+func writeDocFile(filePath string, w docWriter) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+		return WrapExitCode(CodeCantCreate, err)
+	}
+	if err := os.WriteFile(filePath, []byte(w.String()), 0666); err != nil {
+		return WrapExitCode(CodeCantCreate, err)
+	}
+	return nil
+}
+
+// writeDocIndex writes a page listing all documented schemas.
+func writeDocIndex(w docWriter, schemas []namedSchema, names docFileNames) {
+	w.begin("Schemas")
+	w.heading(1, "Schemas")
+	rows := make([][]docCell, 0, len(schemas))
+	for _, s := range schemas {
+		rows = append(rows, []docCell{
+			docLink(s.name, names.schema(s.name)+"/index"+w.ext()),
+			docText(fmt.Sprintf("%d", len(s.schema.Tables))),
+			docText(fmt.Sprintf("%d", len(s.schema.Routines))),
+		})
+	}
+	w.table([]string{"Schema", "Tables", "Routines"}, rows)
+}
+
+// writeDocSchema writes a page listing the tables and routines in s.
+func writeDocSchema(w docWriter, s namedSchema, names docFileNames) {
+	w.begin("Schema " + s.name)
+	w.heading(1, "Schema "+s.name)
+
+	w.heading(2, "Tables")
+	if len(s.schema.Tables) == 0 {
+		w.paragraph("This schema has no tables.")
+	} else {
+		rows := make([][]docCell, 0, len(s.schema.Tables))
+		for _, table := range s.schema.Tables {
+			rows = append(rows, []docCell{
+				docLink(table.Name, names.table(s.name, table.Name)+w.ext()),
+				docText(table.Engine),
+				docText(table.Comment),
+			})
+		}
+		w.table([]string{"Table", "Engine", "Comment"}, rows)
+	}
+
+	if len(s.schema.Routines) > 0 {
+		w.heading(2, "Routines")
+		rows := make([][]docCell, 0, len(s.schema.Routines))
+		for _, r := range s.schema.Routines {
+			rows = append(rows, []docCell{
+				docCode(r.Name),
+				docText(string(r.Type)),
+				docCode(strings.Join(strings.Fields(r.ParamString), " ")),
+				docCode(r.ReturnDataType),
+				docText(r.Comment),
+			})
+		}
+		w.table([]string{"Routine", "Type", "Parameters", "Returns", "Comment"}, rows)
+	}
+}
+
+// writeDocTable writes a page describing table, which is in the schema named
+// schemaName.
+func writeDocTable(w docWriter, schemaName string, table *tengo.Table, names docFileNames) {
+	w.begin("Table " + schemaName + "." + table.Name)
+	w.heading(1, "Table "+table.Name)
+	w.paragraph(table.Comment)
+	w.paragraph(fmt.Sprintf("Schema: %s. Engine: %s. Default character set: %s. Default collation: %s.", schemaName, table.Engine, table.CharSet, table.Collation))
+
+	w.heading(2, "Columns")
+	rows := make([][]docCell, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		var extras []string
+		if col.AutoIncrement {
+			extras = append(extras, "auto_increment")
+		}
+		if col.OnUpdate != "" {
+			extras = append(extras, "on update "+col.OnUpdate)
+		}
+		if col.GenerationExpr != "" {
+			genType := "STORED"
+			if col.Virtual {
+				genType = "VIRTUAL"
+			}
+			extras = append(extras, genType+" generated as ("+col.GenerationExpr+")")
+		}
+		if col.Invisible {
+			extras = append(extras, "invisible")
+		}
+		nullable := "NO"
+		if col.Nullable {
+			nullable = "YES"
+		}
+		rows = append(rows, []docCell{
+			docCode(col.Name),
+			docCode(col.Type.String()),
+			docText(nullable),
+			docCode(col.Default),
+			docText(strings.Join(extras, ", ")),
+			docText(col.Comment),
+		})
+	}
+	w.table([]string{"Column", "Type", "Nullable", "Default", "Extra", "Comment"}, rows)
+
+	indexes := table.SecondaryIndexes
+	if table.PrimaryKey != nil {
+		indexes = append([]*tengo.Index{table.PrimaryKey}, indexes...)
+	}
+	if len(indexes) > 0 {
+		w.heading(2, "Indexes")
+		rows := make([][]docCell, 0, len(indexes))
+		for _, idx := range indexes {
+			kind := "INDEX"
+			if idx.PrimaryKey {
+				kind = "PRIMARY KEY"
+			} else if idx.Unique {
+				kind = "UNIQUE"
+			} else if idx.Type == "FULLTEXT" || idx.Type == "SPATIAL" {
+				kind = idx.Type
+			}
+			if idx.Invisible {
+				kind += " (invisible)"
+			}
+			parts := make([]string, 0, len(idx.Parts))
+			for _, part := range idx.Parts {
+				partText := part.ColumnName
+				if part.Expression != "" {
+					partText = "(" + part.Expression + ")"
+				} else if part.PrefixLength > 0 {
+					partText += fmt.Sprintf("(%d)", part.PrefixLength)
+				}
+				if part.Descending {
+					partText += " DESC"
+				}
+				parts = append(parts, partText)
+			}
+			rows = append(rows, []docCell{
+				docCode(idx.Name),
+				docCode(strings.Join(parts, ", ")),
+				docText(kind),
+				docText(idx.Comment),
+			})
+		}
+		w.table([]string{"Index", "Columns", "Kind", "Comment"}, rows)
+	}
+
+	if len(table.ForeignKeys) > 0 {
+		w.heading(2, "Foreign keys")
+		rows := make([][]docCell, 0, len(table.ForeignKeys))
+		for _, fk := range table.ForeignKeys {
+			refText := fk.ReferencedTableName + " (" + strings.Join(fk.ReferencedColumnNames, ", ") + ")"
+			refSchemaName := schemaName
+			if fk.ReferencedSchemaName != "" {
+				refSchemaName = fk.ReferencedSchemaName
+			}
+			refLink := names.table(refSchemaName, fk.ReferencedTableName) + w.ext()
+			if refSchemaName != schemaName {
+				refText = refSchemaName + "." + refText
+				refLink = "../" + names.schema(refSchemaName) + "/" + refLink
+			}
+			rows = append(rows, []docCell{
+				docCode(fk.Name),
+				docCode(strings.Join(fk.ColumnNames, ", ")),
+				docLink(refText, refLink),
+				docText(fk.UpdateRule),
+				docText(fk.DeleteRule),
+			})
+		}
+		w.table([]string{"Foreign key", "Columns", "References", "On update", "On delete"}, rows)
+	}
+
+	if len(table.Checks) > 0 {
+		w.heading(2, "Check constraints")
+		rows := make([][]docCell, 0, len(table.Checks))
+		for _, cc := range table.Checks {
+			enforced := "YES"
+			if !cc.Enforced {
+				enforced = "NO"
+			}
+			rows = append(rows, []docCell{
+				docCode(cc.Name),
+				docCode(cc.Clause),
+				docText(enforced),
+			})
+		}
+		w.table([]string{"Check", "Clause", "Enforced"}, rows)
+	}
+
+	if tp := table.Partitioning; tp != nil {
+		w.heading(2, "Partitioning")
+		desc := "Partitioned by " + tp.Method + " (" + tp.Expression + ")"
+		if tp.SubMethod != "" {
+			desc += ", subpartitioned by " + tp.SubMethod + " (" + tp.SubExpression + ")"
+		}
+		w.paragraph(desc + ".")
+		rows := make([][]docCell, 0, len(tp.Partitions))
+		for _, p := range tp.Partitions {
+			rows = append(rows, []docCell{
+				docCode(p.Name),
+				docCode(p.Values),
+				docText(p.Comment),
+			})
+		}
+		w.table([]string{"Partition", "Values", "Comment"}, rows)
+	}
+}
+
+// docCell is a single cell of a table in a documentation page.
+type docCell struct {
+	text string
+	code bool   // if true, text is rendered in a monospace font
+	link string // if non-empty, text links to this relative URL
+}
+
+func docText(text string) docCell       { return docCell{text: text} }
+func docCode(text string) docCell       { return docCell{text: text, code: true} }
+func docLink(text, link string) docCell { return docCell{text: text, link: link} }
+
+// docWriter is implemented by each documentation output format.
+type docWriter interface {
+	ext() string                              // file extension, including the leading dot
+	begin(title string)                       // start a new page
+	heading(level int, text string)           // add a heading, with level 1 being the page title
+	paragraph(text string)                    // add a paragraph; no-op if text is empty
+	table(headers []string, rows [][]docCell) // add a table
+	String() string                           // return the full page
+}
+
+// markdownDocWriter renders documentation as GitHub-flavored Markdown.
+type markdownDocWriter struct {
+	b strings.Builder
+}
+
+func newMarkdownDocWriter() docWriter { return &markdownDocWriter{} }
+
+func (mw *markdownDocWriter) ext() string        { return ".md" }
+func (mw *markdownDocWriter) begin(title string) {}
+func (mw *markdownDocWriter) String() string     { return mw.b.String() }
+
+func (mw *markdownDocWriter) heading(level int, text string) {
+	if mw.b.Len() > 0 {
+		mw.b.WriteString("\n")
+	}
+	fmt.Fprintf(&mw.b, "%s %s\n", strings.Repeat("#", level), markdownEscape(text))
+}
+
+func (mw *markdownDocWriter) paragraph(text string) {
+	if text != "" {
+		fmt.Fprintf(&mw.b, "\n%s\n", markdownEscape(text))
+	}
+}
+
+func (mw *markdownDocWriter) table(headers []string, rows [][]docCell) {
+	mw.b.WriteString("\n| " + strings.Join(headers, " | ") + " |\n")
+	mw.b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+	for _, row := range rows {
+		mw.b.WriteString("|")
+		for _, cell := range row {
+			mw.b.WriteString(" " + markdownCell(cell) + " |")
+		}
+		mw.b.WriteString("\n")
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", "&lt;", ">", "&gt;", "|", `\|`, "#", `\#`,
+	"\r\n", "<br>", "\n", "<br>",
+)
+
+// markdownEscape escapes text for use in a Markdown paragraph, heading, or
+// table cell.
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// markdownCell returns the Markdown representation of a table cell.
+func markdownCell(cell docCell) string {
+	if cell.text == "" {
+		return ""
+	} else if cell.link != "" {
+		return "[" + markdownEscape(cell.text) + "](" + strings.ReplaceAll(cell.link, " ", "%20") + ")"
+	} else if !cell.code {
+		return markdownEscape(cell.text)
+	}
+	// Code spans can't contain newlines in a table, and pipes must be escaped even
+	// inside of a code span. If the text contains backticks, a longer delimiter
+	// is needed.
+	text := strings.ReplaceAll(strings.Join(strings.Fields(cell.text), " "), "|", `\|`)
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
+
+// htmlDocWriter renders documentation as a static HTML page.
+type htmlDocWriter struct {
+	b strings.Builder
+}
+
+func newHTMLDocWriter() docWriter { return &htmlDocWriter{} }
+
+func (hw *htmlDocWriter) ext() string { return ".html" }
+
+func (hw *htmlDocWriter) begin(title string) {
+	hw.b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&hw.b, "<title>%s</title>\n", html.EscapeString(title))
+	hw.b.WriteString("<style>\n" +
+		"body { font-family: sans-serif; margin: 2em; }\n" +
+		"table { border-collapse: collapse; margin-bottom: 1em; }\n" +
+		"th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; vertical-align: top; }\n" +
+		"th { background: #eee; }\n" +
+		"</style>\n</head>\n<body>\n")
+}
+
+func (hw *htmlDocWriter) String() string {
+	return hw.b.String() + "</body>\n</html>\n"
+}
+
+func (hw *htmlDocWriter) heading(level int, text string) {
+	fmt.Fprintf(&hw.b, "<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+}
+
+func (hw *htmlDocWriter) paragraph(text string) {
+	if text != "" {
+		fmt.Fprintf(&hw.b, "<p>%s</p>\n", htmlEscape(text))
+	}
+}
+
+func (hw *htmlDocWriter) table(headers []string, rows [][]docCell) {
+	hw.b.WriteString("<table>\n<thead><tr>")
+	for _, header := range headers {
+		fmt.Fprintf(&hw.b, "<th>%s</th>", html.EscapeString(header))
+	}
+	hw.b.WriteString("</tr></thead>\n<tbody>\n")
+	for _, row := range rows {
+		hw.b.WriteString("<tr>")
+		for _, cell := range row {
+			hw.b.WriteString("<td>" + htmlCell(cell) + "</td>")
+		}
+		hw.b.WriteString("</tr>\n")
+	}
+	hw.b.WriteString("</tbody>\n</table>\n")
+}
+
+// htmlEscape escapes text for use in HTML, preserving line breaks.
+func htmlEscape(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// htmlCell returns the HTML representation of a table cell.
+func htmlCell(cell docCell) string {
+	if cell.text == "" {
+		return ""
+	} else if cell.link != "" {
+		return `<a href="` + html.EscapeString(cell.link) + `">` + htmlEscape(cell.text) + "</a>"
+	} else if cell.code {
+		return "<code>" + htmlEscape(cell.text) + "</code>"
+	}
+	return htmlEscape(cell.text)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestDocFileNames(t *testing.T) {
+	schemas := []namedSchema{
+		{name: "product", schema: &tengo.Schema{Tables: []*tengo.Table{
+			{Name: "index"},
+			{Name: "posts"},
+			{Name: "posts?"},
+			{Name: "Posts"},
+			{Name: "posts-2"},
+		}}},
+		{name: "product?", schema: &tengo.Schema{Tables: []*tengo.Table{{Name: "index"}}}},
+	}
+	names := newDocFileNames(schemas)
+	expectTables := map[string]string{
+		"index":   "index-2",
+		"posts":   "posts",
+		"posts?":  "posts-2",
+		"Posts":   "Posts-3",
+		"posts-2": "posts2",
+	}
+	for tableName, expected := range expectTables {
+		if actual := names.table("product", tableName); actual != expected {
+			t.Errorf("Expected file name for table %q to be %q, instead found %q", tableName, expected, actual)
+		}
+	}
+	if actual := names.schema("product"); actual != "product" {
+		t.Errorf("Expected dir name for schema product to be %q, instead found %q", "product", actual)
+	}
+	if actual := names.schema("product?"); actual != "product-2" {
+		t.Errorf("Expected dir name for schema product? to be %q, instead found %q", "product-2", actual)
+	}
+	if actual := names.table("product?", "index"); actual != "index-2" {
+		t.Errorf("Expected file name for table index in schema product? to be %q, instead found %q", "index-2", actual)
+	}
+
+	// Schemas and tables which are not documented fall back to docFileBase
+	if actual := names.table("product", "missing?"); actual != "missing" {
+		t.Errorf("Expected file name for undocumented table to be %q, instead found %q", "missing", actual)
+	}
+	if actual := names.schema("other#"); actual != "other" {
+		t.Errorf("Expected dir name for undocumented schema to be %q, instead found %q", "other", actual)
+	}
+}
+
+// docTestSchemas returns schemas for use in documentation rendering tests,
+// based on the ERD test schemas, but with additional comments and a table
+// named index.
+func docTestSchemas() []namedSchema {
+	schemas := erdTestSchemas()
+	posts := schemas[0].schema.Tables[0]
+	posts.Engine = "InnoDB"
+	posts.Comment = "Blog posts"
+	posts.Columns[2].Comment = "Post | body\nwith *markup*"
+	posts.Columns[2].Nullable = true
+	schemas[0].schema.Tables = append(schemas[0].schema.Tables, &tengo.Table{
+		Name:    "index",
+		Engine:  "InnoDB",
+		Columns: []*tengo.Column{{Name: "id", Type: tengo.ParseColumnType("int")}},
+	})
+	return schemas
+}
+
+func TestWriteDocMarkdown(t *testing.T) {
+	schemas := docTestSchemas()
+	names := newDocFileNames(schemas)
+
+	w := newMarkdownDocWriter()
+	writeDocIndex(w, schemas, names)
+	if out := w.String(); !strings.Contains(out, "| [product](product/index.md) | 3 | 0 |\n") || !strings.Contains(out, "| [shop](shop/index.md) | 1 | 0 |\n") {
+		t.Errorf("Unexpected Markdown schema index:\n%s", out)
+	}
+
+	w = newMarkdownDocWriter()
+	writeDocSchema(w, schemas[0], names)
+	out := w.String()
+	if !strings.HasPrefix(out, "# Schema product\n\n## Tables\n") || !strings.Contains(out, "| [posts](posts.md) | InnoDB | Blog posts |\n") || !strings.Contains(out, "| [index](index-2.md) | InnoDB |  |\n") {
+		t.Errorf("Unexpected Markdown schema page:\n%s", out)
+	}
+
+	w = newMarkdownDocWriter()
+	writeDocTable(w, "product", schemas[0].schema.Tables[0], names)
+	out = w.String()
+	expectContains := []string{
+		"# Table posts\n\nBlog posts\n",
+		"| Column | Type | Nullable | Default | Extra | Comment |\n| --- | --- | --- | --- | --- | --- |\n",
+		"| `body` | `text` | YES |  |  | Post \\| body<br>with \\*markup\\* |\n",
+		"| `PRIMARY` | `id` | PRIMARY KEY |  |\n",
+		"| `user \"fk\" #1` | `user_id` | [users (id)](users.md) |",
+	}
+	for _, expected := range expectContains {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected Markdown table page to contain %q, but it did not. Output:\n%s", expected, out)
+		}
+	}
+
+	// Cross-schema foreign keys link to the other schema's dir
+	w = newMarkdownDocWriter()
+	writeDocTable(w, "shop", schemas[1].schema.Tables[0], names)
+	if out := w.String(); !strings.Contains(out, "[product.users (id)](../product/users.md)") {
+		t.Errorf("Expected cross-schema link in Markdown table page, but it was not found. Output:\n%s", out)
+	}
+}
+
+func TestWriteDocHTML(t *testing.T) {
+	schemas := docTestSchemas()
+	names := newDocFileNames(schemas)
+
+	w := newHTMLDocWriter()
+	writeDocSchema(w, schemas[0], names)
+	out := w.String()
+	if !strings.HasPrefix(out, "<!DOCTYPE html>\n") || !strings.Contains(out, "<title>Schema product</title>") || !strings.HasSuffix(out, "</body>\n</html>\n") {
+		t.Errorf("Unexpected HTML page structure:\n%s", out)
+	}
+	if !strings.Contains(out, `<td><a href="index-2.html">index</a></td>`) {
+		t.Errorf("Expected link to de-duplicated table page, but it was not found. Output:\n%s", out)
+	}
+
+	w = newHTMLDocWriter()
+	writeDocTable(w, "product", schemas[0].schema.Tables[0], names)
+	out = w.String()
+	expectContains := []string{
+		"<title>Table product.posts</title>",
+		"<h1>Table posts</h1>\n<p>Blog posts</p>\n",
+		"<tr><td><code>body</code></td><td><code>text</code></td><td>YES</td><td></td><td></td><td>Post | body<br>with *markup*</td></tr>",
+		`<td><code>user &#34;fk&#34; #1</code></td>`,
+		`<a href="users.html">users (id)</a>`,
+	}
+	for _, expected := range expectContains {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected HTML table page to contain %q, but it did not. Output:\n%s", expected, out)
+		}
+	}
+}
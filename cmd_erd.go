@@ -0,0 +1,380 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Generate an entity-relationship diagram from the filesystem"
+	desc := "Generates an entity-relationship diagram of the tables and foreign keys " +
+		"in the filesystem representation of database objects, for all schema dirs " +
+		"in the current directory tree. The diagram is written to STDOUT, either in " +
+		"Graphviz DOT format (the default) or as a Mermaid erDiagram.\n\n" +
+		"This command relies on accessing a database server to test the SQL DDL in a " +
+		"temporary location. See the --workspace option for more information.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for workspace selection. If no " +
+		"environment name is supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("erd", summary, desc, ERDHandler)
+	cmd.AddOption(mybase.StringOption("format", 0, "dot", `Output format (valid values: "dot", "mermaid")`))
+	cmd.AddOption(mybase.StringOption("only-tables", 0, "", "Only include tables with names matching this regex"))
+	cmd.AddOption(mybase.BoolOption("key-columns-only", 0, false, "Only show primary key columns and columns in foreign keys"))
+	cmd.AddOption(mybase.BoolOption("cluster", 0, false, "Group tables into a separate cluster for each schema (DOT format only)"))
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// erdOptions controls which tables and columns are included in a diagram.
+type erdOptions struct {
+	onlyTables     *regexp.Regexp
+	keyColumnsOnly bool
+	cluster        bool
+}
+
+// namedSchema is a schema built in a workspace from a logical schema, along
+// with the schema's real name. (The name of the underlying tengo.Schema is
+// typically that of a workspace, rather than a real schema name.)
+type namedSchema struct {
+	name   string
+	schema *tengo.Schema
+}
+
+// ERDHandler is the handler method for `skeema erd`
+func ERDHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	format, err := dir.Config.GetEnum("format", "dot", "mermaid")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	opts := erdOptions{
+		keyColumnsOnly: dir.Config.GetBool("key-columns-only"),
+		cluster:        dir.Config.GetBool("cluster"),
+	}
+	if opts.onlyTables, err = dir.Config.GetRegexp("only-tables"); err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	} else if opts.cluster && format != "dot" {
+		return NewExitValue(CodeBadConfig, "Option --cluster is only supported with --format=dot")
+	}
+
+	var schemas []namedSchema
+	if err := namedSchemaWalker(dir, &schemas, 5); err != nil {
+		return err
+	}
+	if format == "mermaid" {
+		os.Stdout.WriteString(renderMermaidERD(schemas, opts))
+	} else {
+		os.Stdout.WriteString(renderDOTERD(schemas, opts))
+	}
+	return nil
+}
+
+// namedSchemaWalker executes each logical schema of dir and its subdirs in a
+// workspace, and appends the resulting schemas to schemas.
+func namedSchemaWalker(dir *fs.Dir, schemas *[]namedSchema, maxDepth int) error {
+	if dir.ParseError != nil {
+		log.Errorf("Skipping %s: %s", dir.Path, dir.ParseError)
+		return NewExitValue(CodeBadConfig, "")
+	}
+	if len(dir.LogicalSchemas) > 0 {
+		wsOpts, err := workspace.OptionsForDirFirstInstance(dir)
+		if err != nil {
+			return WrapExitCode(CodeBadConfig, err)
+		}
+		for _, logicalSchema := range dir.LogicalSchemas {
+			wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
+			if err != nil {
+				return err
+			}
+			for _, stmtErr := range wsSchema.Failures {
+				log.Warnf("Omitting %s due to error: %s", stmtErr.ObjectKey(), stmtErr.Err)
+			}
+			name := logicalSchema.Name
+			if name == "" {
+				name = dir.BaseName()
+				if schemaNames := dir.Config.GetSlice("schema", ',', true); len(schemaNames) == 1 && !strings.ContainsAny(schemaNames[0], "*`$") {
+					name = schemaNames[0]
+				}
+			}
+			*schemas = append(*schemas, namedSchema{name: name, schema: wsSchema.Schema})
+		}
+	}
+
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		return NewExitValue(CodeFatalError, "")
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Warnf("Not walking subdirs of %s: max depth reached", dir)
+		return nil
+	}
+	for _, sub := range subdirs {
+		if err := namedSchemaWalker(sub, schemas, maxDepth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// erdTable is a table to include in a diagram, along with the columns to
+// display.
+type erdTable struct {
+	schemaName string
+	table      *tengo.Table
+	columns    []*tengo.Column
+}
+
+// erdKey returns a key uniquely identifying a table across all schemas in a
+// diagram.
+func erdKey(schemaName, tableName string) string {
+	return schemaName + "." + tableName
+}
+
+// erdTables returns the tables to include in a diagram, in the same order as
+// schemas, along with a map of the same tables keyed by erdKey.
+func erdTables(schemas []namedSchema, opts erdOptions) ([]*erdTable, map[string]*erdTable) {
+	var tables []*erdTable
+	byKey := make(map[string]*erdTable)
+	for _, s := range schemas {
+		for _, table := range s.schema.Tables {
+			if opts.onlyTables == nil || opts.onlyTables.MatchString(table.Name) {
+				et := &erdTable{schemaName: s.name, table: table}
+				tables = append(tables, et)
+				byKey[erdKey(s.name, table.Name)] = et
+			}
+		}
+	}
+
+	// Determine key columns of each table: primary key columns, foreign key
+	// columns, and columns referenced by other tables' foreign keys
+	keyColumns := make(map[string]map[string]bool, len(tables))
+	addKeyColumns := func(key string, columnNames []string) {
+		if keyColumns[key] == nil {
+			keyColumns[key] = make(map[string]bool)
+		}
+		for _, colName := range columnNames {
+			keyColumns[key][colName] = true
+		}
+	}
+	for _, et := range tables {
+		key := erdKey(et.schemaName, et.table.Name)
+		addKeyColumns(key, indexColumnNames(et.table.PrimaryKey))
+		for _, fk := range et.table.ForeignKeys {
+			addKeyColumns(key, fk.ColumnNames)
+			addKeyColumns(erdKey(et.referencedSchema(fk), fk.ReferencedTableName), fk.ReferencedColumnNames)
+		}
+	}
+	for _, et := range tables {
+		key := erdKey(et.schemaName, et.table.Name)
+		for _, col := range et.table.Columns {
+			if !opts.keyColumnsOnly || keyColumns[key][col.Name] {
+				et.columns = append(et.columns, col)
+			}
+		}
+	}
+	return tables, byKey
+}
+
+// referencedSchema returns the name of the schema referenced by fk.
+func (et *erdTable) referencedSchema(fk *tengo.ForeignKey) string {
+	if fk.ReferencedSchemaName != "" {
+		return fk.ReferencedSchemaName
+	}
+	return et.schemaName
+}
+
+// columnKeys returns abbreviations of the types of keys that column is part
+// of: "PK" for primary key, "FK" for foreign key, "UK" for unique index.
+func (et *erdTable) columnKeys(col *tengo.Column) (keys []string) {
+	if slices.Contains(indexColumnNames(et.table.PrimaryKey), col.Name) {
+		keys = append(keys, "PK")
+	}
+	for _, fk := range et.table.ForeignKeys {
+		if slices.Contains(fk.ColumnNames, col.Name) {
+			keys = append(keys, "FK")
+			break
+		}
+	}
+	for _, idx := range et.table.SecondaryIndexes {
+		if idx.Unique && slices.Contains(indexColumnNames(idx), col.Name) {
+			keys = append(keys, "UK")
+			break
+		}
+	}
+	return keys
+}
+
+// fkNullable returns true if any column in fk is nullable.
+func (et *erdTable) fkNullable(fk *tengo.ForeignKey) bool {
+	cols := et.table.ColumnsByName()
+	for _, colName := range fk.ColumnNames {
+		if col := cols[colName]; col != nil && col.Nullable {
+			return true
+		}
+	}
+	return false
+}
+
+// renderDOTERD returns a Graphviz DOT digraph of the tables and foreign keys in
+// schemas. Each table is rendered as an HTML-like table label, with one row
+// per column; each foreign key is rendered as an edge between columns.
+func renderDOTERD(schemas []namedSchema, opts erdOptions) string {
+	tables, byKey := erdTables(schemas, opts)
+	multiSchema := len(schemas) > 1
+
+	var b strings.Builder
+	b.WriteString("digraph erd {\n")
+	b.WriteString("  graph [rankdir=LR];\n")
+	b.WriteString("  node [shape=plaintext];\n")
+	var currentCluster string
+	for n, et := range tables {
+		indent := "  "
+		if opts.cluster {
+			if n == 0 || et.schemaName != currentCluster {
+				if n > 0 {
+					b.WriteString("  }\n")
+				}
+				currentCluster = et.schemaName
+				fmt.Fprintf(&b, "  subgraph %s {\n", dotQuote("cluster_"+et.schemaName))
+				fmt.Fprintf(&b, "    label=%s;\n", dotQuote(et.schemaName))
+			}
+			indent = "    "
+		}
+		title := et.table.Name
+		if multiSchema && !opts.cluster {
+			title = erdKey(et.schemaName, et.table.Name)
+		}
+		fmt.Fprintf(&b, "%s%s [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", indent, dotQuote(erdKey(et.schemaName, et.table.Name)))
+		fmt.Fprintf(&b, "<tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>", html.EscapeString(title))
+		for _, col := range et.columns {
+			text := col.Name + " " + col.Type.String()
+			if keys := et.columnKeys(col); len(keys) > 0 {
+				text += " " + strings.Join(keys, ",")
+			}
+			fmt.Fprintf(&b, "<tr><td align=\"left\" port=\"%s\">%s</td></tr>", html.EscapeString(col.Name), html.EscapeString(text))
+		}
+		b.WriteString("</table>>];\n")
+	}
+	if opts.cluster && len(tables) > 0 {
+		b.WriteString("  }\n")
+	}
+
+	for _, et := range tables {
+		for _, fk := range et.table.ForeignKeys {
+			parentKey := erdKey(et.referencedSchema(fk), fk.ReferencedTableName)
+			if byKey[parentKey] == nil {
+				continue // referenced table not included in diagram
+			}
+			// Foreign key columns are always displayed, even with key-columns-only, so
+			// edges can always be attached to column ports
+			from := dotQuote(erdKey(et.schemaName, et.table.Name)) + ":" + dotQuote(fk.ColumnNames[0])
+			to := dotQuote(parentKey) + ":" + dotQuote(fk.ReferencedColumnNames[0])
+			style := "solid"
+			if et.fkNullable(fk) {
+				style = "dashed"
+			}
+			fmt.Fprintf(&b, "  %s -> %s [label=%s, style=%s];\n", from, to, dotQuote(fk.Name), style)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote returns s as a double-quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// renderMermaidERD returns a Mermaid erDiagram of the tables and foreign keys
+// in schemas. Entity names are qualified with the schema name if schemas
+// contains more than one schema.
+func renderMermaidERD(schemas []namedSchema, opts erdOptions) string {
+	tables, byKey := erdTables(schemas, opts)
+	multiSchema := len(schemas) > 1
+	entityName := func(schemaName, tableName string) string {
+		if multiSchema {
+			return mermaidWord(schemaName + "-" + tableName)
+		}
+		return mermaidWord(tableName)
+	}
+
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, et := range tables {
+		fmt.Fprintf(&b, "    %s {\n", entityName(et.schemaName, et.table.Name))
+		for _, col := range et.columns {
+			fmt.Fprintf(&b, "        %s %s", mermaidWord(col.Type.String()), mermaidWord(col.Name))
+			if keys := et.columnKeys(col); len(keys) > 0 {
+				b.WriteString(" " + strings.Join(keys, ", "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("    }\n")
+	}
+	for _, et := range tables {
+		for _, fk := range et.table.ForeignKeys {
+			parentSchema := et.referencedSchema(fk)
+			if byKey[erdKey(parentSchema, fk.ReferencedTableName)] == nil {
+				continue // referenced table not included in diagram
+			}
+			parentCardinality := "||"
+			if et.fkNullable(fk) {
+				parentCardinality = "|o"
+			}
+			fmt.Fprintf(&b, "    %s %s--o{ %s : %s\n",
+				entityName(parentSchema, fk.ReferencedTableName),
+				parentCardinality,
+				entityName(et.schemaName, et.table.Name),
+				mermaidQuote(fk.Name),
+			)
+		}
+	}
+	return b.String()
+}
+
+var mermaidInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]+`)
+
+// mermaidWord returns s with any characters which are not permitted in Mermaid
+// entity names, attribute types, or attribute names replaced with underscores.
+func mermaidWord(s string) string {
+	return mermaidInvalidChars.ReplaceAllString(s, "_")
+}
+
+var mermaidLabelReplacer = strings.NewReplacer("#", "#35;", `"`, "#quot;", "\r", " ", "\n", " ")
+
+// mermaidQuote returns s as a double-quoted Mermaid relationship label. Mermaid
+// does not support backslash escapes in quoted strings, so any double quotes or
+// hash characters in s are replaced with Mermaid entity codes, and any line
+// breaks are replaced with spaces.
+func mermaidQuote(s string) string {
+	return `"` + mermaidLabelReplacer.Replace(s) + `"`
+}
+
+// indexColumnNames returns the names of the columns in idx, omitting any
+// functional parts. The result is empty if idx is nil.
+func indexColumnNames(idx *tengo.Index) (names []string) {
+	if idx == nil {
+		return nil
+	}
+	for _, part := range idx.Parts {
+		if part.ColumnName != "" {
+			names = append(names, part.ColumnName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// erdTestSchemas returns two schemas for use in ERD rendering tests. In the
+// first schema, posts has a foreign key to users with an unusual name; in the
+// second schema, orders has a nullable foreign key to a table in the first.
+func erdTestSchemas() []namedSchema {
+	users := &tengo.Table{
+		Name: "users",
+		Columns: []*tengo.Column{
+			{Name: "id", Type: tengo.ParseColumnType("int unsigned")},
+			{Name: "name", Type: tengo.ParseColumnType("varchar(30)")},
+		},
+		PrimaryKey: &tengo.Index{Name: "PRIMARY", Parts: []tengo.IndexPart{{ColumnName: "id"}}, PrimaryKey: true, Unique: true},
+	}
+	posts := &tengo.Table{
+		Name: "posts",
+		Columns: []*tengo.Column{
+			{Name: "id", Type: tengo.ParseColumnType("int unsigned")},
+			{Name: "user_id", Type: tengo.ParseColumnType("int unsigned")},
+			{Name: "body", Type: tengo.ParseColumnType("text")},
+		},
+		PrimaryKey: &tengo.Index{Name: "PRIMARY", Parts: []tengo.IndexPart{{ColumnName: "id"}}, PrimaryKey: true, Unique: true},
+		ForeignKeys: []*tengo.ForeignKey{
+			{Name: `user "fk" #1`, ColumnNames: []string{"user_id"}, ReferencedTableName: "users", ReferencedColumnNames: []string{"id"}},
+		},
+	}
+	orders := &tengo.Table{
+		Name: "orders",
+		Columns: []*tengo.Column{
+			{Name: "id", Type: tengo.ParseColumnType("bigint")},
+			{Name: "user_id", Type: tengo.ParseColumnType("int unsigned"), Nullable: true},
+		},
+		PrimaryKey: &tengo.Index{Name: "PRIMARY", Parts: []tengo.IndexPart{{ColumnName: "id"}}, PrimaryKey: true, Unique: true},
+		ForeignKeys: []*tengo.ForeignKey{
+			{Name: "order_user", ColumnNames: []string{"user_id"}, ReferencedSchemaName: "product", ReferencedTableName: "users", ReferencedColumnNames: []string{"id"}},
+		},
+	}
+	return []namedSchema{
+		{name: "product", schema: &tengo.Schema{Name: "_skeema_tmp1", Tables: []*tengo.Table{posts, users}}},
+		{name: "shop", schema: &tengo.Schema{Name: "_skeema_tmp2", Tables: []*tengo.Table{orders}}},
+	}
+}
+
+func TestRenderDOTERD(t *testing.T) {
+	schemas := erdTestSchemas()
+
+	out := renderDOTERD(schemas[:1], erdOptions{})
+	expectContains := []string{
+		"digraph erd {\n",
+		`"product.users" [label=<`,
+		`<b>users</b>`,
+		`<td align="left" port="name">name varchar(30)</td>`,
+		`<td align="left" port="user_id">user_id int unsigned FK</td>`,
+		`"product.posts":"user_id" -> "product.users":"id" [label="user \"fk\" #1", style=solid];`,
+	}
+	for _, expected := range expectContains {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, but it did not. Output:\n%s", expected, out)
+		}
+	}
+
+	// With multiple schemas, table titles are qualified unless clustering;
+	// nullable foreign keys use dashed edges
+	out = renderDOTERD(schemas, erdOptions{keyColumnsOnly: true})
+	if !strings.Contains(out, "<b>shop.orders</b>") || strings.Contains(out, "port=\"body\"") {
+		t.Errorf("Unexpected output with multiple schemas and keyColumnsOnly:\n%s", out)
+	}
+	if !strings.Contains(out, `"shop.orders":"user_id" -> "product.users":"id" [label="order_user", style=dashed];`) {
+		t.Errorf("Expected cross-schema dashed edge, but it was not found. Output:\n%s", out)
+	}
+	out = renderDOTERD(schemas, erdOptions{cluster: true})
+	if !strings.Contains(out, "  subgraph \"cluster_shop\" {\n    label=\"shop\";\n") || !strings.Contains(out, "<b>orders</b>") {
+		t.Errorf("Unexpected output with cluster:\n%s", out)
+	}
+
+	// Edges are omitted if the referenced table is not in the diagram
+	out = renderDOTERD(schemas, erdOptions{onlyTables: regexp.MustCompile("^(posts|orders)$")})
+	if strings.Contains(out, "->") || strings.Contains(out, "users") {
+		t.Errorf("Unexpected output with onlyTables:\n%s", out)
+	}
+}
+
+func TestRenderMermaidERD(t *testing.T) {
+	schemas := erdTestSchemas()
+
+	out := renderMermaidERD(schemas[:1], erdOptions{})
+	expected := "erDiagram\n" +
+		"    posts {\n" +
+		"        int_unsigned id PK\n" +
+		"        int_unsigned user_id FK\n" +
+		"        text body\n" +
+		"    }\n" +
+		"    users {\n" +
+		"        int_unsigned id PK\n" +
+		"        varchar(30) name\n" +
+		"    }\n" +
+		"    users ||--o{ posts : \"user #quot;fk#quot; #35;1\"\n"
+	if out != expected {
+		t.Errorf("Unexpected output from renderMermaidERD.\nExpected:\n%s\nFound:\n%s", expected, out)
+	}
+
+	// With multiple schemas, entity names are qualified; nullable foreign keys
+	// have optional parent cardinality
+	out = renderMermaidERD(schemas, erdOptions{keyColumnsOnly: true})
+	if !strings.Contains(out, "    product-users |o--o{ shop-orders : \"order_user\"\n") || strings.Contains(out, " body") {
+		t.Errorf("Unexpected output with multiple schemas and keyColumnsOnly:\n%s", out)
+	}
+}
+
+func TestMermaidQuote(t *testing.T) {
+	cases := map[string]string{
+		"fk_user":         `"fk_user"`,
+		`a "b" c`:         `"a #quot;b#quot; c"`,
+		"#1":              `"#35;1"`,
+		"line\nbreak":     `"line break"`,
+		`back\slash`:      `"back\slash"`,
+		"café":            `"café"`,
+		"semi;colon #quo": `"semi;colon #35;quo"`,
+	}
+	for input, expected := range cases {
+		if actual := mermaidQuote(input); actual != expected {
+			t.Errorf("Expected mermaidQuote(%q) to return %s, instead found %s", input, expected, actual)
+		}
+	}
+}
@@ -15,6 +15,13 @@ func init() {
 	summary := "Normalize format of filesystem representation of database objects"
 	desc := "Reformats the filesystem representation of database objects to match the canonical " +
 		"format shown in SHOW CREATE.\n\n" +
+		"If the patches-file option is configured, any ALTER TABLE, RENAME TABLE, CREATE INDEX, " +
+		"or DROP INDEX statements in that file are folded into the corresponding CREATE " +
+		"statements, and then removed from the patches file.\n\n" +
+		"With --watch, after the initial run, this command continues running and polls for " +
+		"changes to *.sql and .skeema files. Each time files change, only the affected " +
+		"directories are formatted again, re-using the same workspace container between " +
+		"iterations with workspace=docker. Press Ctrl-C to stop watching.\n\n" +
 		"This command relies on accessing a database server to test the SQL DDL in a " +
 		"temporary location. See the --workspace option for more information.\n\n" +
 		"You may optionally pass an environment name as a command-line arg. This will affect " +
@@ -30,6 +37,7 @@ func init() {
 	cmd := mybase.NewCommand("format", summary, desc, FormatHandler)
 	cmd.AddOption(mybase.BoolOption("write", 0, true, "Update files to correct format"))
 	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Remove PARTITION BY clauses from *.sql files"))
+	cmd.AddOption(mybase.BoolOption("watch", 0, false, "After formatting, watch for changes to *.sql and .skeema files and format affected dirs again"))
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -50,24 +58,53 @@ func FormatHandler(cfg *mybase.Config) error {
 	// have been logged. (Multiple errors may have been encountered along the way,
 	// and it's simpler to log them when they occur, rather than needlessly
 	// collecting them.)
-	err = formatWalker(dir, 5)
-	return NewExitValue(ExitCode(err), "")
+	err = formatWalker(dir, 5, nil)
+	if !dir.Config.GetBool("watch") {
+		return NewExitValue(ExitCode(err), "")
+	}
+
+	// In watch mode, log the initial result, and then format affected dirs again
+	// whenever files change
+	logFormatSummary(dir, err)
+	return watchTree(dir.Path, func(change watchChange) []string {
+		sub, err := fs.ParseDir(change.dirPath, cfg)
+		if err != nil {
+			log.Errorf("Skipping %s: %s", change.dirPath, err)
+			return nil
+		}
+		if change.recursive {
+			var written []string
+			logFormatSummary(sub, formatWalker(sub, 5, &written))
+			return written
+		}
+		logFormatSummary(sub, formatAndLog(sub))
+		return sub.WrittenFiles()
+	})
 }
 
-func formatWalker(dir *fs.Dir, maxDepth int) error {
-	if dir.ParseError != nil {
-		log.Warnf("Skipping %s: %s", dir.Path, dir.ParseError)
-		return NewExitValue(CodeBadConfig, "")
+// logFormatSummary logs a summary of the supplied formatting result. This is
+// used in watch mode, after each iteration.
+func logFormatSummary(dir *fs.Dir, err error) {
+	switch code := ExitCode(err); {
+	case code > CodeDifferencesFound:
+		log.Error("Unable to format some files due to errors")
+	case code == CodeDifferencesFound && dir.Config.GetBool("write"):
+		log.Info("Reformatted some files")
+	case code == CodeDifferencesFound:
+		log.Warn("Some files are not formatted properly")
+	default:
+		log.Info("All files are already formatted properly")
 	}
+}
 
-	if dir.Config.GetBool("write") {
-		log.Infof("Reformatting %s", dir)
-	} else {
-		log.Infof("Checking format of %s", dir)
+// formatWalker reformats dir and its subdirs, up to maxDepth levels deep. If
+// written is non-nil, the paths of any files written are appended to it.
+func formatWalker(dir *fs.Dir, maxDepth int, written *[]string) error {
+	result := formatAndLog(dir)
+	if written != nil {
+		*written = append(*written, dir.WrittenFiles()...)
 	}
-	result := formatDir(dir)
 	if ExitCode(result) > CodeDifferencesFound {
-		log.Errorf("Skipping %s: %s", dir, result)
 		return result // don't walk subdirs if something fatal happened here
 	}
 
@@ -80,37 +117,45 @@ func formatWalker(dir *fs.Dir, maxDepth int) error {
 		return result
 	}
 	for _, sub := range subdirs {
-		err := formatWalker(sub, maxDepth-1)
+		err := formatWalker(sub, maxDepth-1, written)
 		result = HighestExitCode(result, err)
 	}
 	return result
 }
 
+// formatAndLog reformats dir, logging any fatal error. This function does not
+// recurse into subdirs.
+func formatAndLog(dir *fs.Dir) error {
+	if dir.ParseError != nil {
+		log.Warnf("Skipping %s: %s", dir.Path, dir.ParseError)
+		return NewExitValue(CodeBadConfig, "")
+	}
+
+	if dir.Config.GetBool("write") {
+		log.Infof("Reformatting %s", dir)
+	} else {
+		log.Infof("Checking format of %s", dir)
+	}
+	result := formatDir(dir)
+	if ExitCode(result) > CodeDifferencesFound {
+		log.Errorf("Skipping %s: %s", dir, result)
+	}
+	return result
+}
+
 // formatDir reformats SQL statements in all logical schemas in dir. This
 // function does not recurse into subdirs.
 func formatDir(dir *fs.Dir) error {
 	var totalReformatCount int
 
-	// Get workspace options for dir. This involves connecting to the first defined
-	// instance, so that any auto-detect-related settings work properly. However,
-	// with workspace=docker we can ignore connection errors; we'll get reasonable
-	// defaults from workspace.OptionsForDir if inst is nil as long as flavor is set.
 	var wsOpts workspace.Options
 	if len(dir.LogicalSchemas) > 0 {
-		inst, err := dir.FirstInstance()
-		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker"); wsType != "docker" || !dir.Config.Changed("flavor") {
-			if err != nil {
-				return WrapExitCode(CodeBadConfig, err)
-			} else if inst == nil {
-				return NewExitValue(CodeBadConfig, "This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker), but one is not configured for environment %q", dir.Config.Get("environment"))
-			}
-		}
-		if wsOpts, err = workspace.OptionsForDir(dir, inst); err != nil {
+		var err error
+		if wsOpts, err = workspace.OptionsForDirFirstInstance(dir); err != nil {
 			return WrapExitCode(CodeBadConfig, err)
 		}
-
-		// TODO: support multiple logical schemas per dir
-		logicalSchema := dir.LogicalSchemas[0]
+	}
+	for _, logicalSchema := range dir.LogicalSchemas {
 		wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
 		if err != nil {
 			return err
@@ -125,6 +170,8 @@ func formatDir(dir *fs.Dir) error {
 		dumpOpts := dumper.Options{
 			IncludeAutoInc: true,
 			CountOnly:      !dir.Config.GetBool("write"),
+			LogicalSchema:  logicalSchema,
+			FoldAlters:     true,
 		}
 		if dir.Config.GetBool("strip-partitioning") {
 			dumpOpts.Partitioning = tengo.PartitioningRemove
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	summary := "Show recorded push history for database schemas"
+	desc := "Displays DDL previously executed by `skeema push`, for pushes that had the " +
+		"--history option enabled. Push history is stored in a table in the schema " +
+		"specified by --state-schema on each database server.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for processing. For example, " +
+		"running `skeema history staging` will apply config directives from the " +
+		"[staging] section of config files, as well as any sectionless directives at the " +
+		"top of the file. If no environment name is supplied, the default is \"production\".\n\n" +
+		"An exit code of 0 will be returned if history could be retrieved for all " +
+		"schemas, or 2+ if any errors occurred."
+
+	cmd := mybase.NewCommand("history", summary, desc, HistoryHandler)
+	cmd.AddOption(mybase.StringOption("limit", 'n', "20", "Maximum number of statements to display per schema"))
+	cmd.AddOption(mybase.StringOption("object", 0, "", "Only display statements affecting objects with this name"))
+	cmd.AddOption(mybase.BoolOption("statements", 0, true, "Display full text of each statement"))
+	cmd.AddOption(mybase.StringOption("state-schema", 0, "_skeema", "Schema containing the push history table on database servers"))
+	cmd.AddOption(mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple hosts or schemas, only display the first target per dir"))
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// HistoryHandler is the handler method for `skeema history`
+func HistoryHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	limit, err := dir.Config.GetInt("limit")
+	if err != nil || limit < 1 {
+		return NewExitValue(CodeBadConfig, "Option limit must be set to a positive integer")
+	}
+	return historyWalker(dir, limit, 5)
+}
+
+func historyWalker(dir *fs.Dir, limit, maxDepth int) error {
+	if dir.ParseError != nil {
+		log.Warnf("Skipping %s: %s", dir.Path, dir.ParseError)
+		return NewExitValue(CodeBadConfig, "")
+	}
+
+	var err error
+	if dir.Config.Changed("host") && dir.HasSchema() {
+		err = historyDir(dir, limit)
+	}
+
+	subdirs, subErr := dir.Subdirs()
+	if subErr != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, subErr)
+		return NewExitValue(CodeFatalError, "")
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Errorf("Not walking subdirs of %s: max depth reached", dir)
+		return HighestExitCode(err, NewExitValue(CodePartialError, ""))
+	}
+	for _, sub := range subdirs {
+		err = HighestExitCode(err, historyWalker(sub, limit, maxDepth-1))
+	}
+	return err
+}
+
+// historyDir displays push history for each instance and schema that dir maps
+// to. Any errors are logged, and an exit code is returned.
+func historyDir(dir *fs.Dir, limit int) error {
+	var instances []*tengo.Instance
+	var err error
+	if dir.Config.GetBool("first-only") {
+		var inst *tengo.Instance
+		if inst, err = dir.FirstInstance(); inst != nil {
+			instances = []*tengo.Instance{inst}
+		}
+	} else {
+		instances, err = dir.Instances()
+	}
+	if err != nil {
+		log.Errorf("Skipping %s: %s\n", dir, err)
+		return NewExitValue(CodeFatalError, "")
+	}
+
+	for _, inst := range instances {
+		schemaNames, err := dir.SchemaNames(inst)
+		if err != nil {
+			log.Errorf("Skipping %s for %s: %s\n", dir, inst, err)
+			return NewExitValue(CodeFatalError, "")
+		}
+		if len(schemaNames) > 0 && dir.Config.GetBool("first-only") {
+			schemaNames = schemaNames[0:1]
+		}
+		for _, schemaName := range schemaNames {
+			entries, err := applier.QueryHistory(inst, dir, schemaName, dir.Config.Get("object"), limit)
+			if err != nil {
+				log.Errorf("Unable to query push history of %s %s: %s\n", inst, schemaName, err)
+				return NewExitValue(CodeFatalError, "")
+			}
+			printHistory(inst, schemaName, entries, dir.Config.GetBool("statements"))
+		}
+	}
+	return nil
+}
+
+func printHistory(inst *tengo.Instance, schemaName string, entries []applier.HistoryEntry, showStatements bool) {
+	if len(entries) == 0 {
+		log.Infof("%s %s: No push history recorded\n", inst, schemaName)
+		return
+	}
+	log.Infof("%s %s: Showing %s", inst, schemaName, countAndNoun(len(entries), "most recent statement", "most recent statements"))
+	var b strings.Builder
+	for _, entry := range entries {
+		commit := entry.GitCommit
+		if len(commit) > 12 {
+			commit = commit[0:12]
+		} else if commit == "" {
+			commit = "-"
+		}
+		fmt.Fprintf(&b, "-- %s  %s %s  env=%s user=%s commit=%s  %s  %s\n",
+			entry.ExecutedAt, strings.ToUpper(entry.ObjectType), tengo.EscapeIdentifier(entry.ObjectName),
+			entry.Environment, entry.OSUser, commit, entry.Duration, entry.Outcome)
+		if entry.ErrorMessage != "" {
+			fmt.Fprintf(&b, "-- error: %s\n", strings.ReplaceAll(entry.ErrorMessage, "\n", "\n-- "))
+		}
+		if showStatements {
+			b.WriteString(entry.Statement + ";\n")
+		}
+	}
+	b.WriteString("\n")
+	os.Stdout.WriteString(b.String())
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/skeema/skeema/internal/dumper"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
 )
 
 func init() {
@@ -21,6 +22,22 @@ func init() {
 		"--schema), a subdir with a .skeema config file will be created. Each directory " +
 		"will be populated with .sql files containing CREATE statements for every " +
 		"table and routine in the schema.\n\n" +
+		"By default, each object is written to its own file named after the object. The " +
+		"layout option may be used to supply a different path template, for example " +
+		"--layout='{types}/{name}.sql' to use separate tables, procedures, and functions " +
+		"subdirs. Use --group-routines to write all routines to a single file. These " +
+		"options are saved to the host dir's .skeema file. Only the subdirs used by the " +
+		"layout are read; *.sql files in any other nested subdirs are ignored.\n\n" +
+		"With --from-migrations, the schema is instead built by replaying an existing " +
+		"directory of SQL migration files in a workspace (see --workspace), in version " +
+		"order. Files named using the conventions of golang-migrate, Flyway, or goose are " +
+		"supported, as are plain numbered *.sql files; down/undo migrations are ignored. " +
+		"This mode requires --schema, which names the schema that the migrations apply " +
+		"to. Migration files may not contain USE commands, or qualify object names with " +
+		"any other schema name. With the default --workspace=temp-schema, only CREATE, " +
+		"ALTER, and INSERT statements are permitted, and statements which rename tables " +
+		"are rejected; use --workspace=docker or --workspace=local-binary to replay " +
+		"migrations containing other statements, such as DROP TABLE or RENAME TABLE.\n\n" +
 		"When operating on all schemas on the server, this command automatically skips pre-" +
 		"installed / system schemas (information_schema, performance_schema, mysql, sys, " +
 		"test).\n\n" +
@@ -39,12 +56,12 @@ func init() {
 	cmd.AddOption(mybase.StringOption("schema", 0, "", "Only import the one specified schema; skip creation of subdirs for each schema"))
 	cmd.AddOption(mybase.BoolOption("include-auto-inc", 0, false, "Include starting auto-inc values in table files"))
 	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Omit PARTITION BY clause when writing partitioned tables to filesystem"))
+	cmd.AddOption(mybase.StringOption("from-migrations", 0, "", "Build the schema by replaying SQL migration files in this dir, instead of reading it from the database server"))
 
-	// The temp-schema option is normally added via workspace.AddCommandOptions()
-	// only in subcommands that actually interact with workspaces. init doesn't use
-	// workspaces, but it just needs this one option to prevent accidental export
-	// of the temp-schema to the filesystem.
-	cmd.AddOption(mybase.StringOption("temp-schema", 't', "_skeema_tmp", "").Hidden())
+	// Workspace options are only used with --from-migrations, but the temp-schema
+	// option is also needed to prevent accidental export of the temp-schema to the
+	// filesystem.
+	workspace.AddCommandOptions(cmd)
 
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -60,6 +77,10 @@ func InitHandler(cfg *mybase.Config) error {
 		return NewExitValue(CodeBadConfig, "Option --schema may not be set to a system database name")
 	}
 	separateSchemaSubdir := (onlySchema == "")
+	migrationsDir := cfg.Get("from-migrations")
+	if migrationsDir != "" && onlySchema == "" {
+		return NewExitValue(CodeBadConfig, "Option --schema must be supplied with --from-migrations")
+	}
 
 	environment := cfg.Get("environment")
 	if environment == "" || strings.ContainsAny(environment, "[]\n\r") {
@@ -82,16 +103,25 @@ func InitHandler(cfg *mybase.Config) error {
 	}
 
 	// Build list of schemas
-	schemaNameFilter := []string{}
-	if onlySchema != "" {
-		schemaNameFilter = []string{onlySchema}
-	}
-	schemas, err := inst.Schemas(schemaNameFilter...)
-	if err != nil {
-		return NewExitValue(CodeFatalError, "Cannot examine schemas on %s: %s", inst, err)
-	}
-	if onlySchema != "" && len(schemas) == 0 {
-		return NewExitValue(CodeBadConfig, "Schema %s does not exist on database server %s", onlySchema, inst)
+	var schemas []*tengo.Schema
+	if migrationsDir != "" {
+		s, err := schemaFromMigrations(migrationsDir, hostDir, inst)
+		if err != nil {
+			return err
+		}
+		schemas = []*tengo.Schema{s}
+	} else {
+		schemaNameFilter := []string{}
+		if onlySchema != "" {
+			schemaNameFilter = []string{onlySchema}
+		}
+		schemas, err = inst.Schemas(schemaNameFilter...)
+		if err != nil {
+			return NewExitValue(CodeFatalError, "Cannot examine schemas on %s: %s", inst, err)
+		}
+		if onlySchema != "" && len(schemas) == 0 {
+			return NewExitValue(CodeBadConfig, "Schema %s does not exist on database server %s", onlySchema, inst)
+		}
 	}
 
 	// Write host option file
@@ -111,6 +141,42 @@ func InitHandler(cfg *mybase.Config) error {
 	return nil
 }
 
+// schemaFromMigrations replays the migration files in migrationsDir in a
+// workspace, and returns the resulting schema, named after the schema option.
+func schemaFromMigrations(migrationsDir string, dir *fs.Dir, inst *tengo.Instance) (*tengo.Schema, error) {
+	migrationFiles, err := fs.MigrationFiles(migrationsDir)
+	if err != nil {
+		return nil, WrapExitCode(CodeBadConfig, err)
+	}
+	schemaName := dir.Config.Get("schema")
+	var statements []*tengo.Statement
+	for _, mf := range migrationFiles {
+		fileStatements, err := mf.Statements()
+		if err != nil {
+			return nil, WrapExitCode(CodeBadConfig, err)
+		}
+		for _, stmt := range fileStatements {
+			if stmt.ObjectQualifier != "" && stmt.ObjectQualifier != schemaName {
+				return nil, NewExitValue(CodeBadConfig, "%s: migration files may not refer to schema %s, since --schema=%s", stmt.Location(), tengo.EscapeIdentifier(stmt.ObjectQualifier), schemaName)
+			}
+		}
+		statements = append(statements, fileStatements...)
+	}
+	wsOpts, err := workspace.OptionsForDir(dir, inst)
+	if err != nil {
+		return nil, WrapExitCode(CodeBadConfig, err)
+	}
+	log.Infof("Replaying %d migration files from %s", len(migrationFiles), migrationsDir)
+	s, err := workspace.ExecStatements(statements, wsOpts)
+	if _, ok := err.(fs.ConfigError); ok {
+		return nil, err
+	} else if err != nil {
+		return nil, NewExitValue(CodeFatalError, "Unable to replay migrations: %s", err)
+	}
+	s.Name = schemaName
+	return s, nil
+}
+
 func isSystemSchema(name string) bool {
 	systemSchemas := map[string]bool{
 		"mysql":              true,
@@ -171,6 +237,7 @@ func createHostOptionFile(cfg *mybase.Config, hostDir *fs.Dir, inst *tengo.Insta
 			hostOptionFile.SetOptionValue(environment, optionName, cfg.Get(optionName))
 		}
 	}
+	persistLayoutOptions(cfg, hostOptionFile)
 
 	// If a schema name was supplied, a "flat" dir is created that represents both
 	// the host and the schema. The schema name is placed outside of any named
@@ -222,6 +289,31 @@ func persistOptionAlongsideHost(optionName string) bool {
 	return false
 }
 
+// persistLayoutOptions copies the layout and group-routines options to the
+// default section of optionFile, if they were supplied on the command-line.
+// These options affect the filesystem rather than any specific database
+// server, so they are not placed in an environment section. The return value
+// indicates whether any option was copied.
+func persistLayoutOptions(cfg *mybase.Config, optionFile *mybase.File) (changed bool) {
+	if cfg.OnCLI("layout") {
+		optionFile.SetOptionValue("", "layout", cfg.Get("layout"))
+		changed = true
+	}
+	if cfg.OnCLI("group-routines") {
+		// Only one of group-routines or skip-group-routines is written, replacing
+		// any previous value in the file
+		if cfg.GetBool("group-routines") {
+			optionFile.UnsetOptionValue("", "skip-group-routines")
+			optionFile.SetOptionValue("", "group-routines", "")
+		} else {
+			optionFile.UnsetOptionValue("", "group-routines")
+			optionFile.SetOptionValue("", "skip-group-routines", "")
+		}
+		changed = true
+	}
+	return changed
+}
+
 // PopulateSchemaDir writes out *.sql files for all tables in the specified
 // schema. If makeSubdir==true, a subdir with name matching the schema name
 // will be created, and a .skeema option file will be created. Otherwise, the
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -21,8 +22,37 @@ func init() {
 		"generate an error, a warning, or be ignored entirely. Statements that contain " +
 		"invalid SQL, or otherwise return an error from the database, are always flagged " +
 		"as linter errors.\n\n" +
+		"To suppress specific rules for a single object, place a comment such as " +
+		"\"-- skeema:lint-ignore has-float,nullable\" directly before its CREATE statement. " +
+		"The same comment at the end of a line within a CREATE statement, or on a line by " +
+		"itself, suppresses the rules only for that line or the following line respectively. " +
+		"Omitting the rule names suppresses all rules. Comments which do not suppress any " +
+		"problems are flagged with a warning.\n\n" +
+		"Problems in an existing schema may be recorded using --write-baseline=FILE. " +
+		"Subsequently using --baseline=FILE suppresses these known problems, so that only new " +
+		"problems are reported. Problems are identified by directory, rule, object, and summary, " +
+		"rather than by line number.\n\n" +
+		"Company-specific rules may be implemented as an external program, configured via " +
+		"--lint-plugin. The program receives the schema as JSON on STDIN, and must write a JSON " +
+		"array of annotations to STDOUT, each with keys \"type\", \"name\", \"lineOffset\", " +
+		"\"severity\", \"rule\", \"summary\", and \"message\".\n\n" +
+		"With --fix, problems found by rules that have deterministic fixes (such as " +
+		"display-width, charset, engine, and zero-date) are fixed automatically by rewriting " +
+		"the affected CREATE TABLE statements. Each fixed statement is re-executed in the " +
+		"workspace to confirm it is valid; fixes which cause an error are reverted.\n\n" +
+		"With --changed-since=REF, only objects whose CREATE statements differ from their " +
+		"versions in the specified git ref (such as a branch name or commit) are linted, " +
+		"which is useful in CI for large schemas with many existing problems. All objects " +
+		"are still executed in the workspace, so that rules may examine related objects.\n\n" +
+		"With --watch, after the initial run, this command continues running and polls for " +
+		"changes to *.sql and .skeema files. Each time files change, only the affected " +
+		"directories are linted again, re-using the same workspace container between " +
+		"iterations with workspace=docker. Press Ctrl-C to stop watching.\n\n" +
 		"By default, this command also reformats CREATE statements to their canonical form, " +
-		"just like `skeema format`.\n\n" +
+		"just like `skeema format`. However, if the patches-file option is configured, " +
+		"statements in the patches file are never modified or removed by this command, and " +
+		"the CREATE statements of objects affected by them are not reformatted; use " +
+		"`skeema format` to fold them into the corresponding CREATE statements.\n\n" +
 		"This command relies on accessing a database server to test the SQL DDL in a " +
 		"temporary location. See the --workspace option for more information.\n\n" +
 		"You may optionally pass an environment name as a command-line arg. This will affect " +
@@ -37,10 +67,17 @@ func init() {
 
 	cmd := mybase.NewCommand("lint", summary, desc, LintHandler)
 	linter.AddCommandOptions(cmd)
+	cmd.AddOption(mybase.BoolOption("fix", 0, false, "Automatically fix problems found by rules that support it"))
+	cmd.AddOption(mybase.BoolOption("watch", 0, false, "After linting, watch for changes to *.sql and .skeema files and lint affected dirs again"))
+	cmd.AddOption(mybase.StringOption("changed-since", 0, "", "Only lint objects whose CREATE statements changed since the specified git ref"))
 	cmd.AddOptions("Format",
 		mybase.BoolOption("format", 0, true, "Reformat SQL statements to match canonical SHOW CREATE"),
 		mybase.BoolOption("strip-partitioning", 0, false, "Remove PARTITION BY clauses from *.sql files"),
 	)
+	cmd.AddOptions("Baseline",
+		mybase.StringOption("baseline", 0, "", "Suppress problems recorded in the specified baseline file"),
+		mybase.StringOption("write-baseline", 0, "", "Record all problems found to the specified baseline file"),
+	)
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -59,7 +96,67 @@ func LintHandler(cfg *mybase.Config) error {
 		log.Debug("Upgrade notice: the --format option, which currently defaults to true in Skeema v1, will change to default to false in Skeema v2. For more information, visit https://www.skeema.io/v2-changes")
 	}
 
-	result := lintWalker(dir, 5)
+	if dir.Config.GetBool("watch") && dir.Config.Get("write-baseline") != "" {
+		return NewExitValue(CodeBadConfig, "The write-baseline option cannot be combined with the watch option")
+	}
+
+	var baseline *linter.Baseline
+	if baselineFile := dir.Config.Get("baseline"); baselineFile != "" {
+		if baseline, err = linter.ReadBaseline(dir.Path, baselineFile); err != nil {
+			return NewExitValue(CodeBadConfig, "Unable to read baseline file: %s", err)
+		}
+	} else if dir.Config.Get("write-baseline") != "" {
+		baseline = linter.NewBaseline(dir.Path)
+	}
+
+	result := lintWalker(dir, 5, baseline)
+	if baseline != nil {
+		for _, entry := range baseline.Fixed() {
+			log.Infof("Baseline problem no longer found in %s: %s for %s (%s), %s",
+				entry.Dir, entry.Rule, entry.Object, entry.Summary, countAndNoun(entry.Count, "fewer occurrence", "fewer occurrences"),
+			)
+		}
+		if baselineFile := dir.Config.Get("write-baseline"); baselineFile != "" && len(result.Exceptions) == 0 {
+			if err := baseline.Write(baselineFile); err != nil {
+				return NewExitValue(CodeCantCreate, "Unable to write baseline file: %s", err)
+			}
+			log.Infof("Wrote baseline file %s", baselineFile)
+		}
+	}
+	if !dir.Config.GetBool("watch") {
+		return lintExitValue(result)
+	}
+
+	// In watch mode, log the initial result, and then lint affected dirs again
+	// whenever files change
+	logLintSummary(result)
+	return watchTree(dir.Path, func(change watchChange) []string {
+		sub, err := fs.ParseDir(change.dirPath, cfg)
+		if err != nil {
+			log.Errorf("Skipping directory %s due to error: %s", change.dirPath, err)
+			return nil
+		}
+		var baseline *linter.Baseline
+		if baselineFile := dir.Config.Get("baseline"); baselineFile != "" {
+			if baseline, err = linter.ReadBaseline(dir.Path, baselineFile); err != nil {
+				log.Errorf("Unable to read baseline file: %s", err)
+				return nil
+			}
+		}
+		var result *linter.Result
+		if change.recursive {
+			result = lintWalker(sub, 5, baseline)
+		} else {
+			result = lintAndLog(sub, baseline)
+		}
+		logLintSummary(result)
+		return result.WrittenFiles
+	})
+}
+
+// lintExitValue returns an error with the appropriate exit code and message
+// for the supplied result, or nil if no problems were found.
+func lintExitValue(result *linter.Result) error {
 	switch {
 	case len(result.Exceptions) > 0:
 		exitCode := ExitCode(HighestExitCode(result.Exceptions...))
@@ -85,22 +182,23 @@ func LintHandler(cfg *mybase.Config) error {
 	return nil
 }
 
-func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
-	if dir.ParseError != nil {
-		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.ShortName, dir.ParseError))
-		return linter.BadConfigResult(dir, dir.ParseError)
-	}
-	log.Infof("Linting %s", dir)
-	result := lintDir(dir)
-	for _, err := range result.Exceptions {
-		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.ShortName, err))
-	}
-	for _, annotation := range result.Annotations {
-		annotation.Log()
-	}
-	for _, dl := range result.DebugLogs {
-		log.Debug(dl)
+// logLintSummary logs a summary of the supplied result. This is used in watch
+// mode, after each iteration.
+func logLintSummary(result *linter.Result) {
+	err := lintExitValue(result)
+	if ExitCode(err) > CodePartialError {
+		log.Error(err)
+	} else if err != nil && err.Error() != "" {
+		log.Warn(err)
+	} else if result.ReformatCount > 0 {
+		log.Infof("Reformatted %s, and found no problems", countAndNoun(result.ReformatCount, "file", "files"))
+	} else {
+		log.Info("Found no problems")
 	}
+}
+
+func lintWalker(dir *fs.Dir, maxDepth int, baseline *linter.Baseline) *linter.Result {
+	result := lintAndLog(dir, baseline)
 
 	// Don't recurse into subdirs if there was something fatally wrong
 	if len(result.Exceptions) > 0 {
@@ -114,7 +212,7 @@ func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
 		subdirErr = fmt.Errorf("Not walking subdirs of %s: max depth reached", dir)
 	} else {
 		for _, sub := range subdirs {
-			result.Merge(lintWalker(sub, maxDepth-1))
+			result.Merge(lintWalker(sub, maxDepth-1, baseline))
 		}
 	}
 	if subdirErr != nil {
@@ -124,6 +222,33 @@ func lintWalker(dir *fs.Dir, maxDepth int) *linter.Result {
 	return result
 }
 
+// lintAndLog lints dir, applies the baseline if non-nil, and logs the
+// resulting annotations and exceptions. If dir could not be parsed, an error
+// is logged and returned in the result instead. This function does not recurse into
+// subdirs.
+func lintAndLog(dir *fs.Dir, baseline *linter.Baseline) *linter.Result {
+	if dir.ParseError != nil {
+		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.ShortName, dir.ParseError))
+		return linter.BadConfigResult(dir, dir.ParseError)
+	}
+	log.Infof("Linting %s", dir)
+	result := lintDir(dir)
+	result.WrittenFiles = append(result.WrittenFiles, dir.WrittenFiles()...)
+	if baseline != nil {
+		baseline.Apply(dir, result)
+	}
+	for _, err := range result.Exceptions {
+		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.ShortName, err))
+	}
+	for _, annotation := range result.Annotations {
+		annotation.Log()
+	}
+	for _, dl := range result.DebugLogs {
+		log.Debug(dl)
+	}
+	return result
+}
+
 // lintDir lints all logical schemas in dir, optionally also reformatting
 // SQL statements along the way. A combined result for the directory is
 // returned. This function does not recurse into subdirs.
@@ -138,28 +263,35 @@ func lintDir(dir *fs.Dir) *linter.Result {
 		return linter.BadConfigResult(dir, err)
 	}
 	opts.StripAnnotationNewlines = !util.StderrIsTerminal()
+	var changedKeys map[string][]tengo.ObjectKey
+	if ref := dir.Config.Get("changed-since"); ref != "" && len(dir.LogicalSchemas) > 0 {
+		if changedKeys, err = dir.ChangedObjectKeys(ref); err != nil {
+			return linter.BadConfigResult(dir, err)
+		}
+		var changedCount int
+		for _, keys := range changedKeys {
+			changedCount += len(keys)
+		}
+		log.Debugf("Linting %s changed since %s in %s", countAndNoun(changedCount, "object", "objects"), ref, dir)
+	}
 
-	// Get workspace options for dir. This involves connecting to the first defined
-	// instance, so that any auto-detect-related settings work properly. However,
-	// with workspace=docker we can ignore connection errors; we'll get reasonable
-	// defaults from workspace.OptionsForDir if inst is nil as long as flavor is set.
 	var wsOpts workspace.Options
 	if len(dir.LogicalSchemas) > 0 {
-		inst, err := dir.FirstInstance()
-		if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker"); wsType != "docker" || !dir.Config.Changed("flavor") {
-			if err != nil {
-				return linter.BadConfigResult(dir, err)
-			} else if inst == nil {
-				return linter.BadConfigResult(dir, fmt.Errorf("This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker), but one is not configured for environment %q", dir.Config.Get("environment")))
-			}
-		}
-		if wsOpts, err = workspace.OptionsForDir(dir, inst); err != nil {
+		if wsOpts, err = workspace.OptionsForDirFirstInstance(dir); err != nil {
 			return linter.BadConfigResult(dir, err)
 		}
 	}
 
 	result := &linter.Result{}
-	for n, logicalSchema := range dir.LogicalSchemas {
+	for _, logicalSchema := range dir.LogicalSchemas {
+		// With changed-since, each logical schema only lints its own changed objects
+		schemaOpts := opts
+		if changedKeys != nil {
+			optsCopy := *opts
+			optsCopy.OnlyKeys(changedKeys[logicalSchema.Name])
+			schemaOpts = &optsCopy
+		}
+
 		// Convert the logical schema from the filesystem into a real schema, using a
 		// workspace
 		wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
@@ -173,33 +305,56 @@ func lintDir(dir *fs.Dir) *linter.Result {
 			continue
 		}
 		log.Debugf("Workspace performance for %s using %s:\n%s", dir.ShortName, wsSchema.Info, wsSchema.Timers)
-		result.AnnotateStatementErrors(wsSchema.Failures, opts)
+		result.AnnotateStatementErrors(wsSchema.Failures, schemaOpts)
+
+		// Apply automatic fixes if requested. Like reformatting below, this must be
+		// done prior to checking for problems, since it modifies the schema.
+		if dir.Config.GetBool("fix") {
+			var fixCount int
+			wsSchema, fixCount = fixLogicalSchema(dir, wsSchema, wsOpts, schemaOpts)
+			if fixCount > 0 && !dir.Config.GetBool("format") {
+				// Without reformatting, the fixed files must be written here instead
+				for _, file := range dir.DirtyFiles() {
+					if _, err := file.Write(); err != nil {
+						log.Errorf("Unable to write %s: %s", file.FilePath, err)
+					} else {
+						log.Infof("Wrote %s", file.FilePath)
+						result.ReformatCount++
+					}
+				}
+			}
+		}
 
 		// Reformat statements if requested. This must be done prior to checking for
 		// problems. Otherwise, the line offsets in annotations can be wrong.
-		// TODO: support format for multiple logical schemas per dir
-		if dir.Config.GetBool("format") && n == 0 {
+		if dir.Config.GetBool("format") {
 			dumpOpts := dumper.Options{
 				IncludeAutoInc: true,
+				LogicalSchema:  logicalSchema,
 			}
 			if dir.Config.GetBool("strip-partitioning") {
 				dumpOpts.Partitioning = tengo.PartitioningRemove
 			}
 			dumpOpts.IgnoreKeys(wsSchema.FailedKeys())
-			result.ReformatCount, err = dumper.DumpSchema(wsSchema.Schema, dir, dumpOpts)
+			dumpOpts.IgnoreKeys(patchedObjectKeys(wsSchema))
+			reformatCount, err := dumper.DumpSchema(wsSchema.Schema, dir, dumpOpts)
 			if err != nil {
 				log.Errorf("Skipping format operation for %s: %s", dir, err)
 			}
+			result.ReformatCount += reformatCount
 		}
 
 		// Check for problems
-		subresult := linter.CheckSchema(wsSchema, opts)
+		subresult := linter.CheckSchema(wsSchema, schemaOpts)
 		result.Merge(subresult)
 	}
 
 	// Add warnings for any unsupported combinations of schema names, for example
 	// USE commands or dbname prefixes in CREATEs in a dir that also configures
 	// schema name in .skeema
+	for _, keys := range changedKeys {
+		opts.OnlyKeys(keys)
+	}
 	result.AnnotateMixedSchemaNames(dir, opts)
 
 	// Add warning annotations for unparseable statements (unless we hit an
@@ -219,6 +374,117 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	return result
 }
 
+// patchedObjectKeys returns the keys of objects in wsSchema which are affected
+// by statements in the patches file. Since lint does not remove statements from
+// the patches file, reformatting these objects' CREATE statements would cause
+// the patches to be applied twice. In addition to the objects named by each
+// patch, this includes any objects which only exist in the filesystem or only
+// exist in the workspace, such as the original and new names of renamed tables.
+func patchedObjectKeys(wsSchema *workspace.Schema) (keys []tengo.ObjectKey) {
+	logicalSchema := wsSchema.LogicalSchema
+	if len(logicalSchema.Alters) == 0 {
+		return nil
+	}
+	for _, stmt := range logicalSchema.Alters {
+		keys = append(keys, stmt.ObjectKey())
+	}
+	objects := wsSchema.Objects()
+	for key := range objects {
+		if _, ok := logicalSchema.Creates[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	for key := range logicalSchema.Creates {
+		if _, ok := objects[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// fixLogicalSchema applies automatic fixes to problems in wsSchema, rewriting
+// the affected CREATE statements in dir's files in-memory. The logical schema
+// is then re-executed in a workspace, to confirm the fixed statements are
+// valid; any fix which results in an error is reverted. The returned workspace
+// schema reflects the successful fixes, and should be used for subsequent
+// formatting and linting. The number of fixed statements is also returned.
+func fixLogicalSchema(dir *fs.Dir, wsSchema *workspace.Schema, wsOpts workspace.Options, opts *linter.Options) (*workspace.Schema, int) {
+	fixes := linter.FixSchema(wsSchema, opts)
+	if len(fixes) == 0 {
+		return wsSchema, 0
+	}
+	origTexts := make(map[*tengo.Statement]string, len(fixes))
+	origIgnores := make(map[*tengo.Statement][]tengo.LintIgnore, len(fixes))
+	for _, fix := range fixes {
+		origTexts[fix.Statement] = fix.Statement.Text
+		origIgnores[fix.Statement] = fix.Statement.LintIgnores
+		dumper.EditCreateStatement(dir, fix.Statement, fix.Text(wsSchema.Flavor))
+	}
+	revert := func(fix linter.Fix) {
+		fix.Statement.Text = origTexts[fix.Statement]
+		fix.Statement.LintIgnores = origIgnores[fix.Statement]
+	}
+
+	// Confirm the fixed statements are valid, reverting any that aren't
+	fixedSchema, err := workspace.ExecLogicalSchema(wsSchema.LogicalSchema, wsOpts)
+	if err != nil {
+		log.Errorf("Skipping fix operation for %s: %s", dir, err)
+		for _, fix := range fixes {
+			revert(fix)
+		}
+		markFixedFilesClean(dir, fixes, nil)
+		return wsSchema, 0
+	}
+	failures := make(map[*tengo.Statement]error)
+	for _, stmtErr := range fixedSchema.Failures {
+		failures[stmtErr.Statement] = stmtErr.Err
+	}
+	var applied, reverted []linter.Fix
+	for _, fix := range fixes {
+		if err := failures[fix.Statement]; err != nil {
+			log.Warnf("Unable to fix %s at %s, since the fixed statement returned an error: %s", fix.Table.ObjectKey(), fix.Statement.Location(), err)
+			revert(fix)
+			reverted = append(reverted, fix)
+		} else {
+			applied = append(applied, fix)
+		}
+	}
+	markFixedFilesClean(dir, reverted, applied)
+	if len(applied) == 0 {
+		return wsSchema, 0
+	} else if len(applied) < len(fixes) {
+		// Re-execute again, so that the workspace schema reflects the reverts
+		if fixedSchema, err = workspace.ExecLogicalSchema(wsSchema.LogicalSchema, wsOpts); err != nil {
+			log.Errorf("Skipping fix operation for %s: %s", dir, err)
+			for _, fix := range applied {
+				revert(fix)
+			}
+			markFixedFilesClean(dir, applied, nil)
+			return wsSchema, 0
+		}
+	}
+	for _, fix := range applied {
+		log.Infof("Fixed %s at %s: %s", fix.Table.ObjectKey(), fix.Statement.Location(), strings.Join(fix.RuleNames, ", "))
+	}
+	log.Infof("Applied automatic fixes to %s in %s", countAndNoun(len(applied), "statement", "statements"), dir)
+	return fixedSchema, len(applied)
+}
+
+// markFixedFilesClean unmarks files as dirty if they were only modified by
+// fixes that have since been reverted. Files containing any applied fixes are
+// left as-is.
+func markFixedFilesClean(dir *fs.Dir, reverted, applied []linter.Fix) {
+	stillDirty := make(map[*fs.SQLFile]bool)
+	for _, fix := range applied {
+		stillDirty[dir.FileFor(fix.Statement)] = true
+	}
+	for _, fix := range reverted {
+		if file := dir.FileFor(fix.Statement); !stillDirty[file] {
+			file.Dirty = false
+		}
+	}
+}
+
 func countAndNoun(n int, singular, plural string) string {
 	if n == 1 {
 		return fmt.Sprintf("1 %s", singular)
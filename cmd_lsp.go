@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/lsp"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Run a language server for *.sql files, for use by code editors"
+	desc := "Runs a Language Server Protocol server over STDIN and STDOUT, providing feedback " +
+		"on *.sql files while they are edited in a code editor such as VS Code or Neovim. " +
+		"Configure your editor to run `skeema lsp` as the language server for SQL files in " +
+		"your schema repo.\n\n" +
+		"Whenever a *.sql file is opened or saved, its directory is executed in a workspace " +
+		"and linted, just like `skeema lint`. Linter problems and SQL errors are reported as " +
+		"diagnostics. The server also supports formatting documents to the canonical format " +
+		"shown in SHOW CREATE, hover information for columns, indexes, foreign keys, and " +
+		"tables, and go-to-definition for tables and columns referenced by foreign keys. " +
+		"Since these features rely on the most recent workspace execution, CREATE statements " +
+		"which have been modified but not yet saved are not reformatted.\n\n" +
+		"This command relies on accessing a database server to test the SQL DDL in a " +
+		"temporary location. See the --workspace option for more information. With " +
+		"workspace=docker, the default of --docker-cleanup=none keeps the container running " +
+		"between edits, which avoids repeated container startup delays.\n\n" +
+		"You may optionally pass an environment name as a command-line arg. This will affect " +
+		"which section of .skeema config files is used for linter configuration and " +
+		"workspace selection. If no environment name is supplied, the default is " +
+		"\"production\".\n\n" +
+		"An exit code of 0 will be returned if the client requested a shutdown prior to " +
+		"exiting, or 2+ if any error occurred."
+
+	cmd := mybase.NewCommand("lsp", summary, desc, LSPHandler)
+	linter.AddCommandOptions(cmd)
+	workspace.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// LSPHandler is the handler method for `skeema lsp`
+func LSPHandler(cfg *mybase.Config) error {
+	if err := lsp.NewServer(cfg, os.Stdin, os.Stdout).Run(); err != nil {
+		return NewExitValue(CodeFatalError, "%s", err)
+	}
+	return nil
+}
@@ -18,7 +18,14 @@ func init() {
 	desc := "Updates the existing filesystem representation of the schemas on a DB " +
 		"server. Use this command when changes have been applied to the database " +
 		"manually or outside of Skeema, in order to make the filesystem representation " +
-		"reflect those changes.\n\n" +
+		"reflect those changes. For tables with managed data (INSERT statements in " +
+		"*.data.sql files), the INSERTs are rewritten to reflect the table's current rows.\n\n" +
+		"New objects are written to files based on the layout and group-routines options. " +
+		"If either option is supplied on the command-line, existing statements are also " +
+		"moved to match, and the new setting is saved to each directory's .skeema file.\n\n" +
+		"If the patches-file option is configured, statements in the patches file are never " +
+		"modified or removed by this command; use `skeema format` to fold them into the " +
+		"corresponding CREATE statements.\n\n" +
 		"You may optionally pass an environment name as a command-line arg. This will affect " +
 		"which section of .skeema config files is used for processing. For example, " +
 		"running `skeema pull staging` will apply config directives from the " +
@@ -147,15 +154,15 @@ func pullSchemaDir(dir *fs.Dir, instance *tengo.Instance) (schemaNames []string,
 		log.Warnf("Skipping %s: %s", dir, dir.ParseError)
 		return nil, NewExitValue(CodePartialError, "")
 	}
-	if len(dir.LogicalSchemas) > 0 {
-		// TODO: support multiple logical schemas per dir
-		logicalSchema := dir.LogicalSchemas[0]
-		schemaNames, err = pullLogicalSchema(dir, instance, logicalSchema)
+	for _, logicalSchema := range dir.LogicalSchemas {
+		handledNames, err := pullLogicalSchema(dir, instance, logicalSchema)
 		if err != nil {
 			log.Errorf("Skipping %s: %s\n", dir, err)
+			return schemaNames, err
 		}
+		schemaNames = append(schemaNames, handledNames...)
 	}
-	return
+	return schemaNames, nil
 }
 
 // pullLogicalSchema performs appropriate pull logic on a dir that maps to one or
@@ -180,24 +187,45 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 		return
 	}
 	instSchema, err := instance.Schema(schemaNames[0])
-	if err == sql.ErrNoRows {
+	if err == sql.ErrNoRows && logicalSchema.Name != "" && len(dir.LogicalSchemas) > 1 {
+		// If the dir's *.sql files reference multiple schemas by name, only remove
+		// the statements for the schema which no longer exists
+		log.Infof("Removing statements for schema %s from %s -- schema no longer exists", schemaNames[0], dir)
+		instSchema, err = &tengo.Schema{Name: schemaNames[0]}, nil
+	} else if err == sql.ErrNoRows {
 		log.Infof("Deleted directory %s -- schema %s no longer exists\n", dir, schemaNames[0])
 		return nil, dir.Delete()
 	} else if err != nil {
 		return nil, fmt.Errorf("Unable to fetch schema %s from %s: %s", schemaNames[0], instance, err)
+	} else {
+		log.Infof("Updating %s to reflect %s %s", dir, instance, instSchema.Name)
 	}
 	instSchema.StripMatches(dir.IgnorePatterns)
 
-	log.Infof("Updating %s to reflect %s %s", dir, instance, instSchema.Name)
-
 	// Handle changes in schema's default character set and/or collation by
-	// persisting changes to the dir's option file.
-	if err := updateCharSetCollation(dir, instSchema); err != nil {
-		return nil, err
+	// persisting changes to the dir's option file. This only applies when the
+	// schema name is configured in the option file as well.
+	if logicalSchema.Name == "" {
+		if err := updateCharSetCollation(dir, instSchema); err != nil {
+			return nil, err
+		}
 	}
 
 	dumpOpts := dumper.Options{
 		IncludeAutoInc: dir.Config.GetBool("include-auto-inc"),
+		LogicalSchema:  logicalSchema,
+	}
+
+	// If layout options were supplied on the command-line, move existing
+	// statements to match the new layout, and persist the options to the dir's
+	// option file so that future operations use the same layout
+	if dir.Config.OnCLI("layout") || dir.Config.OnCLI("group-routines") {
+		dumpOpts.Relocate = true
+		if dir.OptionFile != nil && persistLayoutOptions(dir.Config, dir.OptionFile) {
+			if err := dir.OptionFile.Write(true); err != nil {
+				return nil, fmt.Errorf("Unable to update layout options in %s: %s", dir.OptionFile.Path(), err)
+			}
+		}
 	}
 	if !dir.Config.GetBool("update-partitioning") {
 		if dir.Config.GetBool("strip-partitioning") {
@@ -227,6 +255,12 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 		dumpOpts.OnlyKeys(inDiff)
 	}
 
+	// Tables with managed data (INSERT statements in *.sql files) have their
+	// rows refreshed from the live schema
+	if dumpOpts.Data, err = managedDataForPull(logicalSchema, instance, instSchema); err != nil {
+		return nil, err
+	}
+
 	_, err = dumper.DumpSchema(instSchema, dir, dumpOpts)
 	if err == nil {
 		os.Stderr.WriteString("\n")
@@ -234,6 +268,31 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 	return
 }
 
+// managedDataForPull queries the live rows of each table which has managed data
+// in logicalSchema. Tables which no longer exist in instSchema are omitted.
+func managedDataForPull(logicalSchema *fs.LogicalSchema, instance *tengo.Instance, instSchema *tengo.Schema) (map[string]*tengo.TableData, error) {
+	var tables []*tengo.Table
+	for _, name := range logicalSchema.InsertTableNames() {
+		if table := instSchema.Table(name); table != nil {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	db, err := instance.CachedConnectionPool(instSchema.Name, "")
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]*tengo.TableData, len(tables))
+	for _, table := range tables {
+		if data[table.Name], err = tengo.QueryTableData(db, table, nil); err != nil {
+			return nil, fmt.Errorf("Unable to query managed data of %s: %w", table.ObjectKey(), err)
+		}
+	}
+	return data, nil
+}
+
 func statementModifiersForPull(config *mybase.Config, instance *tengo.Instance) tengo.StatementModifiers {
 	// We're permissive of unsafe operations here since we don't ever actually
 	// execute the generated statement! We just examine its type.
@@ -19,6 +19,10 @@ func init() {
 		"filesystem representation of them. This essentially performs the same diff logic " +
 		"as `skeema diff`, but then actually runs the generated DDL. You should generally " +
 		"run `skeema diff` first to see what changes will be applied.\n\n" +
+		"Tables with INSERT statements in *.data.sql files (for example in a companion " +
+		"tablename.data.sql file) have their rows managed as well: rows are compared by " +
+		"primary key, and reconciled using INSERT, UPDATE, or DELETE statements. Deleting " +
+		"rows requires --allow-unsafe. INSERT statements in other *.sql files are ignored.\n\n" +
 		"You may optionally pass an environment name as a command-line arg. This will affect " +
 		"which section of .skeema config files is used for processing. For example, " +
 		"running `skeema push staging` will apply config directives from the " +
@@ -55,10 +59,15 @@ func init() {
 
 	cmd.AddOptions("safety",
 		mybase.BoolOption("verify", 0, true, "Test all generated ALTER statements on temp schema to verify correctness"),
+		mybase.StringOption("verify-data", 0, "0", "Test generated ALTER TABLEs against up to this many rows randomly sampled from each altered table"),
+		mybase.StringOption("verify-data-full-below-size", 0, "0", "Test generated ALTER TABLEs against all rows of altered tables below this size in bytes"),
 		mybase.BoolOption("allow-unsafe", 0, false, "Permit running ALTER or DROP operations that are potentially destructive"),
 		mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"),
 		mybase.BoolOption("foreign-key-checks", 0, false, "Force the server to check referential integrity of any new foreign key"),
 		mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"),
+		mybase.StringOption("drop-index-grace-period", 0, "", "Make indexes invisible instead of dropping them, and only drop once invisible for this duration (e.g. \"72h\")"),
+		mybase.BoolOption("history", 0, false, "Record each executed DDL statement in a push history table on database servers"),
+		mybase.StringOption("state-schema", 0, "_skeema", "Schema for storing state tables on database servers, used by --drop-index-grace-period and --history"),
 	)
 
 	cmd.AddOptions("sharding",
@@ -68,6 +77,11 @@ func init() {
 		mybase.StringOption("concurrent-instances", 0, "1", "<deprecated alias for concurrent-servers>").Hidden().MarkDeprecated("This option has been renamed to concurrent-servers. The old concurrent-instances option name remains as an alias in Skeema v1, but will be removed in Skeema v2."),
 	)
 
+	cmd.AddOptions("migration",
+		mybase.StringOption("emit-migration", 0, "", "<overridden by diff command>").Hidden(),
+		mybase.StringOption("migration-format", 0, "golang-migrate", "<overridden by diff command>").Hidden(),
+	)
+
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -78,12 +92,15 @@ func init() {
 func PushHandler(cfg *mybase.Config) error {
 	// Set up some config overrides relating to --brief output mode:
 	// * --brief only affects `skeema diff` (aka `skeema push --dry-run`)
-	// * --brief automatically uses --skip-verify --skip-lint --allow-unsafe
+	// * --brief automatically uses --skip-verify --verify-data=0
+	//   --verify-data-full-below-size=0 --skip-lint --allow-unsafe
 	// * --brief omits INFO-level logging, unless --debug was used
 	if !cfg.GetBool("dry-run") {
 		cfg.SetRuntimeOverride("brief", "0")
 	} else if cfg.GetBool("brief") {
 		cfg.SetRuntimeOverride("verify", "0")
+		cfg.SetRuntimeOverride("verify-data", "0")
+		cfg.SetRuntimeOverride("verify-data-full-below-size", "0")
 		cfg.SetRuntimeOverride("lint", "0")
 		cfg.SetRuntimeOverride("allow-unsafe", "1")
 		if !cfg.GetBool("debug") {
@@ -116,6 +133,12 @@ func PushHandler(cfg *mybase.Config) error {
 	}
 
 	printer := applier.NewPrinter(dir.Config)
+	var emitter *applier.MigrationEmitter
+	if dir.Config.GetBool("dry-run") && dir.Config.Get("emit-migration") != "" {
+		if emitter, err = applier.NewMigrationEmitter(dir.Config); err != nil {
+			return err
+		}
+	}
 
 	g, ctx := errgroup.WithContext(context.Background())
 	g.SetLimit(concurrency)
@@ -132,7 +155,7 @@ func PushHandler(cfg *mybase.Config) error {
 				case <-ctx.Done():
 					return nil // Exit early if context cancelled
 				default:
-					result, err := applier.ApplyTarget(t, printer)
+					result, err := applier.ApplyTarget(t, printer, emitter)
 					if err != nil {
 						return err
 					}
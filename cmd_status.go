@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/applier"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func init() {
+	summary := "Report drift between the filesystem and every environment"
+	desc := "Compares the filesystem representation of each schema directory against the " +
+		"corresponding database schemas in every environment defined in .skeema files, " +
+		"and displays a matrix of directory x environment. Each cell indicates whether " +
+		"the schemas are in sync, the number of objects with differences by object type, " +
+		"or whether the database servers could not be reached.\n\n" +
+		"By default, environments are discovered from the sections of .skeema files in " +
+		"this directory tree and its parents. Use --environments to supply an explicit " +
+		"comma-separated list instead.\n\n" +
+		"This command relies on accessing database servers to test the SQL DDL in a " +
+		"temporary location. See the --workspace option for more information.\n\n" +
+		"An exit code of 0 will be returned if all schemas are in sync in all " +
+		"environments; 1 if any differences were found or any database server could " +
+		"not be reached; or 2+ if a fatal error occurred."
+
+	cmd := mybase.NewCommand("status", summary, desc, StatusHandler)
+	cmd.AddOption(mybase.StringOption("environments", 0, "", "Comma-separated list of environments to check, instead of discovering them automatically"))
+	cmd.AddOption(mybase.StringOption("format", 0, "text", `Output format (valid values: "text", "json")`))
+	cmd.AddOption(mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple hosts or schemas, only check the first target per dir"))
+	cmd.AddOption(mybase.StringOption("environment", 0, "production", "Environment to use for option file sections; overridden for each environment checked").Hidden())
+	cloneSQLGenerationOptions(cmd)
+	workspace.AddCommandOptions(cmd)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// cloneSQLGenerationOptions copies options affecting diff generation from
+// `skeema push` into cmd. Logic relies on cmd_push.go's init() having been
+// called before cmd's init().
+func cloneSQLGenerationOptions(cmd *mybase.Command) {
+	push, ok := CommandSuite.SubCommands["push"]
+	if !ok {
+		panic(fmt.Errorf("Assertion failed: push command must be registered before %s command", cmd.Name))
+	}
+	for _, opt := range push.Options() {
+		if opt.Group == "SQL generation" || opt.Name == "allow-unsafe" {
+			optCopy := *opt
+			optCopy.HiddenOnCLI = optCopy.HiddenOnCLI || opt.Name == "allow-unsafe"
+			cmd.AddOptions(opt.Group, &optCopy)
+		}
+	}
+}
+
+// statusCell summarizes the state of one directory in one environment.
+type statusCell struct {
+	Status      string         `json:"status"` // "in-sync", "drift", "unreachable", or "unconfigured"
+	Targets     int            `json:"targets"`
+	Unreachable int            `json:"unreachable,omitempty"`
+	Drift       map[string]int `json:"drift,omitempty"` // object type => count of objects with differences
+}
+
+// String returns a human-readable representation of the cell, for use in text
+// output.
+func (cell *statusCell) String() string {
+	if cell == nil || cell.Status == "unconfigured" {
+		return "-"
+	} else if cell.Status == "in-sync" {
+		return "in sync"
+	} else if cell.Status == "unreachable" && len(cell.Drift) == 0 {
+		return "unreachable"
+	}
+	types := make([]string, 0, len(cell.Drift))
+	for objType := range cell.Drift {
+		types = append(types, objType)
+	}
+	slices.Sort(types)
+	parts := make([]string, 0, len(types)+1)
+	for _, objType := range types {
+		parts = append(parts, countAndNoun(cell.Drift[objType], objType, objType+"s"))
+	}
+	if cell.Unreachable > 0 {
+		parts = append(parts, fmt.Sprintf("%d unreachable", cell.Unreachable))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// statusReport is the full result of `skeema status`.
+type statusReport struct {
+	Environments []string                          `json:"environments"`
+	Dirs         []string                          `json:"dirs"`
+	Cells        map[string]map[string]*statusCell `json:"cells"` // dir => environment => cell
+}
+
+// StatusHandler is the handler method for `skeema status`
+func StatusHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	format, err := dir.Config.GetEnum("format", "text", "json")
+	if err != nil {
+		return WrapExitCode(CodeBadConfig, err)
+	}
+	environments := dir.Config.GetSlice("environments", ',', true)
+	if len(environments) == 0 {
+		if environments, err = discoverEnvironments(dir, cfg); err != nil {
+			return err
+		}
+	}
+
+	// The dir tree is only parsed once; each environment's configuration is then
+	// resolved from the already-parsed dirs
+	dirs, err := statusWalker(dir, 5)
+	if err != nil {
+		return err
+	}
+	report := &statusReport{
+		Environments: environments,
+		Cells:        make(map[string]map[string]*statusCell),
+	}
+	for _, environment := range environments {
+		for _, d := range dirs {
+			envDir, err := d.ForEnvironment(environment, cfg)
+			if err != nil {
+				log.Errorf("Skipping %s: %s", envDir, err)
+				return NewExitValue(CodeBadConfig, "")
+			} else if !envDir.HasSchema() {
+				continue
+			}
+			cell := &statusCell{Status: "unconfigured"}
+			if envDir.Config.Changed("host") {
+				cell = statusForDir(envDir)
+			}
+			name, err := filepath.Rel(dir.Path, envDir.Path)
+			if err != nil {
+				name = envDir.Path
+			}
+			if report.Cells[name] == nil {
+				report.Cells[name] = make(map[string]*statusCell)
+				report.Dirs = append(report.Dirs, name)
+			}
+			report.Cells[name][environment] = cell
+		}
+	}
+	slices.Sort(report.Dirs)
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printStatusReport(report)
+	}
+
+	for _, byEnv := range report.Cells {
+		for _, cell := range byEnv {
+			if cell.Status == "drift" || cell.Status == "unreachable" {
+				return NewExitValue(CodeDifferencesFound, "")
+			}
+		}
+	}
+	return nil
+}
+
+// discoverEnvironments returns a sorted list of environment names, based on
+// the sections of .skeema files in dir's parents, dir, and dir's subdirs. If
+// no sections are found, the default environment name is returned.
+func discoverEnvironments(dir *fs.Dir, cfg *mybase.Config) ([]string, error) {
+	seen := make(map[string]bool)
+	addSections := func(f *mybase.File) {
+		for _, name := range f.SectionsWithOption("host") {
+			if name != "" {
+				seen[name] = true
+			}
+		}
+		for _, name := range f.SectionsWithOption("schema") {
+			if name != "" {
+				seen[name] = true
+			}
+		}
+	}
+	parentFiles, _, err := fs.ParentOptionFiles(dir.Path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range parentFiles {
+		addSections(f)
+	}
+	var walk func(d *fs.Dir, maxDepth int) error
+	walk = func(d *fs.Dir, maxDepth int) error {
+		if d.OptionFile != nil {
+			addSections(d.OptionFile)
+		}
+		subdirs, err := d.Subdirs()
+		if err != nil || maxDepth <= 0 {
+			return err
+		}
+		for _, sub := range subdirs {
+			if err := walk(sub, maxDepth-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(dir, 5); err != nil {
+		return nil, err
+	}
+	if len(seen) == 0 {
+		return []string{cfg.Get("environment")}, nil
+	}
+	environments := make([]string, 0, len(seen))
+	for name := range seen {
+		environments = append(environments, name)
+	}
+	slices.Sort(environments)
+	return environments, nil
+}
+
+// statusWalker returns dir and its subdirs, recursively. The environments
+// are resolved for each returned dir by the caller, so that the dir tree only
+// needs to be parsed once.
+func statusWalker(dir *fs.Dir, maxDepth int) ([]*fs.Dir, error) {
+	if dir.ParseError != nil {
+		log.Errorf("Skipping %s: %s", dir.Path, dir.ParseError)
+		return nil, NewExitValue(CodeBadConfig, "")
+	}
+	dirs := []*fs.Dir{dir}
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Errorf("Cannot list subdirs of %s: %s", dir, err)
+		return nil, NewExitValue(CodeFatalError, "")
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		log.Warnf("Not walking subdirs of %s: max depth reached", dir)
+		return dirs, nil
+	}
+	for _, sub := range subdirs {
+		subDirs, err := statusWalker(sub, maxDepth-1)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, subDirs...)
+	}
+	return dirs, nil
+}
+
+// statusForDir computes drift for each target of dir. Problems are logged,
+// and reflected in the returned cell as unreachable targets.
+func statusForDir(dir *fs.Dir) *statusCell {
+	cell := &statusCell{Drift: make(map[string]int)}
+	targets, skipCount := applier.TargetsForDirOnly(dir)
+	cell.Targets = len(targets) + skipCount
+	cell.Unreachable = skipCount
+	for _, t := range targets {
+		drift, err := applier.DriftForTarget(t)
+		if err != nil {
+			log.Errorf("Unable to compare %s for %s: %s", t, dir, err)
+			cell.Unreachable++
+			continue
+		}
+		for objType, count := range drift {
+			cell.Drift[string(objType)] += count
+		}
+	}
+	if len(cell.Drift) > 0 {
+		cell.Status = "drift"
+	} else if cell.Unreachable > 0 {
+		cell.Status = "unreachable"
+	} else {
+		cell.Status = "in-sync"
+	}
+	return cell
+}
+
+func printStatusReport(report *statusReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "DIRECTORY\t%s\n", strings.ToUpper(strings.Join(report.Environments, "\t")))
+	for _, name := range report.Dirs {
+		cells := make([]string, len(report.Environments))
+		for n, environment := range report.Environments {
+			cells[n] = report.Cells[name][environment].String()
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
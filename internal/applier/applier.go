@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
@@ -51,6 +52,8 @@ type Plan struct {
 	DiffKeys    []tengo.ObjectKey          // objects with non-blank supported schema differences
 	Unsupported map[tengo.ObjectKey]string // map of object key => details on why unsupported
 	Unsafe      []UnsafeStatement
+
+	history *historyRecorder // non-nil if executed statements should be recorded in push history
 }
 
 // Run prints each statement in the plan, and also executes them if the Target's
@@ -60,7 +63,14 @@ func (plan *Plan) Run(printer Printer) (skipCount int) {
 	for i, stmt := range plan.Statements {
 		printer.Print(stmt)
 		if !dryRun {
-			if err := stmt.Execute(); err != nil {
+			start := time.Now()
+			err := stmt.Execute()
+			if plan.history != nil {
+				if historyErr := plan.history.record(plan.DiffKeys[i], stmt, time.Since(start), err); historyErr != nil {
+					log.Warnf("Unable to record push history on %s: %s", plan.Target, historyErr)
+				}
+			}
+			if err != nil {
 				log.Errorf("Error running SQL statement on %s: %s\nFull SQL statement: %s%s", plan.Target, err, stmt.Statement(), stmt.ClientState().Delimiter)
 				skipCount = len(plan.Statements) - i
 				if skipCount > 1 {
@@ -126,8 +136,9 @@ func (r Result) Error() error {
 }
 
 // ApplyTarget generates the diff for the supplied target, prints the resulting
-// SQL, and executes the SQL if this isn't a dry-run.
-func ApplyTarget(t *Target, printer Printer) (Result, error) {
+// SQL, and executes the SQL if this isn't a dry-run. If emitter is non-nil and
+// this is a dry-run, the resulting SQL is also written to migration files.
+func ApplyTarget(t *Target, printer Printer, emitter *MigrationEmitter) (Result, error) {
 	var result Result
 
 	schemaFromInstance, err := t.SchemaFromInstance()
@@ -163,6 +174,17 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 	}
 
 	diff := tengo.NewSchemaDiff(schemaFromInstance, schemaFromDir)
+
+	// With --drop-index-grace-period, secondary indexes are made invisible first,
+	// and only actually dropped by a subsequent push after the grace period
+	indexDrops, err := newIndexDropTracker(t, diff)
+	if err != nil {
+		return result, err
+	} else if indexDrops != nil {
+		mods.DeferIndexDrops = true
+		mods.IndexDropReady = indexDrops.ready
+	}
+
 	plan, err := CreatePlanForTarget(t, diff, mods)
 	result.UnsupportedCount = len(plan.Unsupported)
 	result.Differences = (len(plan.DiffKeys) + len(plan.Unsupported)) > 0
@@ -218,8 +240,32 @@ func ApplyTarget(t *Target, printer Printer) (Result, error) {
 		return result, nil
 	}
 
+	// If requested, set up recording of executed statements in push history
+	if t.Dir.Config.GetBool("history") && !t.Dir.Config.GetBool("dry-run") && len(plan.Statements) > 0 {
+		if plan.history, err = newHistoryRecorder(t); err != nil {
+			result.SkipCount += len(plan.Statements)
+			if _, ok := err.(ConfigError); ok {
+				return result, err
+			}
+			log.Errorf("Skipping %s: unable to prepare push history table: %s\n", t, err)
+			return result, nil
+		}
+	}
+
 	// Apply plan (print if dry-run, or execute if not); final logging; return result
 	result.SkipCount += plan.Run(printer)
+	if emitter != nil && t.Dir.Config.GetBool("dry-run") && len(plan.Statements) > 0 {
+		reverse := tengo.NewSchemaDiff(schemaFromDir, schemaFromInstance)
+		if err := emitter.Emit(t, plan, reverse, mods); err != nil {
+			result.SkipCount += len(plan.Statements)
+			log.Errorf("Unable to write migration for %s: %s\n", t, err)
+		}
+	}
+	if indexDrops != nil && result.SkipCount == 0 && !t.Dir.Config.GetBool("dry-run") {
+		if err := indexDrops.record(); err != nil {
+			log.Warnf("%s: Unable to record state of invisible indexes pending drop: %s", t, err)
+		}
+	}
 	if !result.Differences {
 		log.Infof("%s: No differences found\n", t)
 	} else if t.Dir.Config.GetBool("dry-run") {
@@ -295,6 +341,11 @@ func CreatePlanForTarget(t *Target, diff *tengo.SchemaDiff, mods tengo.Statement
 		}
 	}
 
+	// With --verify-data, also run ALTER TABLEs against a sample of real rows
+	if fatalErr == nil && len(allAlterTables) > 0 {
+		fatalErr = VerifyDiffData(t, allAlterTables, mods)
+	}
+
 	plan := &Plan{
 		Target:      t,
 		Statements:  make([]PlannedStatement, 0, len(objDiffs)),
@@ -327,6 +378,36 @@ func CreatePlanForTarget(t *Target, diff *tengo.SchemaDiff, mods tengo.Statement
 		}
 	}
 
+	// Third pass: reconcile managed table data, after all DDL has been planned.
+	// Deletions of rows are unsafe unless explicitly permitted.
+	rowDiffs, err := dataDiffsForTarget(t, diff.FromSchema)
+	if err != nil && fatalErr == nil {
+		fatalErr = err
+	}
+	for _, rowDiff := range rowDiffs {
+		key := rowDiff.ObjectKey()
+		stmt, err := rowDiff.Statement(mods)
+		if err != nil && !tengo.IsUnsafeDiff(err) {
+			if fatalErr == nil {
+				fatalErr = err
+			}
+			continue
+		}
+		plan.Statements = append(plan.Statements, &DataStatement{
+			stmt:       stmt,
+			instance:   t.Instance,
+			schemaName: t.SchemaName,
+		})
+		plan.DiffKeys = append(plan.DiffKeys, key)
+		if tengo.IsUnsafeDiff(err) {
+			plan.Unsafe = append(plan.Unsafe, UnsafeStatement{
+				Key:       key,
+				Statement: stmt,
+				Reason:    err.Error(),
+			})
+		}
+	}
+
 	return plan, fatalErr
 }
 
@@ -73,6 +73,28 @@ func TestResultError(t *testing.T) {
 	}
 }
 
+func TestVerifyDataOptionsRowLimit(t *testing.T) {
+	cases := []struct {
+		vdo       verifyDataOptions
+		tableSize int64
+		limit     int
+		ok        bool
+	}{
+		{verifyDataOptions{}, 0, 0, false},
+		{verifyDataOptions{sampleRows: 100}, 0, 100, true},
+		{verifyDataOptions{sampleRows: 100}, 1 << 30, 100, true},
+		{verifyDataOptions{fullBelowSize: 1 << 20}, 1 << 19, 0, true},
+		{verifyDataOptions{fullBelowSize: 1 << 20}, 1 << 20, 0, false},
+		{verifyDataOptions{sampleRows: 100, fullBelowSize: 1 << 20}, 1 << 19, 0, true},
+		{verifyDataOptions{sampleRows: 100, fullBelowSize: 1 << 20}, 1 << 21, 100, true},
+	}
+	for _, c := range cases {
+		if limit, ok := c.vdo.rowLimit(c.tableSize); limit != c.limit || ok != c.ok {
+			t.Errorf("Unexpected result from %+v rowLimit(%d): expected %d,%t, found %d,%t", c.vdo, c.tableSize, c.limit, c.ok, limit, ok)
+		}
+	}
+}
+
 func TestIntegration(t *testing.T) {
 	for _, image := range tengo.SkeemaTestImages(t) {
 		var setupGroup errgroup.Group
@@ -167,6 +189,7 @@ func (s ApplierIntegrationSuite) TestCreatePlanForTarget(t *testing.T) {
 		"environment":            "production",
 		"foreign-key-checks":     "",
 		"verify":                 "true",
+		"verify-data":            "0",
 		"default-character-set":  "latin1",
 		"default-collation":      "latin1_swedish_ci",
 		"workspace":              "temp-schema",
@@ -175,6 +198,7 @@ func (s ApplierIntegrationSuite) TestCreatePlanForTarget(t *testing.T) {
 		"temp-schema-mode":       "regular",
 		"temp-schema-threads":    "",
 		"reuse-temp-schema":      "false",
+		"workspace-cache":        "false",
 	}
 	dir := &fs.Dir{
 		Path:   "/var/tmp/fakedir",
@@ -0,0 +1,79 @@
+package applier
+
+import (
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// DataStatement represents a DML statement (INSERT, UPDATE, or DELETE) which
+// reconciles the managed data rows of a table.
+type DataStatement struct {
+	stmt       string
+	instance   *tengo.Instance
+	schemaName string
+}
+
+// Execute runs the DML statement against the target's schema. Foreign key
+// checks are always disabled, since rows of parent and child tables may be
+// reconciled in either order within a single push.
+func (ds *DataStatement) Execute() error {
+	db, err := ds.instance.CachedConnectionPool(ds.schemaName, "foreign_key_checks=0")
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(ds.stmt)
+	return err
+}
+
+// Statement returns the DML statement.
+func (ds *DataStatement) Statement() string {
+	return ds.stmt
+}
+
+// ClientState returns a representation of the client state which would be
+// used in execution of the statement.
+func (ds *DataStatement) ClientState() ClientState {
+	return ClientState{
+		InstanceName: ds.instance.String(),
+		SchemaName:   ds.schemaName,
+		Delimiter:    ";",
+	}
+}
+
+// dataDiffsForTarget compares the managed data rows declared in the target's
+// dir to the rows present in the live schema, and returns the row differences.
+// Tables which do not exist yet in the live schema are treated as empty. If a
+// live table is missing some columns of the desired definition (for example,
+// when the same push adds a column), only the common columns are queried.
+func dataDiffsForTarget(t *Target, schemaFromInstance *tengo.Schema) ([]*tengo.RowDiff, error) {
+	if t.DesiredSchema == nil || len(t.DesiredSchema.Data) == 0 {
+		return nil, nil
+	}
+	db, err := t.Instance.CachedConnectionPool(t.SchemaName, "")
+	if err != nil {
+		return nil, err
+	}
+	var diffs []*tengo.RowDiff
+	for _, name := range t.DesiredSchema.LogicalSchema.InsertTableNames() {
+		desired := t.DesiredSchema.Data[name]
+		if desired == nil {
+			continue
+		}
+		live := &tengo.TableData{Table: desired.Table, Columns: desired.Columns}
+		if schemaFromInstance != nil {
+			if liveTable := schemaFromInstance.Table(name); liveTable != nil {
+				liveColumns := liveTable.ColumnsByName()
+				columns := make([]*tengo.Column, 0, len(desired.Columns))
+				for _, col := range desired.Columns {
+					if liveColumns[col.Name] != nil {
+						columns = append(columns, col)
+					}
+				}
+				if live, err = tengo.QueryTableData(db, desired.Table, columns); err != nil {
+					return nil, err
+				}
+			}
+		}
+		diffs = append(diffs, tengo.DiffTableData(live, desired)...)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,55 @@
+package applier
+
+import (
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// DriftForTarget compares the target's live schema to its desired state, and
+// returns a count of objects with differences, keyed by object type. This
+// only counts differences which would result in DDL given the target's
+// configuration (for example, next-auto-increment differences are ignored),
+// but does include objects whose differences are unsupported or unsafe.
+func DriftForTarget(t *Target) (map[tengo.ObjectType]int, error) {
+	schemaFromInstance, err := t.SchemaFromInstance()
+	if err != nil {
+		return nil, err
+	}
+	schemaFromDir := t.SchemaFromDir()
+	mods, err := StatementModifiersForDir(t.Dir)
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	}
+	mods.Flavor = t.Instance.Flavor()
+	if mods.Partitioning == tengo.PartitioningRemove {
+		schemaFromDir.StripTablePartitioning(mods.Flavor)
+	}
+
+	// Multiple diffs may affect the same object, for example when ALTER TABLEs
+	// are split up, so track which objects have already been counted
+	drift := make(map[tengo.ObjectType]int)
+	seen := make(map[tengo.ObjectKey]bool)
+	diff := tengo.NewSchemaDiff(schemaFromInstance, schemaFromDir)
+	for _, objDiff := range diff.ObjectDiffs() {
+		key := objDiff.ObjectKey()
+		if seen[key] {
+			continue
+		}
+		if stmt, err := objDiff.Statement(mods); stmt != "" || err != nil {
+			drift[key.Type]++
+			seen[key] = true
+		}
+	}
+
+	// Differences in managed table data count toward the table's drift
+	rowDiffs, err := dataDiffsForTarget(t, schemaFromInstance)
+	if err != nil {
+		return nil, err
+	}
+	for _, rowDiff := range rowDiffs {
+		if key := rowDiff.ObjectKey(); !seen[key] {
+			drift[key.Type]++
+			seen[key] = true
+		}
+	}
+	return drift, nil
+}
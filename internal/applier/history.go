@@ -0,0 +1,166 @@
+package applier
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// HistoryTableName is the name of the table used for recording push history,
+// if the history option is enabled. This table is stored in the schema named
+// by the state-schema option.
+const HistoryTableName = "_skeema_history"
+
+// HistoryEntry represents a single row of the push history table, describing
+// one executed DDL statement.
+type HistoryEntry struct {
+	ExecutedAt   string
+	SchemaName   string
+	ObjectType   string
+	ObjectName   string
+	Environment  string
+	OSUser       string
+	GitCommit    string
+	Statement    string
+	Duration     time.Duration
+	Outcome      string
+	ErrorMessage string
+}
+
+// historyRecorder inserts a row into the push history table for each DDL
+// statement executed against a Target.
+type historyRecorder struct {
+	target      *Target
+	environment string
+	osUser      string
+	gitCommit   string
+}
+
+// newHistoryRecorder returns a historyRecorder for the supplied target,
+// creating the history table (and its containing schema) if it does not exist
+// yet.
+func newHistoryRecorder(t *Target) (*historyRecorder, error) {
+	stateSchema := t.Dir.Config.Get("state-schema")
+	if stateSchema == "" {
+		return nil, ConfigError("option history requires a non-blank value for option state-schema")
+	}
+	db, err := t.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	setup := []string{
+		"CREATE DATABASE IF NOT EXISTS " + tengo.EscapeIdentifier(stateSchema),
+		"CREATE TABLE IF NOT EXISTS " + historyTable(t.Dir) + ` (
+			id bigint unsigned NOT NULL AUTO_INCREMENT,
+			executed_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			schema_name varchar(64) NOT NULL,
+			object_type varchar(20) NOT NULL,
+			object_name varchar(64) NOT NULL,
+			environment varchar(64) NOT NULL,
+			os_user varchar(255) NOT NULL,
+			git_commit varchar(40) NOT NULL,
+			statement longtext NOT NULL,
+			duration_ms int unsigned NOT NULL,
+			outcome enum('success','error') NOT NULL,
+			error_message text,
+			PRIMARY KEY (id),
+			KEY schema_object (schema_name, object_name)
+		) DEFAULT CHARSET=utf8mb4`,
+	}
+	for _, query := range setup {
+		if _, err := db.Exec(query); err != nil {
+			return nil, err
+		}
+	}
+
+	hr := &historyRecorder{
+		target:      t,
+		environment: t.Dir.Config.Get("environment"),
+		osUser:      os.Getenv("USER"),
+	}
+	if u, err := user.Current(); err == nil {
+		hr.osUser = u.Username
+	}
+	// Git commit is best-effort: the dir may not be in a git repo, or git may not
+	// be installed
+	if out, _, err := shellout.New("git rev-parse HEAD").WithWorkingDir(t.Dir.Path).RunCaptureSeparate(); err == nil {
+		hr.gitCommit = strings.TrimSpace(out)
+	}
+	return hr, nil
+}
+
+// historyTable returns the escaped, schema-qualified name of the push history
+// table, based on dir's configuration.
+func historyTable(dir *fs.Dir) string {
+	return tengo.EscapeIdentifier(dir.Config.Get("state-schema")) + "." + tengo.EscapeIdentifier(HistoryTableName)
+}
+
+// record inserts a row for a statement which has just been executed. execErr
+// should be the return value of the statement's Execute method.
+func (hr *historyRecorder) record(key tengo.ObjectKey, stmt PlannedStatement, duration time.Duration, execErr error) error {
+	db, err := hr.target.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return err
+	}
+	outcome := "success"
+	var errorMessage *string
+	if execErr != nil {
+		outcome = "error"
+		msg := execErr.Error()
+		errorMessage = &msg
+	}
+	query := "INSERT INTO " + historyTable(hr.target.Dir) + ` (
+		schema_name, object_type, object_name, environment, os_user, git_commit,
+		statement, duration_ms, outcome, error_message
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = db.Exec(query,
+		hr.target.SchemaName, string(key.Type), key.Name, hr.environment, hr.osUser, hr.gitCommit,
+		stmt.Statement(), duration.Milliseconds(), outcome, errorMessage)
+	return err
+}
+
+// QueryHistory returns the most recent push history entries for the named
+// schema on instance, in reverse chronological order. If objectName is
+// non-blank, results are restricted to objects with that name. At most limit
+// entries are returned.
+func QueryHistory(instance *tengo.Instance, dir *fs.Dir, schemaName, objectName string, limit int) ([]HistoryEntry, error) {
+	db, err := instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT   executed_at, schema_name, object_type, object_name, environment,
+		         os_user, git_commit, statement, duration_ms, outcome,
+		         COALESCE(error_message, '')
+		FROM     %s
+		WHERE    schema_name = ? AND (? = '' OR object_name = ?)
+		ORDER BY id DESC
+		LIMIT    %d`, historyTable(dir), limit)
+	rows, err := db.Query(query, schemaName, objectName, objectName)
+	if tengo.IsDatabaseError(err, tengo.ER_NO_SUCH_TABLE) {
+		return []HistoryEntry{}, nil // history never recorded on this instance
+	} else if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []HistoryEntry
+	for rows.Next() {
+		var entry HistoryEntry
+		var durationMS int64
+		err := rows.Scan(&entry.ExecutedAt, &entry.SchemaName, &entry.ObjectType, &entry.ObjectName, &entry.Environment,
+			&entry.OSUser, &entry.GitCommit, &entry.Statement, &durationMS, &entry.Outcome,
+			&entry.ErrorMessage)
+		if err != nil {
+			return nil, err
+		}
+		entry.Duration = time.Duration(durationMS) * time.Millisecond
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
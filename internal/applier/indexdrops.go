@@ -0,0 +1,154 @@
+package applier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// indexDropKey identifies a secondary index within a Target's schema.
+type indexDropKey struct {
+	tableName string
+	indexName string
+}
+
+// indexDropTracker implements two-phase index drops: rather than dropping a
+// secondary index immediately, it is first made invisible (or IGNORED in
+// MariaDB), and only actually dropped by a later push once it has remained
+// invisible for the configured grace period. The time at which each index was
+// made invisible is persisted in a state table on the target instance.
+type indexDropTracker struct {
+	target      *Target
+	stateSchema string
+	gracePeriod time.Duration
+	dropped     map[indexDropKey]*tengo.Index  // secondary indexes dropped by the diff
+	elapsed     map[indexDropKey]time.Duration // time since index was made invisible, from state table
+}
+
+// newIndexDropTracker returns an indexDropTracker for the supplied target and
+// diff, or nil if the target's configuration does not enable two-phase index
+// drops.
+func newIndexDropTracker(t *Target, diff *tengo.SchemaDiff) (*indexDropTracker, error) {
+	if t.Dir.Config.Get("drop-index-grace-period") == "" {
+		return nil, nil
+	}
+	gracePeriod, err := time.ParseDuration(t.Dir.Config.Get("drop-index-grace-period"))
+	if err != nil || gracePeriod < 0 {
+		return nil, ConfigError("option drop-index-grace-period has been configured to an invalid value; a duration such as \"72h\" is required")
+	}
+	if flavor := t.Instance.Flavor(); !flavor.MinMySQL(8) && !flavor.MinMariaDB(10, 6) {
+		// Without invisible index support, DeferIndexDrops has no effect anyway
+		return nil, nil
+	}
+	stateSchema := t.Dir.Config.Get("state-schema")
+	if stateSchema == "" {
+		return nil, ConfigError("option drop-index-grace-period requires a non-blank value for option state-schema")
+	}
+
+	idt := &indexDropTracker{
+		target:      t,
+		stateSchema: stateSchema,
+		gracePeriod: gracePeriod,
+		dropped:     make(map[indexDropKey]*tengo.Index),
+		elapsed:     make(map[indexDropKey]time.Duration),
+	}
+	for _, td := range diff.TableDiffs {
+		for _, idx := range td.DroppedIndexes() {
+			if !idx.PrimaryKey {
+				idt.dropped[indexDropKey{td.From.Name, idx.Name}] = idx
+			}
+		}
+	}
+
+	db, err := t.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT table_name, index_name, TIMESTAMPDIFF(SECOND, hidden_at, NOW())
+		FROM   %s
+		WHERE  schema_name = ?`, idt.stateTable())
+	rows, err := db.Query(query, t.SchemaName)
+	if tengo.IsDatabaseError(err, tengo.ER_NO_SUCH_TABLE) {
+		return idt, nil // state table not created yet
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to query %s: %w", idt.stateTable(), err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key indexDropKey
+		var seconds int64
+		if err := rows.Scan(&key.tableName, &key.indexName, &seconds); err != nil {
+			return nil, err
+		}
+		idt.elapsed[key] = time.Duration(seconds) * time.Second
+	}
+	return idt, rows.Err()
+}
+
+func (idt *indexDropTracker) stateTable() string {
+	return tengo.EscapeIdentifier(idt.stateSchema) + "." + tengo.EscapeIdentifier("index_drops")
+}
+
+// ready returns true if the named index has been invisible for at least the
+// grace period. Its signature satisfies tengo.IndexDropFilter.
+func (idt *indexDropTracker) ready(tableName, indexName string) bool {
+	elapsed, tracked := idt.elapsed[indexDropKey{tableName, indexName}]
+	return tracked && elapsed >= idt.gracePeriod
+}
+
+// record updates the state table to reflect a successful push: indexes which
+// were just made invisible begin their grace period, indexes which were already
+// invisible but untracked begin their grace period now, and rows for indexes
+// which were dropped (or which are no longer pending a drop) are removed.
+func (idt *indexDropTracker) record() error {
+	if len(idt.dropped) == 0 && len(idt.elapsed) == 0 {
+		return nil
+	}
+	db, err := idt.target.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return err
+	}
+	setup := []string{
+		"CREATE DATABASE IF NOT EXISTS " + tengo.EscapeIdentifier(idt.stateSchema),
+		"CREATE TABLE IF NOT EXISTS " + idt.stateTable() + ` (
+			schema_name varchar(64) NOT NULL,
+			table_name varchar(64) NOT NULL,
+			index_name varchar(64) NOT NULL,
+			hidden_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (schema_name, table_name, index_name)
+		) DEFAULT CHARSET=utf8mb4`,
+	}
+	for _, query := range setup {
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	pending := make(map[indexDropKey]bool)
+	for key, idx := range idt.dropped {
+		var verb string
+		if !idx.Invisible {
+			verb = "REPLACE" // just made invisible now, so (re)start grace period
+		} else if !idt.ready(key.tableName, key.indexName) {
+			verb = "INSERT IGNORE" // start grace period if not already tracked
+		} else {
+			continue // actually dropped by this push
+		}
+		query := verb + " INTO " + idt.stateTable() + " (schema_name, table_name, index_name) VALUES (?, ?, ?)"
+		if _, err := db.Exec(query, idt.target.SchemaName, key.tableName, key.indexName); err != nil {
+			return err
+		}
+		pending[key] = true
+	}
+	for key := range idt.elapsed {
+		if !pending[key] {
+			query := "DELETE FROM " + idt.stateTable() + " WHERE schema_name = ? AND table_name = ? AND index_name = ?"
+			if _, err := db.Exec(query, idt.target.SchemaName, key.tableName, key.indexName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package applier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// MigrationEmitter writes the statements generated for each target to
+// versioned migration files, for use with external migration tools such as
+// golang-migrate, Flyway, or goose. Each migration consists of the target's
+// planned statements ("up"), along with the statements needed to reverse them
+// ("down"). A single MigrationEmitter may be used concurrently by multiple
+// goroutines.
+type MigrationEmitter struct {
+	Dir    string // directory to write migration files to
+	Format string // one of "golang-migrate", "flyway", or "goose"
+
+	start    time.Time
+	versions map[string]string // schema name => migration version
+	written  map[string]string // file path => contents written
+	m        sync.Mutex
+}
+
+// migrationStatement is a single statement in a migration file.
+type migrationStatement struct {
+	stmt     string
+	compound bool // true if stmt is a compound statement, such as CREATE PROCEDURE
+}
+
+// NewMigrationEmitter returns a MigrationEmitter configured by the
+// emit-migration and migration-format options in cfg. An error is returned if
+// cfg also uses alter-wrapper or ddl-wrapper, since shell commands cannot be
+// written to migration files.
+func NewMigrationEmitter(cfg *mybase.Config) (*MigrationEmitter, error) {
+	if cfg.Get("alter-wrapper") != "" || cfg.Get("ddl-wrapper") != "" {
+		return nil, ConfigError("option emit-migration cannot be combined with alter-wrapper or ddl-wrapper")
+	}
+	format, err := cfg.GetEnum("migration-format", "golang-migrate", "flyway", "goose")
+	if err != nil {
+		return nil, ConfigError(err.Error())
+	}
+	return &MigrationEmitter{
+		Dir:      cfg.Get("emit-migration"),
+		Format:   format,
+		start:    time.Now().UTC(),
+		versions: make(map[string]string),
+		written:  make(map[string]string),
+	}, nil
+}
+
+// Emit writes a migration for the supplied target. The up migration consists of
+// the statements in plan. The down migration is generated from reverse, which
+// should be the diff from the target's desired schema back to its current
+// schema, using the same statement modifiers as the plan; any index drops which
+// the plan deferred are reversed by making the index visible again. Targets
+// with the same schema name share a version, so that sharded schemas result in
+// a single migration. If another target with the same schema name already
+// resulted in a different migration, an error is returned, since the
+// migration files cannot represent both.
+func (e *MigrationEmitter) Emit(t *Target, plan *Plan, reverse *tengo.SchemaDiff, mods tengo.StatementModifiers) error {
+	up := make([]migrationStatement, 0, len(plan.Statements))
+	var hasData bool
+	for _, stmt := range plan.Statements {
+		up = append(up, migrationStatement{
+			stmt:     stmt.Statement(),
+			compound: stmt.ClientState().Delimiter != ";",
+		})
+		if _, ok := stmt.(*DataStatement); ok {
+			hasData = true
+		}
+	}
+	if hasData {
+		log.Warnf("%s: down migration does not reverse changes to managed table data", t)
+	}
+
+	// The down migration inherently reverses whatever the up migration did, so
+	// destructive statements are permitted. DDLStatement isn't used here since
+	// objects being reversed may not exist on the target yet, and wrappers don't
+	// apply to migration files anyway.
+	mods.AllowUnsafe = true
+	mods.ReverseIndexDrops = true
+	var down []migrationStatement
+	for _, objDiff := range reverse.ObjectDiffs() {
+		stmt, err := objDiff.Statement(mods)
+		if tengo.IsUnsupportedDiff(err) {
+			return fmt.Errorf("unable to generate down migration for %s: %w", objDiff.ObjectKey(), err)
+		} else if err != nil && !tengo.IsUnsafeDiff(err) {
+			return err
+		} else if stmt == "" {
+			continue
+		}
+		ms := migrationStatement{stmt: stmt}
+		if compounder, ok := objDiff.(tengo.Compounder); ok {
+			ms.compound = compounder.IsCompoundStatement()
+		}
+		down = append(down, ms)
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+	version, ok := e.versions[t.SchemaName]
+	if !ok {
+		// Each schema needs a distinct version, since migration tools typically
+		// require versions to be unique within a directory
+		version = e.start.Add(time.Duration(len(e.versions)) * time.Second).Format("20060102150405")
+		e.versions[t.SchemaName] = version
+	}
+	files := migrationFiles(e.Format, version, migrationName(t.SchemaName), up, down)
+	for name, contents := range files {
+		filePath := filepath.Join(e.Dir, name)
+		if prev, already := e.written[filePath]; already && prev != contents {
+			return fmt.Errorf("%s was already written with different contents for another target with the same schema name", filePath)
+		}
+	}
+	if err := os.MkdirAll(e.Dir, 0777); err != nil {
+		return err
+	}
+	for name, contents := range files {
+		filePath := filepath.Join(e.Dir, name)
+		if _, already := e.written[filePath]; already {
+			continue
+		}
+		if err := os.WriteFile(filePath, []byte(contents), 0666); err != nil {
+			return err
+		}
+		e.written[filePath] = contents
+		log.Infof("Wrote migration file %s", filePath)
+	}
+	return nil
+}
+
+// migrationName returns a description for use in migration file names, based
+// on the supplied schema name.
+func migrationName(schemaName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, schemaName)
+	if name == "" {
+		name = "schema"
+	}
+	return name
+}
+
+// migrationFiles returns a map of file name => file contents for a migration in
+// the supplied format.
+func migrationFiles(format, version, name string, up, down []migrationStatement) map[string]string {
+	switch format {
+	case "flyway":
+		// Flyway's undo migrations use a U prefix instead of V
+		return map[string]string{
+			"V" + version + "__" + name + ".sql": migrationContents(up, flywayStatement),
+			"U" + version + "__" + name + ".sql": migrationContents(down, flywayStatement),
+		}
+	case "goose":
+		contents := "-- +goose Up\n" + migrationContents(up, gooseStatement) +
+			"\n-- +goose Down\n" + migrationContents(down, gooseStatement)
+		return map[string]string{
+			version + "_" + name + ".sql": contents,
+		}
+	default: // golang-migrate
+		return map[string]string{
+			version + "_" + name + ".up.sql":   migrationContents(up, plainStatement),
+			version + "_" + name + ".down.sql": migrationContents(down, plainStatement),
+		}
+	}
+}
+
+// migrationContents returns the contents of a migration file, or section of a
+// file, containing the supplied statements formatted by formatter.
+func migrationContents(statements []migrationStatement, formatter func(migrationStatement) string) string {
+	if len(statements) == 0 {
+		return "-- No statements\n"
+	}
+	var b strings.Builder
+	for _, ms := range statements {
+		b.WriteString(formatter(ms))
+	}
+	return b.String()
+}
+
+// plainStatement terminates every statement with a semicolon. Migration tools
+// that send the entire file to the server at once don't require any special
+// handling of compound statements.
+func plainStatement(ms migrationStatement) string {
+	return ms.stmt + ";\n"
+}
+
+// flywayStatement uses the DELIMITER command around compound statements, as
+// with the mysql client.
+func flywayStatement(ms migrationStatement) string {
+	if ms.compound {
+		return "DELIMITER //\n" + ms.stmt + "//\nDELIMITER ;\n"
+	}
+	return ms.stmt + ";\n"
+}
+
+// gooseStatement wraps compound statements in goose's StatementBegin and
+// StatementEnd annotations.
+func gooseStatement(ms migrationStatement) string {
+	if ms.compound {
+		return "-- +goose StatementBegin\n" + ms.stmt + ";\n-- +goose StatementEnd\n"
+	}
+	return ms.stmt + ";\n"
+}
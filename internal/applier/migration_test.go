@@ -0,0 +1,97 @@
+package applier
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	up := []migrationStatement{
+		{stmt: "ALTER TABLE `foo` ADD COLUMN `bar` int"},
+		{stmt: "CREATE PROCEDURE `p`() BEGIN SELECT 1; END", compound: true},
+	}
+	down := []migrationStatement{
+		{stmt: "DROP PROCEDURE IF EXISTS `p`"},
+		{stmt: "ALTER TABLE `foo` DROP COLUMN `bar`"},
+	}
+
+	files := migrationFiles("golang-migrate", "20240102030405", "product", up, down)
+	expected := map[string]string{
+		"20240102030405_product.up.sql":   "ALTER TABLE `foo` ADD COLUMN `bar` int;\nCREATE PROCEDURE `p`() BEGIN SELECT 1; END;\n",
+		"20240102030405_product.down.sql": "DROP PROCEDURE IF EXISTS `p`;\nALTER TABLE `foo` DROP COLUMN `bar`;\n",
+	}
+	if !maps.Equal(files, expected) {
+		t.Errorf("Unexpected result for golang-migrate: %v", files)
+	}
+
+	files = migrationFiles("flyway", "20240102030405", "product", up, nil)
+	expected = map[string]string{
+		"V20240102030405__product.sql": "ALTER TABLE `foo` ADD COLUMN `bar` int;\nDELIMITER //\nCREATE PROCEDURE `p`() BEGIN SELECT 1; END//\nDELIMITER ;\n",
+		"U20240102030405__product.sql": "-- No statements\n",
+	}
+	if !maps.Equal(files, expected) {
+		t.Errorf("Unexpected result for flyway: %v", files)
+	}
+
+	files = migrationFiles("goose", "20240102030405", "product", up, down)
+	expected = map[string]string{
+		"20240102030405_product.sql": "-- +goose Up\nALTER TABLE `foo` ADD COLUMN `bar` int;\n" +
+			"-- +goose StatementBegin\nCREATE PROCEDURE `p`() BEGIN SELECT 1; END;\n-- +goose StatementEnd\n" +
+			"\n-- +goose Down\nDROP PROCEDURE IF EXISTS `p`;\nALTER TABLE `foo` DROP COLUMN `bar`;\n",
+	}
+	if !maps.Equal(files, expected) {
+		t.Errorf("Unexpected result for goose: %v", files)
+	}
+}
+
+func TestMigrationName(t *testing.T) {
+	cases := map[string]string{
+		"product":    "product",
+		"my-db.v2":   "my_db_v2",
+		"":           "schema",
+		"Sharded_01": "Sharded_01",
+	}
+	for input, expected := range cases {
+		if actual := migrationName(input); actual != expected {
+			t.Errorf("Expected migrationName(%q) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestMigrationEmitterConflict(t *testing.T) {
+	e := &MigrationEmitter{
+		Dir:      t.TempDir(),
+		Format:   "golang-migrate",
+		start:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		versions: make(map[string]string),
+		written:  make(map[string]string),
+	}
+	plan := &Plan{}
+	schema := &tengo.Schema{Name: "product"}
+	noDiff := tengo.NewSchemaDiff(schema, schema)
+	if err := e.Emit(&Target{SchemaName: "product"}, plan, noDiff, tengo.StatementModifiers{}); err != nil {
+		t.Fatalf("Unexpected error from Emit: %v", err)
+	}
+
+	// Another shard with the same schema name and same migration is fine
+	if err := e.Emit(&Target{SchemaName: "product"}, plan, noDiff, tengo.StatementModifiers{}); err != nil {
+		t.Errorf("Unexpected error from Emit for identical migration: %v", err)
+	}
+
+	// Another shard with the same schema name but a different migration should
+	// return an error, rather than silently omitting it
+	otherSchema := &tengo.Schema{Name: "product", Tables: []*tengo.Table{
+		{Name: "foo", CreateStatement: "CREATE TABLE `foo` (\n  `id` int NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"},
+	}}
+	if err := e.Emit(&Target{SchemaName: "product"}, plan, tengo.NewSchemaDiff(schema, otherSchema), tengo.StatementModifiers{}); err == nil {
+		t.Error("Expected error from Emit for conflicting migration, but err was nil")
+	}
+	if contents, err := os.ReadFile(filepath.Join(e.Dir, "20240102030405_product.down.sql")); err != nil || string(contents) != "-- No statements\n" {
+		t.Errorf("Unexpected contents of down migration after conflict: %q (err=%v)", contents, err)
+	}
+}
@@ -62,6 +62,48 @@ func TargetsForDir(dir *fs.Dir, maxDepth int) (targets []*Target, skipCount int)
 		return nil, 1
 	}
 
+	var fatal bool
+	if targets, skipCount, fatal = targetsForDirOnly(dir); fatal {
+		// If something went wrong, don't recurse into subdirs, since there's likely
+		// something fatally wrong with this dir's configuration
+		return
+	}
+
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		log.Warnf("Skipping subdirs of %s: %s\n", dir, err)
+		skipCount++
+		return
+	} else if len(subdirs) > 0 && maxDepth < 1 {
+		log.Warnf("Skipping subdirs of %s: max depth reached\n", dir)
+		skipCount += len(subdirs)
+		return
+	}
+
+	for _, subdir := range subdirs {
+		subTargets, subSkipCount := TargetsForDir(subdir, maxDepth-1)
+		targets = append(targets, subTargets...)
+		skipCount += subSkipCount
+	}
+	return
+}
+
+// TargetsForDirOnly behaves like TargetsForDir, except it does not recurse
+// into subdirectories of dir.
+func TargetsForDirOnly(dir *fs.Dir) (targets []*Target, skipCount int) {
+	if dir.ParseError != nil {
+		log.Errorf("Skipping %s: %s\n", dir.Path, dir.ParseError)
+		return nil, 1
+	}
+	targets, skipCount, _ = targetsForDirOnly(dir)
+	return
+}
+
+// targetsForDirOnly returns the Targets for dir, without recursing into
+// subdirectories. The fatal return value will be true if any LogicalSchema in
+// dir could not be processed, in which case the caller should not process
+// subdirs either.
+func targetsForDirOnly(dir *fs.Dir) (targets []*Target, skipCount int, fatal bool) {
 	if dir.Config.Changed("host") && dir.HasSchema() {
 		var instances []*tengo.Instance
 		instances, skipCount = instancesForDir(dir)
@@ -74,11 +116,9 @@ func TargetsForDir(dir *fs.Dir, maxDepth int) (targets []*Target, skipCount int)
 				targets = append(targets, thisTargets...)
 				skipCount += thisSkipCount
 				if thisSkipCount > 0 {
-					// If something went wrong, stop processing any other LogicalSchemas and
-					// don't recurse into subdirs, since there's likely something fatally wrong
-					// with this dir's configuration
+					// If something went wrong, stop processing any other LogicalSchemas
 					skipCount += len(dir.LogicalSchemas) - 1 - n
-					return
+					return targets, skipCount, true
 				}
 			}
 		}
@@ -97,22 +137,6 @@ func TargetsForDir(dir *fs.Dir, maxDepth int) (targets []*Target, skipCount int)
 		log.Warnf("Skipping %s: no schema defined for environment %q\nOther environments do define a schema name. Refer to the .skeema file in this directory for details.\n", dir, dir.Config.Get("environment"))
 	}
 
-	subdirs, err := dir.Subdirs()
-	if err != nil {
-		log.Warnf("Skipping subdirs of %s: %s\n", dir, err)
-		skipCount++
-		return
-	} else if len(subdirs) > 0 && maxDepth < 1 {
-		log.Warnf("Skipping subdirs of %s: max depth reached\n", dir)
-		skipCount += len(subdirs)
-		return
-	}
-
-	for _, subdir := range subdirs {
-		subTargets, subSkipCount := TargetsForDir(subdir, maxDepth-1)
-		targets = append(targets, subTargets...)
-		skipCount += subSkipCount
-	}
 	return
 }
 
@@ -294,7 +294,7 @@ func getBaseConfig(t *testing.T, cliFlags string) *mybase.Config {
 	cmd.AddArg("environment", "production", false)
 	util.AddGlobalOptions(cmd)
 	workspace.AddCommandOptions(cmd)
-	return mybase.ParseFakeCLI(t, cmd, fmt.Sprintf("appliertest %s", cliFlags))
+	return mybase.ParseFakeCLI(t, cmd, fmt.Sprintf("appliertest --skip-workspace-cache %s", cliFlags))
 }
 
 func getDir(t *testing.T, dirPath, extraFlags string) *fs.Dir {
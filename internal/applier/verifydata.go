@@ -0,0 +1,227 @@
+package applier
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+// verifyDataChunkSize is the maximum number of rows copied into a workspace
+// table per INSERT statement.
+const verifyDataChunkSize = 500
+
+// verifyDataOptions controls how many rows are copied from each table for
+// data verification.
+type verifyDataOptions struct {
+	sampleRows    int   // from verify-data option; 0 means sampling is disabled
+	fullBelowSize int64 // from verify-data-full-below-size option; 0 means disabled
+}
+
+// rowLimit returns the maximum number of rows to copy from a table of the
+// supplied size, with 0 meaning the full table should be copied. If the table
+// should not be verified at all, ok is false.
+func (vdo verifyDataOptions) rowLimit(tableSize int64) (limit int, ok bool) {
+	if tableSize < vdo.fullBelowSize {
+		return 0, true
+	}
+	return vdo.sampleRows, vdo.sampleRows > 0
+}
+
+// VerifyDiffData runs the ALTER TABLEs in altersInDiff against real rows copied
+// from the target, to detect changes that would fail on the table's actual
+// data, such as truncation of values, duplicate values in a new unique index,
+// or violations of a new check constraint. Tables smaller than the target's
+// verify-data-full-below-size option are copied in full; otherwise, up to the
+// number of rows in the target's verify-data option are randomly sampled from
+// the table. If neither option is enabled, no verification takes place. The
+// rows are copied into a workspace table matching the table's current
+// definition, and then the generated ALTERs are run there using a strict
+// sql_mode. Problems are logged per table, and an error is returned if any
+// table failed verification.
+func VerifyDiffData(t *Target, altersInDiff []*tengo.TableDiff, mods tengo.StatementModifiers) error {
+	var vdo verifyDataOptions
+	var err error
+	if vdo.sampleRows, err = t.Dir.Config.GetInt("verify-data"); err != nil || vdo.sampleRows < 0 {
+		return ConfigError("option verify-data must be set to a non-negative integer")
+	}
+	fullBelowSize, err := t.Dir.Config.GetBytes("verify-data-full-below-size")
+	if err != nil {
+		return ConfigError("option verify-data-full-below-size has been configured to an invalid value")
+	}
+	vdo.fullBelowSize = int64(fullBelowSize)
+	if vdo.sampleRows == 0 && vdo.fullBelowSize == 0 {
+		return nil
+	}
+
+	// The copy algorithm is used so that every row is processed by the ALTER,
+	// and no lock clause is needed in the workspace
+	mods.AlgorithmClause = "copy"
+	mods.LockClause = ""
+
+	// Group ALTERs by table, retaining their original order, and skip any which
+	// are no-ops with these mods
+	var tableNames []string
+	alters := make(map[string][]string)
+	originals := make(map[string]*tengo.Table)
+	for _, td := range altersInDiff {
+		stmt, err := td.Statement(mods)
+		if stmt == "" || (err != nil && !tengo.IsUnsafeDiff(err)) {
+			continue
+		}
+		if _, already := alters[td.From.Name]; !already {
+			tableNames = append(tableNames, td.From.Name)
+			originals[td.From.Name] = td.From
+		}
+		alters[td.From.Name] = append(alters[td.From.Name], stmt)
+	}
+	if len(tableNames) == 0 {
+		return nil
+	}
+
+	vopts, err := VerifierOptionsForTarget(t)
+	if err != nil {
+		return err
+	}
+	liveDB, err := t.Instance.CachedConnectionPool(t.SchemaName, "")
+	if err != nil {
+		return err
+	}
+
+	// Determine how many rows to copy from each table. Tables which are neither
+	// small enough to copy in full nor eligible for sampling are skipped.
+	limits := make(map[string]int, len(tableNames))
+	var tableSize int64
+	for _, name := range tableNames {
+		if vdo.fullBelowSize > 0 {
+			if tableSize, err = t.Instance.TableSize(t.SchemaName, name); err != nil {
+				return err
+			}
+		}
+		if limit, ok := vdo.rowLimit(tableSize); ok {
+			limits[name] = limit
+		} else {
+			log.Debugf("Skipping data verification for table %s: size=%d >= verify-data-full-below-size=%d", tengo.EscapeIdentifier(name), tableSize, vdo.fullBelowSize)
+		}
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	ws, err := workspace.New(vopts.WorkspaceOptions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cleanupErr := ws.Cleanup(nil); cleanupErr != nil {
+			log.Warnf("Unable to clean up workspace after data verification: %s", cleanupErr)
+		}
+	}()
+	// Copied rows may be sensitive, so avoid writing them to the binary log, if
+	// the workspace configuration permits doing so
+	params := "foreign_key_checks=0"
+	if vopts.WorkspaceOptions.SkipBinlog {
+		params += "&sql_log_bin=0"
+	}
+	wsDB, err := ws.ConnectionPool(params)
+	if err != nil {
+		return fmt.Errorf("Cannot connect to workspace: %w", err)
+	}
+
+	var failures int
+	for _, name := range tableNames {
+		limit, ok := limits[name]
+		if !ok {
+			continue
+		}
+		if err := verifyTableData(liveDB, wsDB, originals[name], alters[name], limit); err != nil {
+			log.Errorf("Data verification failure on table %s for %s: %s", tengo.EscapeIdentifier(name), t, err)
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("Data verification failed for %s. Run command again with --verify-data=0 --verify-data-full-below-size=0 if this is safe to ignore", countAndNoun(failures, "table"))
+	}
+	return nil
+}
+
+// verifyTableData copies up to limit rows (or all rows, if limit is 0) from
+// table in liveDB into a new table in wsDB, and then runs the supplied ALTER
+// TABLE statements there. Any error from running the ALTERs is returned,
+// annotated with a description of its cause. Warnings from the ALTERs are
+// logged. The workspace table is always dropped before returning.
+func verifyTableData(liveDB, wsDB *sql.DB, table *tengo.Table, alters []string, limit int) (retErr error) {
+	data, err := tengo.QueryTableDataSample(liveDB, table, limit)
+	if err != nil {
+		return fmt.Errorf("unable to query rows: %w", err)
+	}
+
+	// A single connection is used, so that the session sql_mode applies to the
+	// ALTERs
+	ctx := context.Background()
+	conn, err := wsDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, table.CreateStatement); err != nil {
+		return fmt.Errorf("unable to create table in workspace: %w", err)
+	}
+	defer func() {
+		// Always drop the table here, since workspace cleanup may refuse to drop
+		// tables containing rows
+		if _, err := conn.ExecContext(ctx, "DROP TABLE "+tengo.EscapeIdentifier(table.Name)); err != nil && retErr == nil {
+			retErr = fmt.Errorf("unable to drop table in workspace: %w", err)
+		}
+	}()
+	for chunk := range slices.Chunk(data.Rows, verifyDataChunkSize) {
+		chunkData := &tengo.TableData{Table: table, Columns: data.Columns, Rows: chunk}
+		if _, err := conn.ExecContext(ctx, chunkData.InsertStatement()); err != nil {
+			return fmt.Errorf("unable to copy rows into workspace: %w", err)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "SET SESSION sql_mode = CONCAT(@@sql_mode, ',STRICT_ALL_TABLES')"); err != nil {
+		return err
+	}
+	for _, stmt := range alters {
+		_, err := conn.ExecContext(ctx, stmt)
+		if tengo.IsTruncationError(err) {
+			return fmt.Errorf("values would be truncated or rejected in %d copied rows: %w", len(data.Rows), err)
+		} else if tengo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("duplicate values found for a unique index in %d copied rows: %w", len(data.Rows), err)
+		} else if tengo.IsCheckConstraintError(err) {
+			return fmt.Errorf("check constraint violated in %d copied rows: %w", len(data.Rows), err)
+		} else if err != nil {
+			return err
+		}
+		if warnings := showWarnings(ctx, conn); len(warnings) > 0 {
+			log.Warnf("Data verification of table %s generated warnings:\n%s", tengo.EscapeIdentifier(table.Name), strings.Join(warnings, "\n"))
+		}
+	}
+	return nil
+}
+
+// showWarnings returns the warnings from the last statement run on conn,
+// formatted for display. Errors are ignored.
+func showWarnings(ctx context.Context, conn *sql.Conn) (warnings []string) {
+	rows, err := conn.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err := rows.Scan(&level, &code, &message); err == nil {
+			warnings = append(warnings, fmt.Sprintf("%s %d: %s", level, code, message))
+		}
+	}
+	return warnings
+}
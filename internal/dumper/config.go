@@ -1,16 +1,21 @@
 package dumper
 
 import (
+	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
 )
 
 // Options controls dumper behavior.
 type Options struct {
-	IncludeAutoInc bool                     // if false, strip AUTO_INCREMENT clauses from CREATE TABLE
-	Partitioning   tengo.PartitioningMode   // PartitioningKeep: retain previous FS partitioning clause; PartitioningRemove: strip partitioning clause
-	CountOnly      bool                     // if true, skip writing files, just report count of rewrites
-	skipKeys       map[tengo.ObjectKey]bool // skip objects with true values
-	onlyKeys       map[tengo.ObjectKey]bool // if map is non-nil, only format objects with true values
+	IncludeAutoInc bool                        // if false, strip AUTO_INCREMENT clauses from CREATE TABLE
+	Partitioning   tengo.PartitioningMode      // PartitioningKeep: retain previous FS partitioning clause; PartitioningRemove: strip partitioning clause
+	CountOnly      bool                        // if true, skip writing files, just report count of rewrites
+	Data           map[string]*tengo.TableData // table name => live rows, for tables with managed data
+	LogicalSchema  *fs.LogicalSchema           // logical schema to update; if nil, the dir's first logical schema is used
+	Relocate       bool                        // if true, move existing CREATE statements to the files dictated by the dir's layout
+	FoldAlters     bool                        // if true, remove ALTER statements from the patches file, since their effects are reflected in the CREATEs
+	skipKeys       map[tengo.ObjectKey]bool    // skip objects with true values
+	onlyKeys       map[tengo.ObjectKey]bool    // if map is non-nil, only format objects with true values
 }
 
 // OnlyKeys specifies a list of tengo.ObjectKeys that the dump should
@@ -6,6 +6,8 @@ package dumper
 
 import (
 	"errors"
+	"slices"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
@@ -19,22 +21,34 @@ import (
 // files is returned, along with any fatal write error. If opts.CountOnly is
 // true, no actual filesystem writes occur, but a file count is still returned.
 func DumpSchema(schema *tengo.Schema, dir *fs.Dir, opts Options) (int, error) {
-	// Ensure that this dir does not reference any schemas by name, either via
-	// USE commands or CREATEs with schema name qualifiers
-	if len(dir.NamedSchemaStatements) > 0 {
+	// Schema names may be referenced in *.sql files via USE commands or CREATEs
+	// with schema name qualifiers, but only if the dir's .skeema file does not
+	// also configure a schema name
+	if len(dir.NamedSchemaStatements) > 0 && len(dir.LogicalSchemas) > 0 && dir.LogicalSchemas[0].Name == "" {
 		if len(dir.NamedSchemaStatements) == 1 {
 			log.Warnf("This directory contains a statement referencing a specific schema name at %s line %d.", dir.NamedSchemaStatements[0].File, dir.NamedSchemaStatements[0].LineNo)
 		} else {
 			log.Warnf("This directory contains %d statements referencing specific schema names, for example %s line %d.", len(dir.NamedSchemaStatements), dir.NamedSchemaStatements[0].File, dir.NamedSchemaStatements[0].LineNo)
 		}
-		log.Warn("Most Skeema commands do not support USE statements or schema-prefixed table names yet.")
-		log.Warn("Please configure schema names only in .skeema files.")
+		log.Warn("When configuring a schema name in .skeema, please omit schema names entirely from *.sql files.")
 		return 0, errors.New("unsupported format of .sql files")
 	}
+	if opts.LogicalSchema == nil {
+		opts.LogicalSchema = dir.LogicalSchemas[0]
+	}
 
 	if err := updateCreateStatements(schema, dir, opts); err != nil {
 		return 0, err
 	}
+	if opts.Relocate {
+		relocateCreateStatements(schema, dir, opts)
+	}
+	if len(opts.Data) > 0 {
+		updateDataStatements(dir, opts)
+	}
+	if opts.FoldAlters {
+		removeAlterStatements(dir, opts)
+	}
 	filesWithDiffs := dir.DirtyFiles()
 	for n, file := range filesWithDiffs {
 		if opts.CountOnly {
@@ -63,67 +77,56 @@ func DumpSchema(schema *tengo.Schema, dir *fs.Dir, opts Options) (int, error) {
 // addition to files being marked as dirty. No writes are ever persisted to the
 // filesystem by this function.
 func updateCreateStatements(schema *tengo.Schema, dir *fs.Dir, opts Options) error {
-	// TODO: handle dirs that contain multiple logical schemas by name
-	logicalSchema := dir.LogicalSchemas[0]
+	logicalSchema := opts.LogicalSchema
+	useQualifiers := logicalSchema.Name != "" && hasQualifiedCreates(logicalSchema)
 
+	// Objects are processed in a deterministic order, so that statements are
+	// consistently ordered in files containing multiple objects
 	dbObjects := schema.Objects()
-	for key, object := range dbObjects {
+	for _, key := range sortedKeys(dbObjects) {
+		object := dbObjects[key]
 		if opts.shouldIgnore(object) {
 			continue
 		}
-		canonicalCreate := object.Def()
-		var fsCreate string
 		stmt := logicalSchema.Creates[key]
-		if stmt != nil {
-			fsCreate, _ = stmt.SplitTextBody()
-		}
-
-		// Include or strip auto_increment clause. (Note that if fs representation
-		// already exists and explicitly had an autoinc value > 1, we keep and update
-		// it regardless.)
-		if key.Type == tengo.ObjectTypeTable && !opts.IncludeAutoInc {
-			if _, fsAutoInc := tengo.ParseCreateAutoInc(fsCreate); fsAutoInc <= 1 {
-				canonicalCreate, _ = tengo.ParseCreateAutoInc(canonicalCreate)
-			}
-		}
-
-		// If requested, adjust the canonical create to add the partitioning clause
-		// from the filesystem create, or remove it
-		if key.Type == tengo.ObjectTypeTable && opts.Partitioning != tengo.PartitioningPermissive {
-			dbCreateBase, _ := tengo.ParseCreatePartitioning(canonicalCreate)
-			if opts.Partitioning == tengo.PartitioningKeep && fsCreate != "" {
-				_, fsCreatePart := tengo.ParseCreatePartitioning(fsCreate)
-				canonicalCreate = dbCreateBase + fsCreatePart
-			} else if opts.Partitioning == tengo.PartitioningRemove {
-				canonicalCreate = dbCreateBase
-			}
-		}
-
+		canonicalCreate := CanonicalCreate(object, stmt, opts)
 		newStmt := tengo.ParseStatementInString(canonicalCreate)
 		if newStmt.Type != tengo.StatementTypeCreate || newStmt.ObjectKey() != key {
 			log.Errorf("%s is unexpectedly not able to be parsed by Skeema\nPlease file an issue report at https://github.com/skeema/skeema/issues with the problematic statement, redacting sensitive portions if necessary:\n%s", key, canonicalCreate)
 			log.Error("Unfortunately this error is fatal and prevents Skeema from being usable in your environment until this is resolved.")
 			return errors.New("fatal parser exception")
 		}
+		var fsCreate string
+		if stmt != nil {
+			fsCreate = stmt.Body() // without schema name qualifier, if any
+		}
 
 		if stmt == nil {
 			// We didn't have a Statement from the fs, so append a new one, or just mark
-			// the file as dirty if doing CountOnly.
+			// the file as dirty if doing CountOnly. In a named logical schema, follow
+			// the style of its existing statements: either qualify the new statement's
+			// object name with the schema name, or place it after a USE command.
 			sqlFile := dir.FileFor(object)
 			if opts.CountOnly {
 				sqlFile.Dirty = true
+				continue
+			}
+			if useQualifiers {
+				qualifiedClause := tengo.EscapeIdentifier(logicalSchema.Name) + "." + tengo.EscapeIdentifier(key.Name)
+				newStmt = tengo.ParseStatementInString(newStmt.ReplaceNameClause(qualifiedClause))
 			} else {
-				sqlFile.AddStatement(newStmt)
+				newStmt.DefaultDatabase = logicalSchema.Name
 			}
+			sqlFile.AddStatement(newStmt)
 		} else if fsCreate != canonicalCreate {
 			// Statement came from the fs and we need to update it, or just mark its
-			// file as dirty if doing CountOnly
-			sqlFile := dir.FileFor(stmt)
+			// file as dirty if doing CountOnly. If the original statement had a schema
+			// name qualifier, retain it as-is.
 			if opts.CountOnly {
-				sqlFile.Dirty = true
-			} else {
-				sqlFile.EditStatementText(stmt, canonicalCreate, newStmt.Compound)
+				dir.FileFor(stmt).Dirty = true
+				continue
 			}
+			editCreateStatement(dir, stmt, canonicalCreate, newStmt)
 		}
 	}
 
@@ -141,3 +144,258 @@ func updateCreateStatements(schema *tengo.Schema, dir *fs.Dir, opts Options) err
 
 	return nil
 }
+
+// CanonicalCreate returns the canonical CREATE statement for object, as it
+// should be written to the filesystem. stmt should be the object's existing
+// CREATE statement from the filesystem, or nil if there isn't one yet. The
+// handling of AUTO_INCREMENT and partitioning clauses depends on opts and
+// stmt, and any line-level skeema:lint-ignore comments in stmt are retained.
+func CanonicalCreate(object tengo.DefKeyer, stmt *tengo.Statement, opts Options) string {
+	key := object.ObjectKey()
+	canonicalCreate := object.Def()
+	var fsCreate string
+	if stmt != nil {
+		fsCreate = stmt.Body() // without schema name qualifier, if any
+	}
+
+	// Include or strip auto_increment clause. (Note that if fs representation
+	// already exists and explicitly had an autoinc value > 1, we keep and update
+	// it regardless.)
+	if key.Type == tengo.ObjectTypeTable && !opts.IncludeAutoInc {
+		if _, fsAutoInc := tengo.ParseCreateAutoInc(fsCreate); fsAutoInc <= 1 {
+			canonicalCreate, _ = tengo.ParseCreateAutoInc(canonicalCreate)
+		}
+	}
+
+	// If requested, adjust the canonical create to add the partitioning clause
+	// from the filesystem create, or remove it
+	if key.Type == tengo.ObjectTypeTable && opts.Partitioning != tengo.PartitioningPermissive {
+		dbCreateBase, _ := tengo.ParseCreatePartitioning(canonicalCreate)
+		if opts.Partitioning == tengo.PartitioningKeep && fsCreate != "" {
+			_, fsCreatePart := tengo.ParseCreatePartitioning(fsCreate)
+			canonicalCreate = dbCreateBase + fsCreatePart
+		} else if opts.Partitioning == tengo.PartitioningRemove {
+			canonicalCreate = dbCreateBase
+		}
+	}
+
+	// Retain any skeema:lint-ignore comments from the filesystem version of a
+	// CREATE TABLE, since the canonical create lacks comments
+	if stmt != nil {
+		canonicalCreate = preserveLintIgnores(stmt, canonicalCreate)
+	}
+	return canonicalCreate
+}
+
+// relocateCreateStatements moves CREATE statements which are not in the file
+// dictated by the dir's layout. Each moved statement is placed at the end of
+// its new file. If opts.CountOnly is true, the affected files are just marked
+// as dirty instead.
+func relocateCreateStatements(schema *tengo.Schema, dir *fs.Dir, opts Options) {
+	logicalSchema := opts.LogicalSchema
+	dbObjects := schema.Objects()
+	for _, key := range sortedKeys(logicalSchema.Creates) {
+		if _, inDB := dbObjects[key]; !inDB && !opts.shouldIgnore(key) {
+			continue // already removed by updateCreateStatements
+		}
+		stmt := logicalSchema.Creates[key]
+		oldFile, newFile := dir.FileFor(stmt), dir.FileFor(key)
+		if oldFile == newFile {
+			continue
+		} else if opts.CountOnly {
+			oldFile.Dirty = true
+			newFile.Dirty = true
+			continue
+		}
+		body, _ := stmt.SplitTextBody()
+		movedStmt := tengo.ParseStatementInString(body)
+		if movedStmt.ObjectQualifier == "" {
+			movedStmt.DefaultDatabase = logicalSchema.Name
+		}
+		oldFile.RemoveStatement(stmt)
+		newFile.AddStatement(movedStmt)
+		logicalSchema.Creates[key] = movedStmt
+	}
+}
+
+// removeAlterStatements removes the logical schema's ALTER statements, which
+// must only be done once their effects have been folded into the corresponding
+// CREATE statements. Statements affecting ignored objects are retained, since
+// these objects' CREATEs were not updated. If opts.CountOnly is true, the
+// affected files are just marked as dirty instead.
+func removeAlterStatements(dir *fs.Dir, opts Options) {
+	for _, stmt := range opts.LogicalSchema.Alters {
+		if opts.shouldIgnore(stmt) {
+			continue
+		}
+		sqlFile := dir.FileFor(stmt)
+		if opts.CountOnly {
+			sqlFile.Dirty = true
+		} else {
+			sqlFile.RemoveStatement(stmt)
+		}
+	}
+}
+
+// EditCreateStatement rewrites stmt, which must be a CREATE statement from a
+// file in dir, to instead use newCreate. If stmt's object name had a schema
+// name qualifier, it is retained. Line-level skeema:lint-ignore comments are
+// also retained, as long as the same column, index, or constraint is still
+// present in newCreate. The file is marked as dirty, but not written.
+func EditCreateStatement(dir *fs.Dir, stmt *tengo.Statement, newCreate string) {
+	newCreate = preserveLintIgnores(stmt, newCreate)
+	editCreateStatement(dir, stmt, newCreate, tengo.ParseStatementInString(newCreate))
+}
+
+// editCreateStatement rewrites stmt to use newCreate, which has already been
+// parsed into newStmt. Any schema name qualifier from stmt is retained.
+func editCreateStatement(dir *fs.Dir, stmt *tengo.Statement, newCreate string, newStmt *tengo.Statement) {
+	sqlFile := dir.FileFor(stmt)
+	if stmt.ObjectQualifier != "" {
+		sqlFile.EditStatementText(stmt, newStmt.ReplaceNameClause(stmt.NameClause()), newStmt.Compound)
+	} else {
+		sqlFile.EditStatementText(stmt, newCreate, newStmt.Compound)
+	}
+	stmt.LintIgnores = editedLintIgnores(stmt, newStmt)
+}
+
+// preserveLintIgnores returns canonicalCreate with any line-level
+// skeema:lint-ignore comments from stmt re-attached as trailing comments, on
+// the lines defining the same columns or indexes as in stmt. Statement-level
+// comments located on the first line of stmt are re-attached to the first line
+// of canonicalCreate. This is only meaningful for CREATE TABLE statements;
+// canonicalCreate is returned unchanged for other object types, since their
+// canonical form already retains comments within the object's body.
+func preserveLintIgnores(stmt *tengo.Statement, canonicalCreate string) string {
+	if stmt.ObjectType != tengo.ObjectTypeTable {
+		return canonicalCreate
+	}
+	var origLines, newLines []string
+	for _, li := range stmt.LintIgnores {
+		if li.LineOffset < 0 && li.LineNo < stmt.LineNo {
+			continue // in a comment preceding the statement, so no need to move it
+		}
+		if origLines == nil {
+			origLines = strings.Split(stmt.Text, "\n")
+			newLines = strings.Split(canonicalCreate, "\n")
+		}
+		if li.LineOffset < 0 {
+			newLines[0] += " " + li.String()
+			continue
+		} else if li.LineOffset >= len(origLines) {
+			continue
+		}
+		key := lintIgnoreLineKey(origLines[li.LineOffset])
+		if key == "" {
+			continue
+		}
+		for n := 1; n < len(newLines); n++ {
+			if lintIgnoreLineKey(newLines[n]) == key {
+				newLines[n] += " " + li.String()
+				break
+			}
+		}
+	}
+	if newLines == nil {
+		return canonicalCreate
+	}
+	return strings.Join(newLines, "\n")
+}
+
+// lintIgnoreLineKey returns a string identifying the column, index, or
+// constraint defined on the supplied line of a CREATE TABLE, or an empty string
+// if the line does not define any of these.
+func lintIgnoreLineKey(line string) string {
+	kind := "column"
+	for _, token := range tengo.TokenizeStringN(line, 4) {
+		switch strings.ToUpper(token) {
+		case "PRIMARY":
+			return "index:primary"
+		case "UNIQUE", "FULLTEXT", "SPATIAL", "KEY", "INDEX":
+			kind = "index"
+		case "CONSTRAINT", "FOREIGN", "CHECK":
+			kind = "constraint"
+		case "(", ")", ",", "CREATE":
+			return ""
+		default:
+			return kind + ":" + strings.ToLower(strings.Trim(token, "`"))
+		}
+	}
+	return ""
+}
+
+// editedLintIgnores returns the skeema:lint-ignore comments of stmt after its
+// text has been rewritten to match newStmt. Statement-level comments preceding
+// stmt are retained as-is, while comments within the statement text are
+// obtained from newStmt, since their line offsets may have changed.
+func editedLintIgnores(stmt, newStmt *tengo.Statement) (result []tengo.LintIgnore) {
+	for _, li := range stmt.LintIgnores {
+		if li.LineOffset < 0 && li.LineNo < stmt.LineNo {
+			result = append(result, li)
+		}
+	}
+	for _, li := range newStmt.LintIgnores {
+		li.LineNo = stmt.LineNo + max(li.LineOffset, 0)
+		result = append(result, li)
+	}
+	return result
+}
+
+// sortedKeys returns the keys of m, sorted by object type and then name.
+func sortedKeys[V any](m map[tengo.ObjectKey]V) []tengo.ObjectKey {
+	keys := make([]tengo.ObjectKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b tengo.ObjectKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return keys
+}
+
+// hasQualifiedCreates returns true if any CREATE statement in logicalSchema
+// uses a schema name qualifier before the object name.
+func hasQualifiedCreates(logicalSchema *fs.LogicalSchema) bool {
+	for _, stmt := range logicalSchema.Creates {
+		if stmt.ObjectQualifier != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// updateDataStatements rewrites the INSERT statements of each table in
+// opts.Data, so that the filesystem's managed data rows match the supplied
+// live rows. The first INSERT statement for each table is replaced with a
+// single canonical multi-row INSERT, and any additional INSERTs for that table
+// are removed. If the live table has no rows, all of its INSERTs are removed.
+func updateDataStatements(dir *fs.Dir, opts Options) {
+	logicalSchema := opts.LogicalSchema
+	byTable := make(map[string][]*tengo.Statement)
+	for _, stmt := range logicalSchema.Inserts {
+		byTable[stmt.ObjectName] = append(byTable[stmt.ObjectName], stmt)
+	}
+	for _, name := range logicalSchema.InsertTableNames() {
+		data := opts.Data[name]
+		if data == nil || opts.shouldIgnore(data.Table) {
+			continue
+		}
+		canonicalInsert := data.InsertStatement()
+		for n, stmt := range byTable[name] {
+			sqlFile := dir.FileFor(stmt)
+			if n == 0 && canonicalInsert != "" {
+				if fsInsert, _ := stmt.SplitTextBody(); fsInsert == canonicalInsert {
+					continue
+				} else if opts.CountOnly {
+					sqlFile.Dirty = true
+				} else {
+					sqlFile.EditStatementText(stmt, canonicalInsert, false)
+				}
+			} else if opts.CountOnly {
+				sqlFile.Dirty = true
+			} else {
+				sqlFile.RemoveStatement(stmt)
+			}
+		}
+	}
+}
@@ -50,6 +50,294 @@ func TestIntegration(t *testing.T) {
 	}
 }
 
+// TestDumpSchemaNamedSchemas confirms that DumpSchema preserves the style of
+// schema name references in a dir that uses USE commands and/or prefixed
+// (dbname.objectname) CREATE statements, without any schema name in .skeema.
+func TestDumpSchemaNamedSchemas(t *testing.T) {
+	dirPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(dirPath, "a.sql"), "CREATE TABLE somedb.foo (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "b.sql"), "USE otherdb\nCREATE TABLE bar (id int);\n")
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	} else if len(dir.LogicalSchemas) != 2 {
+		t.Fatalf("Expected dir to have 2 logical schemas, instead found %d", len(dir.LogicalSchemas))
+	}
+
+	createFor := func(name string) string {
+		return "CREATE TABLE `" + name + "` (\n  `id` int DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	}
+	for _, logicalSchema := range dir.LogicalSchemas {
+		schema := &tengo.Schema{Name: logicalSchema.Name}
+		for key := range logicalSchema.Creates {
+			for _, name := range []string{key.Name, key.Name + "2"} {
+				schema.Tables = append(schema.Tables, &tengo.Table{Name: name, CreateStatement: createFor(name)})
+			}
+		}
+		opts := Options{LogicalSchema: logicalSchema}
+		if count, err := DumpSchema(schema, dir, opts); count != 2 || err != nil {
+			t.Errorf("Expected DumpSchema() to return (2, nil); instead found (%d, %v)", count, err)
+		}
+	}
+
+	expected := map[string]string{
+		"a.sql":    strings.Replace(createFor("foo"), "`foo`", "somedb.foo", 1) + ";\n",
+		"foo2.sql": strings.Replace(createFor("foo2"), "`foo2`", "`somedb`.`foo2`", 1) + ";\n",
+		"b.sql":    "USE otherdb\n" + createFor("bar") + ";\n",
+		"bar2.sql": "USE `otherdb`\n" + createFor("bar2") + ";\n",
+	}
+	for fileName, expectContents := range expected {
+		if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, fileName)); actualContents != expectContents {
+			t.Errorf("Mismatch for contents of %s:\nExpected:\n%s\nActual:\n%s", fileName, expectContents, actualContents)
+		}
+	}
+}
+
+// TestDumpSchemaRelocate confirms that DumpSchema moves existing statements to
+// match the dir's layout when the Relocate option is used.
+func TestDumpSchemaRelocate(t *testing.T) {
+	dirPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\nlayout={types}/{name}.sql\ngroup-routines\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), "CREATE TABLE foo (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "p2.sql"), "CREATE PROCEDURE p2() SELECT 2;\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "p1.sql"), "CREATE PROCEDURE p1() SELECT 1;\n")
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+	createFoo := "CREATE TABLE `foo` (\n  `id` int DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	schema := &tengo.Schema{
+		Name:   "foo",
+		Tables: []*tengo.Table{{Name: "foo", CreateStatement: createFoo}},
+		Routines: []*tengo.Routine{
+			{Name: "p1", Type: tengo.ObjectTypeProc, CreateStatement: "CREATE PROCEDURE p1() SELECT 1"},
+			{Name: "p2", Type: tengo.ObjectTypeProc, CreateStatement: "CREATE PROCEDURE p2() SELECT 2"},
+		},
+	}
+
+	// Without Relocate, only the reformatted table file should be rewritten
+	if count, err := DumpSchema(schema, dir, Options{CountOnly: true}); count != 1 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (1, nil); instead found (%d, %v)", count, err)
+	}
+
+	// With Relocate, all 3 old files are deleted, and 2 new files are created
+	if count, err := DumpSchema(schema, dir, Options{Relocate: true}); count != 5 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (5, nil); instead found (%d, %v)", count, err)
+	}
+	expected := map[string]string{
+		"tables/foo.sql":        createFoo + ";\n",
+		"routines/routines.sql": "CREATE PROCEDURE p1() SELECT 1;\nCREATE PROCEDURE p2() SELECT 2;\n",
+	}
+	for fileName, expectContents := range expected {
+		if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, filepath.FromSlash(fileName))); actualContents != expectContents {
+			t.Errorf("Mismatch for contents of %s:\nExpected:\n%s\nActual:\n%s", fileName, expectContents, actualContents)
+		}
+	}
+	for _, fileName := range []string{"foo.sql", "p1.sql", "p2.sql"} {
+		if _, err := os.Stat(filepath.Join(dirPath, fileName)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted, but stat returned err=%v", fileName, err)
+		}
+	}
+}
+
+func TestDumpSchemaFoldAlters(t *testing.T) {
+	dirPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\npatches-file=patches.sql\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), "CREATE TABLE foo (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "patches.sql"), "ALTER TABLE foo ADD COLUMN name varchar(30);\nCREATE INDEX idx_name ON foo (name);\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "other.sql"), "ALTER TABLE foo DROP COLUMN id;\n")
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	} else if alters := dir.LogicalSchemas[0].Alters; len(alters) != 2 {
+		t.Fatalf("Expected logical schema to have 2 alters, instead found %d", len(alters))
+	} else if len(dir.UnparsedStatements) != 1 || dir.UnparsedStatements[0].File != filepath.Join(dirPath, "other.sql") {
+		t.Fatalf("Expected ALTER outside of patches file to be unparsed, instead found %+v", dir.UnparsedStatements)
+	}
+	createFoo := "CREATE TABLE `foo` (\n  `id` int DEFAULT NULL,\n  `name` varchar(30) DEFAULT NULL,\n  KEY `idx_name` (`name`)\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	schema := &tengo.Schema{
+		Name:   "foo",
+		Tables: []*tengo.Table{{Name: "foo", CreateStatement: createFoo}},
+	}
+
+	// Without FoldAlters, only the table file should be rewritten
+	if count, err := DumpSchema(schema, dir, Options{CountOnly: true}); count != 1 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (1, nil); instead found (%d, %v)", count, err)
+	}
+
+	// With FoldAlters, the patches file is emptied and therefore deleted, but
+	// the unparsed ALTER in other.sql is left alone
+	if count, err := DumpSchema(schema, dir, Options{FoldAlters: true}); count != 2 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (2, nil); instead found (%d, %v)", count, err)
+	}
+	if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, "foo.sql")); actualContents != createFoo+";\n" {
+		t.Errorf("Unexpected contents of foo.sql:\n%s", actualContents)
+	}
+	if _, err := os.Stat(filepath.Join(dirPath, "patches.sql")); !os.IsNotExist(err) {
+		t.Errorf("Expected patches.sql to be deleted, but stat returned err=%v", err)
+	}
+	if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, "other.sql")); actualContents != "ALTER TABLE foo DROP COLUMN id;\n" {
+		t.Errorf("Unexpected contents of other.sql:\n%s", actualContents)
+	}
+}
+
+func TestDumpSchemaLintIgnores(t *testing.T) {
+	dirPath := t.TempDir()
+	contents := `-- skeema:lint-ignore pk
+CREATE TABLE foo (
+  id int,
+  -- skeema:lint-ignore has-float
+  price float,
+  key idx_price (price) -- skeema:lint-ignore dupe-index
+);
+`
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), contents)
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+	createFoo := "CREATE TABLE `foo` (\n  `id` int DEFAULT NULL,\n  `price` float DEFAULT NULL,\n  KEY `idx_price` (`price`)\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	schema := &tengo.Schema{
+		Name:   "foo",
+		Tables: []*tengo.Table{{Name: "foo", CreateStatement: createFoo}},
+	}
+	if count, err := DumpSchema(schema, dir, Options{}); count != 1 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (1, nil); instead found (%d, %v)", count, err)
+	}
+	expected := "-- skeema:lint-ignore pk\n" +
+		"CREATE TABLE `foo` (\n  `id` int DEFAULT NULL,\n" +
+		"  `price` float DEFAULT NULL, -- skeema:lint-ignore has-float\n" +
+		"  KEY `idx_price` (`price`) -- skeema:lint-ignore dupe-index\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1;\n"
+	if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, "foo.sql")); actualContents != expected {
+		t.Errorf("Unexpected contents of foo.sql:\n%s", actualContents)
+	}
+	stmt := dir.LogicalSchemas[0].Creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "foo"}]
+	if len(stmt.LintIgnores) != 3 || stmt.LintIgnores[1].LineNo != 4 || stmt.LintIgnores[2].LineOffset != 3 {
+		t.Errorf("Unexpected LintIgnores after rewrite: %+v", stmt.LintIgnores)
+	}
+
+	// Dumping again should be a no-op, now that the comments are in canonical
+	// positions
+	if dir, err = getDir(dirPath); err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+	if count, err := DumpSchema(schema, dir, Options{CountOnly: true}); count != 0 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (0, nil); instead found (%d, %v)", count, err)
+	}
+}
+
+func TestDumpSchemaStatementLintIgnores(t *testing.T) {
+	// A statement-level comment on the first line of the statement is part of
+	// the statement's text, and must be retained on the first line of the
+	// canonical CREATE
+	dirPath := t.TempDir()
+	contents := "CREATE TABLE foo ( -- skeema:lint-ignore pk,has-float\n" +
+		"  price float\n" +
+		");\n"
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), contents)
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+	key := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "foo"}
+	if lis := dir.LogicalSchemas[0].Creates[key].LintIgnores; len(lis) != 1 || lis[0].LineOffset != -1 {
+		t.Fatalf("Unexpected LintIgnores before rewrite: %+v", lis)
+	}
+	createFoo := "CREATE TABLE `foo` (\n  `price` float DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	schema := &tengo.Schema{
+		Name:   "foo",
+		Tables: []*tengo.Table{{Name: "foo", CreateStatement: createFoo}},
+	}
+	if count, err := DumpSchema(schema, dir, Options{}); count != 1 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (1, nil); instead found (%d, %v)", count, err)
+	}
+	expected := "CREATE TABLE `foo` ( -- skeema:lint-ignore pk,has-float\n" +
+		"  `price` float DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1;\n"
+	if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, "foo.sql")); actualContents != expected {
+		t.Errorf("Unexpected contents of foo.sql:\n%s", actualContents)
+	}
+	stmt := dir.LogicalSchemas[0].Creates[key]
+	if len(stmt.LintIgnores) != 1 || stmt.LintIgnores[0].LineOffset != -1 || len(stmt.LintIgnores[0].Rules) != 2 {
+		t.Errorf("Unexpected LintIgnores after rewrite: %+v", stmt.LintIgnores)
+	}
+
+	// Dumping again should be a no-op, and must not lose the comment
+	if dir, err = getDir(dirPath); err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+	if count, err := DumpSchema(schema, dir, Options{}); count != 0 || err != nil {
+		t.Errorf("Expected DumpSchema() to return (0, nil); instead found (%d, %v)", count, err)
+	}
+	if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, "foo.sql")); actualContents != expected {
+		t.Errorf("Unexpected contents of foo.sql after second dump:\n%s", actualContents)
+	}
+}
+
+func TestDumpSchemaRoutineLintIgnores(t *testing.T) {
+	// Routine bodies retain their comments in canonical form, so lint-ignore
+	// comments inside the body must not be re-attached by the dumper. Otherwise,
+	// each dump would add another copy.
+	dirPath := t.TempDir()
+	createProc := "CREATE PROCEDURE `p1`()\n" +
+		"BEGIN\n" +
+		"  SELECT * FROM foo; -- skeema:lint-ignore\n" +
+		"END"
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\n")
+	fs.WriteTestFile(t, filepath.Join(dirPath, "p1.sql"), "DELIMITER //\n"+createProc+"//\nDELIMITER ;\n")
+	schema := &tengo.Schema{
+		Name: "foo",
+		Routines: []*tengo.Routine{
+			{Name: "p1", Type: tengo.ObjectTypeProc, CreateStatement: createProc},
+		},
+	}
+	expected := fs.ReadTestFile(t, filepath.Join(dirPath, "p1.sql"))
+	for n := 1; n <= 2; n++ {
+		dir, err := getDir(dirPath)
+		if err != nil {
+			t.Fatalf("Unexpected error from getDir: %v", err)
+		}
+		if count, err := DumpSchema(schema, dir, Options{}); count != 0 || err != nil {
+			t.Errorf("Dump %d: expected DumpSchema() to return (0, nil); instead found (%d, %v)", n, count, err)
+		}
+		if actualContents := fs.ReadTestFile(t, filepath.Join(dirPath, "p1.sql")); actualContents != expected {
+			t.Errorf("Dump %d: unexpected contents of p1.sql:\n%s", n, actualContents)
+		}
+	}
+}
+
+func TestEditCreateStatement(t *testing.T) {
+	dirPath := t.TempDir()
+	contents := "CREATE TABLE foo.bar (\n" +
+		"  id int(5), -- skeema:lint-ignore pk\n" +
+		"  price float -- skeema:lint-ignore has-float\n" +
+		");\n"
+	fs.WriteTestFile(t, filepath.Join(dirPath, "bar.sql"), contents)
+	dir, err := getDir(dirPath)
+	if err != nil {
+		t.Fatalf("Unexpected error from getDir: %v", err)
+	}
+	stmt := dir.LogicalSchemas[0].Creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "bar"}]
+	newCreate := "CREATE TABLE `bar` (\n  `id` int(11) DEFAULT NULL,\n  `amount` decimal(10,2) DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"
+	EditCreateStatement(dir, stmt, newCreate)
+	expected := "CREATE TABLE foo.bar (\n" +
+		"  `id` int(11) DEFAULT NULL, -- skeema:lint-ignore pk\n" +
+		"  `amount` decimal(10,2) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1;\n"
+	if stmt.Text != expected {
+		t.Errorf("Unexpected statement text after EditCreateStatement:\n%s", stmt.Text)
+	}
+	if len(stmt.LintIgnores) != 1 || stmt.LintIgnores[0].LineOffset != 1 {
+		t.Errorf("Unexpected LintIgnores after EditCreateStatement: %+v", stmt.LintIgnores)
+	}
+	if !dir.FileFor(stmt).Dirty {
+		t.Error("Expected file to be marked as dirty, but it was not")
+	}
+}
+
 type DumperIntegrationSuite struct {
 	d *tengo.DockerizedInstance
 }
@@ -146,7 +434,8 @@ func (s DumperIntegrationSuite) TestDumperPull(t *testing.T) {
 
 // TestDumperNamedSchemas confirms errors are returned when attempting to
 // format a dir containing either 'USE' commands or prefixed (dbname.objectname)
-// CREATE statements.
+// CREATE statements, if other statements in the dir do not reference a schema
+// name.
 func (s DumperIntegrationSuite) TestDumperNamedSchemas(t *testing.T) {
 	_, schema, _ := s.setupDirAndDB(t, "basic")
 
@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// ChangedObjectKeys returns the keys of objects in dir whose CREATE statements
+// differ from the versions in the supplied git ref, including objects which
+// did not exist at that ref. The result is keyed by logical schema name, with
+// an entry for every logical schema in dir, even if none of its objects
+// changed. Only *.sql files belonging to dir are examined at the ref: those
+// directly in dir, and those in the same subdirs that ParseDir reads for dir. Statements are compared by their text, so changes to
+// whitespace or formatting are considered changes as well. The dir must be
+// located within a git repo, and ref must be resolvable by git; otherwise a
+// ConfigError is returned.
+func (dir *Dir) ChangedObjectKeys(ref string) (map[string][]tengo.ObjectKey, error) {
+	git := func(commandLine string, variables map[string]string) (string, error) {
+		command, err := shellout.New(commandLine).WithVariables(variables)
+		if err != nil {
+			return "", err
+		}
+		stdout, _, err := command.WithWorkingDir(dir.Path).RunCaptureSeparate()
+		return stdout, err
+	}
+	refVars := map[string]string{"REF": ref + "^{commit}"}
+	if _, err := git("git rev-parse --verify --quiet {REF}", refVars); err != nil {
+		return nil, ConfigErrorf("Unable to resolve git ref %q in %s", ref, dir)
+	}
+	fileList, err := git("git ls-tree -r -z --name-only {REF} ./", refVars)
+	if err != nil {
+		return nil, ConfigErrorf("Unable to list files of git ref %q in %s: %w", ref, dir, err)
+	}
+
+	// Determine which subdirs contain *.sql files belonging to dir, using the
+	// same rules as ParseDir
+	ownedDirs := append([]string{"."}, dir.layoutSubdirs()...)
+
+	// Obtain the text of each CREATE statement as of ref, keyed by logical schema
+	// name and then object key
+	origBodies := make(map[string]map[tengo.ObjectKey]string)
+	for _, name := range strings.Split(fileList, "\x00") {
+		name = filepath.FromSlash(name)
+		if !strings.HasSuffix(strings.ToLower(name), ".sql") || !slices.Contains(ownedDirs, filepath.Dir(name)) {
+			continue
+		}
+		contents, err := git("git show {FILE}", map[string]string{"FILE": ref + ":./" + filepath.ToSlash(name)})
+		if err != nil {
+			return nil, ConfigErrorf("Unable to obtain %s from git ref %q: %w", name, ref, err)
+		}
+		statements, err := tengo.ParseStatements(strings.NewReader(contents), filepath.Join(dir.Path, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range statements {
+			if stmt.Type == tengo.StatementTypeCreate {
+				schemaName := stmt.Schema()
+				if origBodies[schemaName] == nil {
+					origBodies[schemaName] = make(map[tengo.ObjectKey]string)
+				}
+				origBodies[schemaName][stmt.ObjectKey()] = stmt.Body()
+			}
+		}
+	}
+
+	changed := make(map[string][]tengo.ObjectKey, len(dir.LogicalSchemas))
+	for _, logicalSchema := range dir.LogicalSchemas {
+		keys := []tengo.ObjectKey{}
+		for key, stmt := range logicalSchema.Creates {
+			if origBody, ok := origBodies[logicalSchema.Name][key]; !ok || origBody != stmt.Body() {
+				keys = append(keys, key)
+			}
+		}
+		changed[logicalSchema.Name] = keys
+	}
+	return changed, nil
+}
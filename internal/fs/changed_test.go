@@ -0,0 +1,101 @@
+package fs
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestDirChangedObjectKeys(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available on PATH")
+	}
+	repoPath := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unexpected error from git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	dirPath := filepath.Join(repoPath, "mydb")
+	WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "layout={types}/{name}.sql\n")
+	WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), "CREATE TABLE foo (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "bar.sql"), "CREATE TABLE bar (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "multi.sql"), "CREATE TABLE one (id int);\nCREATE TABLE two (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "tables", "sub.sql"), "CREATE TABLE sub (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "analytics.sql"), "USE analytics;\nCREATE TABLE foo (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "nested", ".skeema"), "schema=nested\n")
+	WriteTestFile(t, filepath.Join(dirPath, "nested", "nested.sql"), "CREATE TABLE nested (id int);\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+
+	sortedKeys := func(changed map[string][]tengo.ObjectKey, schemaName string) []tengo.ObjectKey {
+		keys := changed[schemaName]
+		slices.SortFunc(keys, func(a, b tengo.ObjectKey) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		return keys
+	}
+
+	// Nothing has changed yet, including objects in a layout subdir and objects
+	// in a second logical schema
+	dir := getDir(t, dirPath)
+	changed, err := dir.ChangedObjectKeys("HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error from ChangedObjectKeys: %v", err)
+	} else if len(changed) != 2 {
+		t.Errorf("Expected an entry for 2 logical schemas, instead found %v", changed)
+	}
+	for schemaName, keys := range changed {
+		if len(keys) != 0 {
+			t.Errorf("Expected no changed keys in logical schema %q, instead found %v", schemaName, keys)
+		}
+	}
+
+	// Modify one table, add a new table, move another table to a different file,
+	// move a table into a layout subdir, and delete a table. Also move a table in
+	// the second logical schema, and modify a table in a nested dir with its own
+	// .skeema file.
+	WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), "CREATE TABLE foo (id int, name varchar(30));\n")
+	WriteTestFile(t, filepath.Join(dirPath, "baz.sql"), "CREATE TABLE baz (id int);\nCREATE TABLE two (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "multi.sql"), "")
+	WriteTestFile(t, filepath.Join(dirPath, "tables", "one.sql"), "CREATE TABLE one (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "analytics.sql"), "")
+	WriteTestFile(t, filepath.Join(dirPath, "tables", "analytics.sql"), "USE analytics;\nCREATE TABLE foo (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "nested", "nested.sql"), "CREATE TABLE nested (id bigint);\n")
+	RemoveTestFile(t, filepath.Join(dirPath, "bar.sql"))
+	dir = getDir(t, dirPath)
+	changed, err = dir.ChangedObjectKeys("HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error from ChangedObjectKeys: %v", err)
+	}
+	expected := []tengo.ObjectKey{
+		{Type: tengo.ObjectTypeTable, Name: "baz"},
+		{Type: tengo.ObjectTypeTable, Name: "foo"},
+	}
+	if keys := sortedKeys(changed, ""); !slices.Equal(keys, expected) {
+		t.Errorf("Expected changed keys %v, instead found %v", expected, keys)
+	}
+	if keys := sortedKeys(changed, "analytics"); len(keys) != 0 {
+		t.Errorf("Expected no changed keys in logical schema analytics, instead found %v", keys)
+	}
+
+	// Nonexistent ref should be an error
+	if _, err := dir.ChangedObjectKeys("no-such-branch"); err == nil {
+		t.Error("Expected error from ChangedObjectKeys with nonexistent ref, but err was nil")
+	}
+
+	// Dir outside of a git repo should be an error
+	dir = getDir(t, "testdata/host/db")
+	dir.Path = t.TempDir()
+	if _, err := dir.ChangedObjectKeys("HEAD"); err == nil {
+		t.Error("Expected error from ChangedObjectKeys outside of a git repo, but err was nil")
+	}
+}
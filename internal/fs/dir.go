@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -29,10 +30,12 @@ type Dir struct {
 	SQLFiles              map[string]*SQLFile   // .sql files, keyed by absolute file path, usually with file name lowercased
 	UnparsedStatements    []*tengo.Statement    // statements with unknown type / not supported by this package
 	NamedSchemaStatements []*tengo.Statement    // statements with explicit schema names: USE command or CREATEs with schema name qualifier
-	LogicalSchemas        []*LogicalSchema      // for now, always 0 or 1 elements; 2+ in same dir to be supported in future
+	LogicalSchemas        []*LogicalSchema      // 2+ elements only if *.sql files reference schemas by name via USE commands or qualifiers
 	IgnorePatterns        []tengo.ObjectPattern // regexes for matching objects that should be ignored
+	Layout                Layout                // controls placement of new statements, and which subdirs contain *.sql files for this dir
 	ParseError            error                 // any fatal error found parsing dir's config or contents
 	repoBase              string                // absolute path of containing repo or Skeema-related tree; symlink destinations must stay within this prefix
+	optionFiles           []*mybase.File        // .skeema files used as sources in Config, from the rootmost parent dir's to this dir's
 	retainMapKeyCasing    bool                  // if true, map keys in SQLFiles retain original casing; used only when conflicting filenames found
 }
 
@@ -44,6 +47,9 @@ type Dir struct {
 // .skeema file is added first (and the current working dir's last), meaning
 // that options "cascade" down the fs hierarchy and can be overridden by child
 // directories.
+// *.sql files in subdirectories are only included if the subdirectory is one
+// dictated by the dir's layout option, and lacks its own .skeema file. Other
+// nested subdirectories are not searched for *.sql files.
 func ParseDir(dirPath string, globalConfig *mybase.Config) (*Dir, error) {
 	cleaned, err := filepath.Abs(filepath.Clean(dirPath))
 	if err != nil {
@@ -64,6 +70,7 @@ func ParseDir(dirPath string, globalConfig *mybase.Config) (*Dir, error) {
 	for _, optionFile := range parentFiles {
 		dir.Config.AddSource(optionFile)
 	}
+	dir.optionFiles = parentFiles
 
 	dir.parseContents()
 	return dir, dir.ParseError
@@ -106,6 +113,14 @@ func (dir *Dir) Subdirs() ([]*Dir, error) {
 	}
 	result := make([]*Dir, 0, len(entries))
 	for _, entry := range entries {
+		// Subdirectories used by dir's layout, or named after an object type as
+		// used by other layouts, are part of dir itself, unless they contain their
+		// own .skeema file
+		if entry.IsDir() && (dir.Layout.isLayoutSubdir(entry.Name()) || slices.Contains(layoutTypeDirNames(), entry.Name())) {
+			if has, _ := dir.HasFile(filepath.Join(entry.Name(), ".skeema")); !has {
+				continue
+			}
+		}
 		if entry.IsDir() && entry.Name()[0] != '.' {
 			if sub, _ := dir.Subdir(entry.Name()); sub != nil {
 				result = append(result, sub)
@@ -115,6 +130,32 @@ func (dir *Dir) Subdirs() ([]*Dir, error) {
 	return result, nil
 }
 
+// layoutSubdirs returns the relative paths of subdirectories whose *.sql files
+// are part of dir itself: those used by dir's layout, as well as any existing
+// subdirectories named after an object type as used by other layouts, such as
+// "tables". The latter ensures that changing the layout option does not cause
+// objects in the previous layout's subdirectories to be silently excluded from
+// dir, which would otherwise cause push to generate DROPs. In either case,
+// subdirectories with their own .skeema file are excluded.
+func (dir *Dir) layoutSubdirs() (result []string) {
+	candidates := dir.Layout.Subdirs()
+	for _, name := range layoutTypeDirNames() {
+		if slices.Contains(candidates, name) {
+			continue
+		}
+		if fi, err := os.Stat(filepath.Join(dir.Path, name)); err == nil && fi.IsDir() {
+			candidates = append(candidates, name)
+		}
+	}
+	for _, sub := range candidates {
+		if has, _ := dir.HasFile(filepath.Join(sub, ".skeema")); !has {
+			result = append(result, sub)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
 // Subdir returns a specific subdirectory of dir by name. If the named
 // subdirectory does not exist or is a non-directory, then a nil *Dir will be
 // returned alongside an error. In some other error conditions (such as a
@@ -126,6 +167,9 @@ func (dir *Dir) Subdir(name string) (*Dir, error) {
 		ShortName: filepath.Join(dir.ShortName, name),
 		Config:    dir.Config.Clone(),
 		repoBase:  dir.repoBase,
+		// Full slice expression, so that appending to sub's option files can never
+		// affect dir's
+		optionFiles: dir.optionFiles[:len(dir.optionFiles):len(dir.optionFiles)],
 	}
 	sub.parseContents()
 	if sub.ParseError != nil {
@@ -140,6 +184,40 @@ func (dir *Dir) Subdir(name string) (*Dir, error) {
 	return sub, sub.ParseError
 }
 
+// ForEnvironment returns a copy of dir, configured for the supplied environment
+// name instead of the one dir was originally parsed with. The copy's Config is
+// rebuilt from globalConfig and the same .skeema files as dir, using each
+// file's section for the environment. The *.sql files are not re-read;
+// instead, their already-parsed statements are re-evaluated using the new
+// configuration, which may affect ignore patterns, the patches file, or the
+// schema. The copy shares dir's SQLFiles, so it should not be used for any
+// operation that modifies files. If the new configuration is invalid, the
+// copy's ParseError will be set and also returned.
+func (dir *Dir) ForEnvironment(environment string, globalConfig *mybase.Config) (*Dir, error) {
+	envDir := *dir
+	envDir.Config = globalConfig.Clone()
+	envDir.Config.SetRuntimeOverride("environment", environment)
+	envDir.optionFiles = make([]*mybase.File, len(dir.optionFiles))
+	for n, optionFile := range dir.optionFiles {
+		// Shallow copy, so that selecting a section doesn't affect dir's file
+		fileCopy := *optionFile
+		_ = fileCopy.UseSection(environment) // we don't care if the section doesn't exist
+		envDir.optionFiles[n] = &fileCopy
+		envDir.Config.AddSource(&fileCopy)
+	}
+	if dir.OptionFile != nil {
+		envDir.OptionFile = envDir.optionFiles[len(envDir.optionFiles)-1]
+	}
+
+	var err error
+	if envDir.IgnorePatterns, err = util.IgnorePatterns(envDir.Config); err != nil {
+		envDir.ParseError = ConfigError{err}
+	} else {
+		envDir.ParseError = envDir.assembleStatements()
+	}
+	return &envDir, envDir.ParseError
+}
+
 // CreateOptionFile adds the supplied option file to dir. It is an error if dir
 // already has an option file.
 func (dir *Dir) CreateOptionFile(optionFile *mybase.File) (err error) {
@@ -154,6 +232,7 @@ func (dir *Dir) CreateOptionFile(optionFile *mybase.File) (err error) {
 		return err
 	}
 	dir.Config.AddSource(dir.OptionFile)
+	dir.optionFiles = append(dir.optionFiles, dir.OptionFile)
 
 	// If the option file configures a schema name, add an empty logical schema
 	// to the dir. (Normally this is handled by parseContents() if the dir's config
@@ -239,16 +318,16 @@ func (dir *Dir) Port() (int, bool) {
 // FileFor returns a SQLFile associated with the supplied keyer. If keyer is a
 // *tengo.Statement with non-empty File field, that path will be used as-is.
 // Otherwise, FileFor returns the default location for the supplied keyer based
-// on its type and name. In either case, if no known SQLFile exists at that
-// location yet, FileFor will instantiate a new SQLFile value for it, but no
-// underlying filesystem file is created/written by this method.
+// on its type, its name, and the dir's Layout. In either case, if no known
+// SQLFile exists at that location yet, FileFor will instantiate a new SQLFile
+// value for it, but no underlying filesystem file is created/written by this
+// method.
 func (dir *Dir) FileFor(keyer tengo.ObjectKeyer) *SQLFile {
 	var dirPath, base string
 	if stmt, ok := keyer.(*tengo.Statement); ok && stmt.File != "" {
 		dirPath, base = filepath.Split(stmt.File)
 	} else {
-		dirPath = dir.Path
-		base = FileNameForObject(keyer.ObjectKey().Name)
+		dirPath, base = filepath.Split(filepath.Join(dir.Path, dir.Layout.RelPath(keyer.ObjectKey())))
 	}
 
 	// Build the real file path, as well as a version of the path usable as a
@@ -288,6 +367,18 @@ func (dir *Dir) DirtyFiles() (result []*SQLFile) {
 	return
 }
 
+// WrittenFiles returns the paths of SQLFiles that have been successfully
+// written or deleted via SQLFile.Write since dir was parsed, sorted by path.
+func (dir *Dir) WrittenFiles() (result []string) {
+	for _, sf := range dir.SQLFiles {
+		if sf.written {
+			result = append(result, sf.FilePath)
+		}
+	}
+	sort.Strings(result)
+	return
+}
+
 // Instances returns 0 or more tengo.Instance pointers, based on the
 // directory's configuration. The Instances will NOT be checked for
 // connectivity. However, if the configuration is invalid (for example, illegal
@@ -885,6 +976,7 @@ func (dir *Dir) parseContents() {
 			return
 		}
 		dir.Config.AddSource(dir.OptionFile)
+		dir.optionFiles = append(dir.optionFiles, dir.OptionFile)
 	}
 
 	var err error
@@ -892,12 +984,30 @@ func (dir *Dir) parseContents() {
 		dir.ParseError = ConfigError{err}
 		return
 	}
+	if dir.Layout, err = LayoutForConfig(dir.Config); err != nil {
+		dir.ParseError = ConfigError{err}
+		return
+	}
 
-	// See what *.sql files are here
+	// See what *.sql files are here, including in any subdirs used by the layout
+	// which don't have their own .skeema file
 	var sqlFileNames []string
 	if sqlFileNames, dir.ParseError = sqlFiles(dir.Path, dir.repoBase); dir.ParseError != nil {
 		return
 	}
+	for _, sub := range dir.layoutSubdirs() {
+		subFileNames, err := sqlFiles(filepath.Join(dir.Path, sub), dir.repoBase)
+		if err != nil && !os.IsNotExist(err) {
+			dir.ParseError = err
+			return
+		}
+		if len(subFileNames) > 0 && !slices.Contains(dir.Layout.Subdirs(), sub) {
+			log.Warnf("Directory %s has *.sql files in subdirectory %s, which is not used by the current layout option. These files are still treated as part of the directory, but new statements are placed according to the layout. To move existing statements to match the layout, run `skeema pull` with the --layout option.", dir, sub)
+		}
+		for _, fileName := range subFileNames {
+			sqlFileNames = append(sqlFileNames, filepath.Join(sub, fileName))
+		}
+	}
 
 	// See if there are any case-insensitive file name conflicts, since that
 	// affects how we key the file names. We seek to avoid introducing any new
@@ -906,7 +1016,7 @@ func (dir *Dir) parseContents() {
 	// ALREADY has a situation where "Foo.sql" and "foo.sql" both exist.
 	dir.SQLFiles = make(map[string]*SQLFile, len(sqlFileNames))
 	for _, fileName := range sqlFileNames {
-		normalizedPath := filepath.Join(dir.Path, strings.ToLower(fileName))
+		normalizedPath := filepath.Join(dir.Path, filepath.Dir(fileName), strings.ToLower(filepath.Base(fileName)))
 		if _, already := dir.SQLFiles[normalizedPath]; already {
 			dir.retainMapKeyCasing = true
 			dir.SQLFiles = make(map[string]*SQLFile, len(sqlFileNames))
@@ -916,7 +1026,6 @@ func (dir *Dir) parseContents() {
 	}
 
 	// Tokenize, parse, and track all *.sql files
-	logicalSchemasByName := make(map[string]*LogicalSchema)
 	for _, fileName := range sqlFileNames {
 		sf := &SQLFile{
 			FilePath: filepath.Join(dir.Path, fileName),
@@ -931,6 +1040,52 @@ func (dir *Dir) parseContents() {
 			// quote for example.
 			return
 		}
+		var filePathKey string
+		if dir.retainMapKeyCasing {
+			filePathKey = sf.FilePath
+		} else {
+			filePathKey = filepath.Join(dir.Path, filepath.Dir(fileName), strings.ToLower(filepath.Base(fileName)))
+		}
+		dir.SQLFiles[filePathKey] = sf
+	}
+	dir.ParseError = dir.assembleStatements()
+}
+
+// assembleStatements places the statements of dir's already-parsed *.sql files
+// into logical schemas, based on dir's current configuration. This populates
+// dir.LogicalSchemas, dir.UnparsedStatements, and dir.NamedSchemaStatements,
+// and may append to dir.IgnorePatterns.
+func (dir *Dir) assembleStatements() error {
+	dir.LogicalSchemas = nil
+	dir.UnparsedStatements = nil
+	dir.NamedSchemaStatements = nil
+
+	// Imperative DDL, such as ALTER TABLE, is only applied if it is located in
+	// the file configured by the patches-file option
+	var patchesPath string
+	if patchesFile := dir.Config.Get("patches-file"); patchesFile != "" {
+		patchesPath = filepath.Join(dir.Path, patchesFile)
+	}
+
+	// Process files in the same order they were read: files directly in dir
+	// first, followed by files in subdirs used by the layout
+	files := make([]*SQLFile, 0, len(dir.SQLFiles))
+	for _, sf := range dir.SQLFiles {
+		files = append(files, sf)
+	}
+	slices.SortFunc(files, func(a, b *SQLFile) int {
+		aInSubdir, bInSubdir := filepath.Dir(a.FilePath) != dir.Path, filepath.Dir(b.FilePath) != dir.Path
+		if aInSubdir != bInSubdir {
+			if aInSubdir {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.FilePath, b.FilePath)
+	})
+
+	logicalSchemasByName := make(map[string]*LogicalSchema)
+	for _, sf := range files {
 		for _, stmt := range sf.Statements {
 			// Statements that are ignored due to ignore-table, ignore-proc, etc are
 			// simply not placed into a LogicalSchema, so that all other logic won't
@@ -938,13 +1093,23 @@ func (dir *Dir) parseContents() {
 			if dir.ShouldIgnore(stmt) {
 				continue
 			}
+			if stmt.Type == tengo.StatementTypeAlter && sf.FilePath != patchesPath {
+				// Imperative DDL outside of the patches file is ignored, in the same way as
+				// any other unsupported statement type
+				dir.UnparsedStatements = append(dir.UnparsedStatements, stmt)
+				continue
+			} else if stmt.Type == tengo.StatementTypeInsert && !IsDataFile(sf.FilePath) {
+				// Likewise, INSERTs only declare managed table data if they are located in
+				// a *.data.sql file, so that pre-existing INSERTs elsewhere remain inert
+				dir.UnparsedStatements = append(dir.UnparsedStatements, stmt)
+				continue
+			}
 
 			if _, ok := logicalSchemasByName[stmt.Schema()]; !ok {
 				logicalSchemasByName[stmt.Schema()] = NewLogicalSchema()
 			}
-			dir.ParseError = logicalSchemasByName[stmt.Schema()].AddStatement(stmt)
-			if dir.ParseError != nil {
-				return
+			if err := logicalSchemasByName[stmt.Schema()].AddStatement(stmt); err != nil {
+				return err
 			}
 			if stmt.Type == tengo.StatementTypeUnknown {
 				// Statements which could not be parsed, meaning of an unsupported statement
@@ -969,13 +1134,6 @@ func (dir *Dir) parseContents() {
 				dir.NamedSchemaStatements = append(dir.NamedSchemaStatements, stmt)
 			}
 		}
-		var filePathKey string
-		if dir.retainMapKeyCasing {
-			filePathKey = sf.FilePath
-		} else {
-			filePathKey = filepath.Join(dir.Path, strings.ToLower(fileName))
-		}
-		dir.SQLFiles[filePathKey] = sf
 	}
 
 	// Prune any logical schema which didn't have any relevant statements (e.g.
@@ -1006,6 +1164,7 @@ func (dir *Dir) parseContents() {
 		ls.Name = name
 		dir.LogicalSchemas = append(dir.LogicalSchemas, ls)
 	}
+	return nil
 }
 
 // ParentOptionFiles returns a slice of *mybase.File, corresponding to the
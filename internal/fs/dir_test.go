@@ -297,6 +297,26 @@ func TestParseDirUnparsedStatements(t *testing.T) {
 	}
 }
 
+func TestParseDirManagedData(t *testing.T) {
+	// INSERTs only declare managed data if located in a *.data.sql file. Anywhere
+	// else, they are treated as unsupported statements.
+	dirPath := t.TempDir()
+	WriteTestFile(t, filepath.Join(dirPath, "roles.sql"), "CREATE TABLE roles (id int NOT NULL, PRIMARY KEY (id));\nINSERT INTO roles VALUES (1);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "roles.data.sql"), "INSERT INTO roles VALUES (2);\nINSERT INTO roles VALUES (3);\n")
+	dir, err := ParseDir(dirPath, getValidConfig(t))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir(): %v", err)
+	} else if dir.ParseError != nil {
+		t.Fatalf("Expected nil ParseError, instead found %v", dir.ParseError)
+	}
+	if inserts := dir.LogicalSchemas[0].Inserts; len(inserts) != 2 || !IsDataFile(inserts[0].File) {
+		t.Errorf("Expected 2 managed data INSERTs from roles.data.sql, instead found %+v", inserts)
+	}
+	if len(dir.UnparsedStatements) != 1 || dir.UnparsedStatements[0].File != filepath.Join(dirPath, "roles.sql") {
+		t.Errorf("Expected INSERT in roles.sql to be tracked as unparsed, instead found %+v", dir.UnparsedStatements)
+	}
+}
+
 func TestParseDirRedundantDelimiter(t *testing.T) {
 	// This dir contains two special cases of DELIMITER commands:
 	// * Setting a delimiter that is already the current delimiter, e.g. from ; to ;
@@ -492,6 +512,65 @@ func TestDirSubdirs(t *testing.T) {
 	}
 }
 
+func TestDirForEnvironment(t *testing.T) {
+	// Commands which use ForEnvironment have environment as an option, not an
+	// arg, since an arg would take precedence over the runtime override
+	cmd := mybase.NewCommand("fstest", "", "", nil)
+	util.AddGlobalOptions(cmd)
+	cmd.AddOption(mybase.StringOption("environment", 0, "production", "Environment"))
+	cfg := mybase.ParseFakeCLI(t, cmd, "fstest")
+	dir, err := ParseDir("testdata/environments", cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing dir: %v", err)
+	}
+	if len(dir.LogicalSchemas) != 1 || len(dir.LogicalSchemas[0].Creates) != 2 {
+		t.Fatalf("Unexpected logical schemas in %s: %+v", dir, dir.LogicalSchemas)
+	}
+
+	// staging section's ignore-table should exclude _bar, without affecting the
+	// original dir
+	staging, err := dir.ForEnvironment("staging", cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error from ForEnvironment: %v", err)
+	}
+	if env := staging.Config.Get("environment"); env != "staging" {
+		t.Errorf("Expected environment to be staging, instead found %q", env)
+	}
+	if host := staging.Config.Get("host"); host != "staging.example.com" {
+		t.Errorf("Expected host from staging section, instead found %q", host)
+	}
+	if len(staging.LogicalSchemas) != 1 || len(staging.LogicalSchemas[0].Creates) != 1 {
+		t.Errorf("Expected ignore-table to leave 1 CREATE in staging, instead found %+v", staging.LogicalSchemas)
+	} else if staging.LogicalSchemas[0].CharSet != "latin1" {
+		t.Errorf("Expected logical schema's CharSet to be latin1, instead found %q", staging.LogicalSchemas[0].CharSet)
+	}
+	if host := dir.Config.Get("host"); host != "prod.example.com" || len(dir.LogicalSchemas[0].Creates) != 2 {
+		t.Errorf("ForEnvironment unexpectedly affected original dir: host=%q, creates=%d", host, len(dir.LogicalSchemas[0].Creates))
+	}
+
+	// A subdir which only has a schema in one environment
+	subs, err := dir.Subdirs()
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("Unexpected return from Subdirs(): %+v, %v", subs, err)
+	}
+	if subs[0].HasSchema() {
+		t.Errorf("Expected %s to lack a schema in production", subs[0])
+	}
+	if staging, err = subs[0].ForEnvironment("staging", cfg); err != nil {
+		t.Errorf("Unexpected error from ForEnvironment: %v", err)
+	} else if !staging.HasSchema() || staging.Config.Get("schema") != "staged" || staging.Config.Get("host") != "staging.example.com" {
+		t.Errorf("Expected %s to have schema and host from staging sections, instead found schema=%q host=%q", staging, staging.Config.Get("schema"), staging.Config.Get("host"))
+	}
+
+	// Invalid configuration in the environment's section should return a
+	// ConfigError
+	_, err = dir.ForEnvironment("broken", cfg)
+	var ce ConfigError
+	if !errors.As(err, &ce) {
+		t.Errorf("Expected ForEnvironment to return a ConfigError, instead found %v", err)
+	}
+}
+
 func TestDirFileFor(t *testing.T) {
 	dir := getDir(t, "testdata/host/db")
 
@@ -561,6 +640,39 @@ func TestDirDirtyFiles(t *testing.T) {
 	}
 }
 
+func TestDirWrittenFiles(t *testing.T) {
+	dirPath := t.TempDir()
+	WriteTestFile(t, filepath.Join(dirPath, "foo.sql"), "CREATE TABLE foo (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "bar.sql"), "CREATE TABLE bar (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "baz.sql"), "CREATE TABLE baz (id int);\n")
+	dir := getDir(t, dirPath)
+	if written := dir.WrittenFiles(); len(written) > 0 {
+		t.Errorf("Expected no written files initially, instead found %v", written)
+	}
+
+	// Marking a file as dirty does not count as writing it, but rewriting or
+	// deleting a file does
+	for _, sf := range dir.SQLFiles {
+		switch sf.FileName() {
+		case "foo.sql":
+			sf.Dirty = true
+		case "bar.sql":
+			if _, err := sf.Write(); err != nil {
+				t.Fatalf("Unexpected error from Write: %v", err)
+			}
+		case "baz.sql":
+			sf.Statements = nil
+			if _, err := sf.Write(); err != nil {
+				t.Fatalf("Unexpected error from Write: %v", err)
+			}
+		}
+	}
+	expected := []string{filepath.Join(dirPath, "bar.sql"), filepath.Join(dirPath, "baz.sql")}
+	if written := dir.WrittenFiles(); !reflect.DeepEqual(written, expected) {
+		t.Errorf("Expected written files %v, instead found %v", expected, written)
+	}
+}
+
 func TestDirInstances(t *testing.T) {
 	assertInstances := func(optionValues map[string]string, expectError bool, expectedInstances ...string) []*tengo.Instance {
 		cmd := mybase.NewCommand("test", "1.0", "this is for testing", nil)
@@ -0,0 +1,135 @@
+package fs
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// DefaultLayoutTemplate is the default value of the layout option, which places
+// each object in its own file, named after the object, directly in the schema
+// directory.
+const DefaultLayoutTemplate = "{name}.sql"
+
+// Layout controls where new CREATE statements are placed within a directory's
+// *.sql files, and which subdirectories of a directory contain *.sql files
+// that are part of the directory itself.
+type Layout struct {
+	Template      string // relative path template, using forward slashes; see LayoutForConfig
+	GroupRoutines bool   // if true, all stored procedures and functions are placed in a single file
+}
+
+// layoutObjectTypes lists the object types which can be written to *.sql
+// files, along with the plural form of each type used in the {types}
+// placeholder.
+var layoutObjectTypes = []struct {
+	objType tengo.ObjectType
+	plural  string
+}{
+	{tengo.ObjectTypeTable, "tables"},
+	{tengo.ObjectTypeProc, "procedures"},
+	{tengo.ObjectTypeFunc, "functions"},
+}
+
+// LayoutForConfig returns the Layout configured by the layout and
+// group-routines options in cfg. The layout option is a relative path template
+// which may contain these placeholders:
+//
+//	{name}  the object name, with special characters removed
+//	{type}  the object type: table, procedure, or function
+//	{types} the plural object type: tables, procedures, or functions
+//
+// With group-routines, all routines use a {name} of "routines", {type} of
+// "routine", and {types} of "routines".
+// An error is returned if the template does not end in ".sql", refers to an
+// absolute path or parent directory, or uses {name} in a directory component.
+func LayoutForConfig(cfg *mybase.Config) (Layout, error) {
+	layout := Layout{
+		Template:      filepath.ToSlash(cfg.Get("layout")),
+		GroupRoutines: cfg.GetBool("group-routines"),
+	}
+	if layout.Template == "" {
+		layout.Template = DefaultLayoutTemplate
+	}
+	if !strings.HasSuffix(layout.Template, ".sql") || strings.HasSuffix(layout.Template, "/.sql") {
+		return layout, errors.New("option layout must be a file path ending in .sql")
+	} else if path.IsAbs(layout.Template) || filepath.IsAbs(layout.Template) || filepath.VolumeName(layout.Template) != "" {
+		return layout, errors.New("option layout must be a relative path")
+	}
+	dirPart, _ := path.Split(layout.Template)
+	for _, elem := range strings.Split(dirPart, "/") {
+		if elem == ".." || (elem != "" && elem != "." && elem[0] == '.') {
+			return layout, errors.New("option layout may not refer to parent or hidden directories")
+		}
+	}
+	if strings.Contains(dirPart, "{name}") {
+		return layout, errors.New("option layout may only use {name} in the file name, not in a directory name")
+	}
+	return layout, nil
+}
+
+// RelPath returns the path, relative to the directory, of the file where a new
+// CREATE statement for key should be placed.
+func (layout Layout) RelPath(key tengo.ObjectKey) string {
+	template := layout.Template
+	if template == "" {
+		template = DefaultLayoutTemplate
+	}
+	name, typeName, plural := key.Name, string(key.Type), string(key.Type)+"s"
+	for _, lot := range layoutObjectTypes {
+		if lot.objType == key.Type {
+			plural = lot.plural
+		}
+	}
+	if layout.GroupRoutines && (key.Type == tengo.ObjectTypeProc || key.Type == tengo.ObjectTypeFunc) {
+		name, typeName, plural = "routines", "routine", "routines"
+	}
+	replacer := strings.NewReplacer(
+		"{name}", strings.TrimSuffix(FileNameForObject(name), ".sql"),
+		"{type}", typeName,
+		"{types}", plural,
+	)
+	return filepath.FromSlash(path.Clean(replacer.Replace(template)))
+}
+
+// Subdirs returns the relative paths of subdirectories which may contain
+// *.sql files under this layout, in sorted order. The result is empty for
+// layouts which only place files directly in the directory.
+func (layout Layout) Subdirs() []string {
+	var result []string
+	for _, lot := range layoutObjectTypes {
+		dirPart, _ := filepath.Split(layout.RelPath(tengo.ObjectKey{Type: lot.objType}))
+		if dirPart = filepath.Clean(dirPart); dirPart != "." && !slices.Contains(result, dirPart) {
+			result = append(result, dirPart)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// layoutTypeDirNames returns the subdirectory names which the {type} and
+// {types} placeholders may expand to, regardless of the configured layout.
+func layoutTypeDirNames() []string {
+	names := []string{"routine", "routines"}
+	for _, lot := range layoutObjectTypes {
+		names = append(names, string(lot.objType), lot.plural)
+	}
+	return names
+}
+
+// isLayoutSubdir returns true if name is the first path component of any of
+// the layout's subdirectories.
+func (layout Layout) isLayoutSubdir(name string) bool {
+	for _, sub := range layout.Subdirs() {
+		first, _, _ := strings.Cut(filepath.ToSlash(sub), "/")
+		if first == name {
+			return true
+		}
+	}
+	return false
+}
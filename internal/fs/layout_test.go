@@ -0,0 +1,162 @@
+package fs
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestLayoutForConfig(t *testing.T) {
+	validCases := map[string]Layout{
+		"":                                       {Template: "{name}.sql"},
+		"--layout={types}/{name}.sql":            {Template: "{types}/{name}.sql"},
+		"--layout=schema/{type}-{name}.sql":      {Template: "schema/{type}-{name}.sql"},
+		"--layout=all.sql --group-routines":      {Template: "all.sql", GroupRoutines: true},
+		"--layout=./{types}/{name}.sql":          {Template: "./{types}/{name}.sql"},
+		"--group-routines --layout=x/{name}.sql": {Template: "x/{name}.sql", GroupRoutines: true},
+	}
+	for cliOptions, expected := range validCases {
+		layout, err := LayoutForConfig(getValidConfigWithCLI(t, cliOptions))
+		if err != nil {
+			t.Errorf("Unexpected error from LayoutForConfig with %q: %v", cliOptions, err)
+		} else if layout != expected {
+			t.Errorf("Unexpected result from LayoutForConfig with %q: expected %+v, found %+v", cliOptions, expected, layout)
+		}
+	}
+
+	invalidCases := []string{
+		"--layout={name}.txt",
+		"--layout=tables/.sql",
+		"--layout=/tmp/{name}.sql",
+		"--layout=../{name}.sql",
+		"--layout=.hidden/{name}.sql",
+		"--layout={name}/{type}.sql",
+	}
+	for _, cliOptions := range invalidCases {
+		if _, err := LayoutForConfig(getValidConfigWithCLI(t, cliOptions)); err == nil {
+			t.Errorf("Expected error from LayoutForConfig with %q, but err was nil", cliOptions)
+		}
+	}
+}
+
+func TestLayoutRelPath(t *testing.T) {
+	table := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "my-table"}
+	proc := tengo.ObjectKey{Type: tengo.ObjectTypeProc, Name: "myproc"}
+	function := tengo.ObjectKey{Type: tengo.ObjectTypeFunc, Name: "myfunc"}
+	cases := []struct {
+		layout   Layout
+		expected []string // for table, proc, function respectively
+	}{
+		{Layout{}, []string{"mytable.sql", "myproc.sql", "myfunc.sql"}},
+		{Layout{Template: "{types}/{name}.sql"}, []string{"tables/mytable.sql", "procedures/myproc.sql", "functions/myfunc.sql"}},
+		{Layout{Template: "{types}/{name}.sql", GroupRoutines: true}, []string{"tables/mytable.sql", "routines/routines.sql", "routines/routines.sql"}},
+		{Layout{Template: "{name}.sql", GroupRoutines: true}, []string{"mytable.sql", "routines.sql", "routines.sql"}},
+		{Layout{Template: "ddl/{type}.{name}.sql"}, []string{"ddl/table.mytable.sql", "ddl/procedure.myproc.sql", "ddl/function.myfunc.sql"}},
+	}
+	for _, c := range cases {
+		for n, key := range []tengo.ObjectKey{table, proc, function} {
+			if actual := c.layout.RelPath(key); actual != filepath.FromSlash(c.expected[n]) {
+				t.Errorf("Unexpected result from %+v RelPath(%s): expected %q, found %q", c.layout, key, c.expected[n], actual)
+			}
+		}
+	}
+}
+
+func TestLayoutSubdirs(t *testing.T) {
+	cases := []struct {
+		layout   Layout
+		expected []string
+	}{
+		{Layout{}, nil},
+		{Layout{Template: "{types}/{name}.sql"}, []string{"functions", "procedures", "tables"}},
+		{Layout{Template: "{types}/{name}.sql", GroupRoutines: true}, []string{"routines", "tables"}},
+		{Layout{Template: "ddl/{type}.{name}.sql"}, []string{"ddl"}},
+		{Layout{Template: "ddl/{types}/{name}.sql"}, []string{filepath.FromSlash("ddl/functions"), filepath.FromSlash("ddl/procedures"), filepath.FromSlash("ddl/tables")}},
+	}
+	for _, c := range cases {
+		if actual := c.layout.Subdirs(); !slices.Equal(actual, c.expected) {
+			t.Errorf("Unexpected result from %+v Subdirs(): expected %v, found %v", c.layout, c.expected, actual)
+		}
+	}
+}
+
+func TestParseDirLayout(t *testing.T) {
+	dirPath := t.TempDir()
+	WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\nlayout={types}/{name}.sql\n")
+	WriteTestFile(t, filepath.Join(dirPath, "legacy.sql"), "CREATE TABLE legacy (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "tables", "Users.sql"), "CREATE TABLE users (id int);\n")
+	WriteTestFile(t, filepath.Join(dirPath, "procedures", "p.sql"), "CREATE PROCEDURE p() SELECT 1;\n")
+	WriteTestFile(t, filepath.Join(dirPath, "functions", ".skeema"), "schema=bar\n")
+	WriteTestFile(t, filepath.Join(dirPath, "functions", "f.sql"), "CREATE FUNCTION f() RETURNS int RETURN 1;\n")
+	WriteTestFile(t, filepath.Join(dirPath, "other", "t2.sql"), "CREATE TABLE t2 (id int);\n")
+
+	// Files in the layout's subdirs should be part of the dir, except for subdirs
+	// that have their own .skeema file
+	dir := getDir(t, dirPath)
+	if len(dir.LogicalSchemas) != 1 {
+		t.Fatalf("Expected 1 logical schema, instead found %d", len(dir.LogicalSchemas))
+	}
+	creates := dir.LogicalSchemas[0].Creates
+	if len(creates) != 3 || creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "users"}] == nil || creates[tengo.ObjectKey{Type: tengo.ObjectTypeProc, Name: "p"}] == nil {
+		t.Errorf("Unexpected contents of logical schema: %+v", creates)
+	}
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		t.Fatalf("Unexpected error from Subdirs(): %v", err)
+	}
+	var subdirNames []string
+	for _, sub := range subdirs {
+		subdirNames = append(subdirNames, sub.BaseName())
+	}
+	slices.Sort(subdirNames)
+	if !slices.Equal(subdirNames, []string{"functions", "other"}) {
+		t.Errorf("Unexpected result from Subdirs(): %v", subdirNames)
+	}
+
+	// Existing statements stay in their current file, but new objects follow
+	// the layout
+	usersKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "users"}
+	if sf := dir.FileFor(creates[usersKey]); sf.FilePath != filepath.Join(dirPath, "tables", "Users.sql") {
+		t.Errorf("Unexpected result from FileFor(%s): %s", usersKey, sf.FilePath)
+	}
+	if sf := dir.FileFor(creates[usersKey]); sf != dir.FileFor(usersKey) {
+		t.Errorf("Expected FileFor to return the same SQLFile regardless of case, but it did not")
+	}
+	legacyKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "legacy"}
+	if sf := dir.FileFor(legacyKey); sf.FilePath != filepath.Join(dirPath, "tables", "legacy.sql") {
+		t.Errorf("Unexpected result from FileFor(%s): %s", legacyKey, sf.FilePath)
+	}
+
+	// Writing a file in a new subdir should create the subdir
+	funcKey := tengo.ObjectKey{Type: tengo.ObjectTypeFunc, Name: "newfunc"}
+	dir.Layout.Template = "ddl/{types}/{name}.sql"
+	sf := dir.FileFor(funcKey)
+	sf.AddStatement(tengo.ParseStatementInString("CREATE FUNCTION newfunc() RETURNS int RETURN 2"))
+	if _, err := sf.Write(); err != nil {
+		t.Fatalf("Unexpected error from Write(): %v", err)
+	}
+	if contents := ReadTestFile(t, filepath.Join(dirPath, "ddl", "functions", "newfunc.sql")); contents != "CREATE FUNCTION newfunc() RETURNS int RETURN 2;\n" {
+		t.Errorf("Unexpected file contents: %q", contents)
+	}
+
+	// After changing the layout option, files in subdirs named after object
+	// types should still be part of the dir, rather than silently disappearing
+	WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "schema=foo\n")
+	dir = getDir(t, dirPath)
+	if creates := dir.LogicalSchemas[0].Creates; len(creates) != 3 || creates[usersKey] == nil {
+		t.Errorf("Unexpected contents of logical schema after changing layout: %+v", creates)
+	}
+	if subdirs, err = dir.Subdirs(); err != nil {
+		t.Fatalf("Unexpected error from Subdirs(): %v", err)
+	}
+	subdirNames = nil
+	for _, sub := range subdirs {
+		subdirNames = append(subdirNames, sub.BaseName())
+	}
+	slices.Sort(subdirNames)
+	if !slices.Equal(subdirNames, []string{"ddl", "functions", "other"}) {
+		t.Errorf("Unexpected result from Subdirs() after changing layout: %v", subdirNames)
+	}
+}
@@ -18,6 +18,7 @@ type LogicalSchema struct {
 	Collation string
 	Creates   map[tengo.ObjectKey]*tengo.Statement
 	Alters    []*tengo.Statement // Alterations that are run after the Creates
+	Inserts   []*tengo.Statement // Managed table data, populated after the Alters
 }
 
 // NewLogicalSchema returns a pointer to an empty, nameless LogicalSchema. Any
@@ -49,13 +50,29 @@ func (logicalSchema *LogicalSchema) AddStatement(stmt *tengo.Statement) error {
 		logicalSchema.Creates[key] = stmt
 	case tengo.StatementTypeAlter:
 		logicalSchema.Alters = append(logicalSchema.Alters, stmt)
+	case tengo.StatementTypeInsert:
+		logicalSchema.Inserts = append(logicalSchema.Inserts, stmt)
 	}
 	return nil
 }
 
+// InsertTableNames returns the names of tables which have managed data, in
+// order of first appearance among the INSERT statements.
+func (logicalSchema *LogicalSchema) InsertTableNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, stmt := range logicalSchema.Inserts {
+		if !seen[stmt.ObjectName] {
+			names = append(names, stmt.ObjectName)
+			seen[stmt.ObjectName] = true
+		}
+	}
+	return names
+}
+
 // Empty returns true if the LogicalSchema contains no statements.
 func (logicalSchema *LogicalSchema) Empty() bool {
-	return len(logicalSchema.Creates)+len(logicalSchema.Alters) == 0
+	return len(logicalSchema.Creates)+len(logicalSchema.Alters)+len(logicalSchema.Inserts) == 0
 }
 
 // LowerCaseNames adjusts logicalSchema in-place such that its object names are
@@ -85,6 +102,9 @@ func (logicalSchema *LogicalSchema) LowerCaseNames(mode tengo.NameCaseMode) erro
 			newCreates[k] = stmt
 		}
 		logicalSchema.Creates = newCreates
+		for _, stmt := range logicalSchema.Inserts {
+			stmt.ObjectName = strings.ToLower(stmt.ObjectName)
+		}
 
 	case tengo.NameCaseInsensitive: // lower_case_table_names=2
 		// Only view names are forced to lowercase in this mode, but Community Edition
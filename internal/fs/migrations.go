@@ -0,0 +1,189 @@
+package fs
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// MigrationFile represents an "up" migration file of an external schema
+// migration tool, such as golang-migrate, Flyway, or goose.
+type MigrationFile struct {
+	FilePath    string
+	Version     []uint64 // numeric components of version; nil for Flyway repeatable migrations
+	Description string
+}
+
+// Regular expressions for matching migration file names. Each captures the
+// version (if any) followed by the description.
+var (
+	reGolangMigrateUp  = regexp.MustCompile(`^(\d+)_(.*)\.up\.sql$`)
+	reGolangMigrateDn  = regexp.MustCompile(`^\d+_.*\.down\.sql$`)
+	reFlywayVersioned  = regexp.MustCompile(`^V(\d+(?:[._]\d+)*)__(.*)\.sql$`)
+	reFlywayRepeatable = regexp.MustCompile(`^R__(.*)\.sql$`)
+	reFlywayUndo       = regexp.MustCompile(`^U\d+(?:[._]\d+)*__.*\.sql$`)
+	reNumberedSQL      = regexp.MustCompile(`^(\d+)[_-]?(.*)\.sql$`)
+)
+
+// MigrationFiles returns the migration files in dirPath, in the order that
+// they should be applied. Files are recognized using the naming conventions of
+// golang-migrate ("1_desc.up.sql"), Flyway ("V1__desc.sql", "R__desc.sql"),
+// and goose or plain numbered files ("001_desc.sql"). Down or undo migrations
+// are ignored, as are files not ending in .sql. Flyway repeatable migrations
+// are applied after all versioned migrations, ordered by description.
+// An error is returned if a *.sql file has no recognizable version, if
+// multiple files have the same version, or if no migration files are found.
+func MigrationFiles(dirPath string) ([]*MigrationFile, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	var result []*MigrationFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasSuffix(name, ".sql") || reGolangMigrateDn.MatchString(name) || reFlywayUndo.MatchString(name) {
+			continue
+		}
+		mf := &MigrationFile{FilePath: filepath.Join(dirPath, name)}
+		var version string
+		if matches := reGolangMigrateUp.FindStringSubmatch(name); matches != nil {
+			version, mf.Description = matches[1], matches[2]
+		} else if matches := reFlywayVersioned.FindStringSubmatch(name); matches != nil {
+			version, mf.Description = matches[1], matches[2]
+		} else if matches := reFlywayRepeatable.FindStringSubmatch(name); matches != nil {
+			mf.Description = matches[1]
+		} else if matches := reNumberedSQL.FindStringSubmatch(name); matches != nil {
+			version, mf.Description = matches[1], matches[2]
+		} else {
+			return nil, fmt.Errorf("Cannot determine version of migration file %s", mf.FilePath)
+		}
+		if version != "" {
+			for _, part := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' }) {
+				n, err := strconv.ParseUint(part, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Cannot parse version of migration file %s: %w", mf.FilePath, err)
+				}
+				mf.Version = append(mf.Version, n)
+			}
+		}
+		result = append(result, mf)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("No *.sql migration files found in %s", dirPath)
+	}
+
+	slices.SortStableFunc(result, func(a, b *MigrationFile) int {
+		if (a.Version == nil) != (b.Version == nil) {
+			if a.Version == nil {
+				return 1 // repeatable migrations go last
+			}
+			return -1
+		} else if a.Version == nil {
+			return cmp.Compare(a.Description, b.Description)
+		}
+		return compareMigrationVersions(a.Version, b.Version)
+	})
+	for n := 1; n < len(result); n++ {
+		if result[n].Version != nil && compareMigrationVersions(result[n-1].Version, result[n].Version) == 0 {
+			return nil, fmt.Errorf("Migration files %s and %s have the same version", result[n-1].FilePath, result[n].FilePath)
+		}
+	}
+	return result, nil
+}
+
+// compareMigrationVersions compares versions numerically, one component at a
+// time. Missing trailing components are treated as zero, so that versions 1
+// and 1.0 are considered equal.
+func compareMigrationVersions(a, b []uint64) int {
+	for n := range max(len(a), len(b)) {
+		var aPart, bPart uint64
+		if n < len(a) {
+			aPart = a[n]
+		}
+		if n < len(b) {
+			bPart = b[n]
+		}
+		if c := cmp.Compare(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// Statements returns the SQL statements in the migration file, excluding
+// whitespace, comments, and DELIMITER commands. For files using goose
+// annotations, only statements in the "Up" section are returned. An error is
+// returned if the file contains a USE command, since migrations are replayed in
+// a single workspace schema.
+func (mf *MigrationFile) Statements() ([]*tengo.Statement, error) {
+	contents, err := os.ReadFile(mf.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := tengo.ParseStatements(strings.NewReader(gooseUpSection(string(contents))), mf.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*tengo.Statement, 0, len(statements))
+	for _, stmt := range statements {
+		if stmt.Type == tengo.StatementTypeCommand {
+			if keyword, _, _ := strings.Cut(strings.TrimSpace(stmt.Text), " "); strings.EqualFold(keyword, "use") {
+				return nil, fmt.Errorf("%s: USE commands are not supported in migration files", stmt.Location())
+			}
+		} else if stmt.Type != tengo.StatementTypeNoop {
+			result = append(result, stmt)
+		}
+	}
+	return result, nil
+}
+
+// gooseUpSection returns contents with everything outside of the goose "Up"
+// section blanked out. Statement blocks delimited by goose's StatementBegin and
+// StatementEnd annotations are converted to use the DELIMITER command, so that
+// they can be parsed like any other *.sql file. Line numbers are preserved. If
+// contents does not use goose annotations, it is returned as-is.
+func gooseUpSection(contents string) string {
+	if !strings.Contains(contents, "-- +goose Up") {
+		return contents
+	}
+	lines := strings.Split(contents, "\n")
+	var inUp, inBlock bool
+	lastBlockLine := -1
+	for n, line := range lines {
+		annotation, isAnnotation := strings.CutPrefix(strings.TrimSpace(line), "-- +goose ")
+		if isAnnotation {
+			lines[n] = ""
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				inUp = true
+			case "Down":
+				inUp = false
+			case "StatementBegin":
+				if inUp {
+					lines[n] = "DELIMITER //"
+					inBlock, lastBlockLine = true, -1
+				}
+			case "StatementEnd":
+				if inUp && inBlock {
+					if lastBlockLine >= 0 {
+						lines[lastBlockLine] = strings.TrimSuffix(strings.TrimRight(lines[lastBlockLine], " \t\r"), ";") + "//"
+					}
+					lines[n] = "DELIMITER ;"
+					inBlock = false
+				}
+			}
+		} else if !inUp {
+			lines[n] = ""
+		} else if inBlock && strings.TrimSpace(line) != "" {
+			lastBlockLine = n
+		}
+	}
+	return strings.Join(lines, "\n")
+}
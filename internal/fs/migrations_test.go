@@ -0,0 +1,116 @@
+package fs
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	cases := []struct {
+		files    []string
+		expected []string
+	}{
+		{ // golang-migrate
+			[]string{"2_add_posts.up.sql", "2_add_posts.down.sql", "10_add_index.up.sql", "1_init.up.sql", "README.md"},
+			[]string{"1_init.up.sql", "2_add_posts.up.sql", "10_add_index.up.sql"},
+		},
+		{ // Flyway
+			[]string{"V1_2__second.sql", "V1_10__third.sql", "V1__first.sql", "U1__first.sql", "R__views.sql", "R__a_procs.sql", "V2.1__fourth.sql"},
+			[]string{"V1__first.sql", "V1_2__second.sql", "V1_10__third.sql", "V2.1__fourth.sql", "R__a_procs.sql", "R__views.sql"},
+		},
+		{ // goose or plain numbered files
+			[]string{"00002-posts.sql", "00001_init.sql", "20240101_legacy.rb"},
+			[]string{"00001_init.sql", "00002-posts.sql"},
+		},
+	}
+	var dirPath string
+	for _, c := range cases {
+		dirPath = t.TempDir()
+		for _, name := range c.files {
+			WriteTestFile(t, filepath.Join(dirPath, name), "CREATE TABLE foo (id int);\n")
+		}
+		mfs, err := MigrationFiles(dirPath)
+		if err != nil {
+			t.Fatalf("Unexpected error from MigrationFiles: %v", err)
+		}
+		var actual []string
+		for _, mf := range mfs {
+			actual = append(actual, filepath.Base(mf.FilePath))
+		}
+		if !slices.Equal(actual, c.expected) {
+			t.Errorf("Unexpected result from MigrationFiles: expected %v, found %v", c.expected, actual)
+		}
+	}
+
+	// Duplicate versions, unrecognized names, and empty dirs are errors
+	WriteTestFile(t, filepath.Join(dirPath, "1_dupe.sql"), "CREATE TABLE bar (id int);\n")
+	if _, err := MigrationFiles(dirPath); err == nil {
+		t.Error("Expected error from MigrationFiles with duplicate versions, but err was nil")
+	}
+	dirPath = t.TempDir()
+	if _, err := MigrationFiles(dirPath); err == nil {
+		t.Error("Expected error from MigrationFiles with empty dir, but err was nil")
+	}
+	WriteTestFile(t, filepath.Join(dirPath, "schema.sql"), "CREATE TABLE bar (id int);\n")
+	if _, err := MigrationFiles(dirPath); err == nil {
+		t.Error("Expected error from MigrationFiles with unversioned file, but err was nil")
+	}
+}
+
+func TestMigrationFileStatements(t *testing.T) {
+	dirPath := t.TempDir()
+	contents := `-- +goose Up
+CREATE TABLE foo (id int);
+INSERT INTO foo VALUES (1);
+-- +goose StatementBegin
+CREATE PROCEDURE p()
+BEGIN
+  SELECT 1;
+END;
+-- +goose StatementEnd
+ALTER TABLE foo ADD COLUMN name varchar(30);
+
+-- +goose Down
+DROP TABLE foo;
+`
+	WriteTestFile(t, filepath.Join(dirPath, "001_init.sql"), contents)
+	mf := &MigrationFile{FilePath: filepath.Join(dirPath, "001_init.sql")}
+	statements, err := mf.Statements()
+	if err != nil {
+		t.Fatalf("Unexpected error from Statements: %v", err)
+	}
+	expected := []struct {
+		body   string
+		lineNo int
+	}{
+		{"CREATE TABLE foo (id int)", 2},
+		{"INSERT INTO foo VALUES (1)", 3},
+		{"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND", 5},
+		{"ALTER TABLE foo ADD COLUMN name varchar(30)", 10},
+	}
+	if len(statements) != len(expected) {
+		t.Fatalf("Expected %d statements, instead found %d: %v", len(expected), len(statements), statements)
+	}
+	for n, stmt := range statements {
+		if stmt.Body() != expected[n].body || stmt.LineNo != expected[n].lineNo {
+			t.Errorf("Unexpected statement[%d]: body %q at line %d", n, stmt.Body(), stmt.LineNo)
+		}
+	}
+
+	// Files without goose annotations are parsed as-is
+	WriteTestFile(t, filepath.Join(dirPath, "002_plain.sql"), "DELIMITER ;\nDROP TABLE foo;\n")
+	mf = &MigrationFile{FilePath: filepath.Join(dirPath, "002_plain.sql")}
+	if statements, err := mf.Statements(); err != nil || len(statements) != 1 || statements[0].Body() != "DROP TABLE foo" {
+		t.Errorf("Unexpected result from Statements: %v, %v", statements, err)
+	}
+
+	// USE commands are an error, rather than being silently ignored
+	for _, contents := range []string{"USE foo;\nDROP TABLE foo;\n", "DROP TABLE foo;\nuse foo\n"} {
+		WriteTestFile(t, filepath.Join(dirPath, "003_use.sql"), contents)
+		mf = &MigrationFile{FilePath: filepath.Join(dirPath, "003_use.sql")}
+		if statements, err := mf.Statements(); err == nil {
+			t.Errorf("Expected error from Statements with contents %q, instead found %v", contents, statements)
+		}
+	}
+}
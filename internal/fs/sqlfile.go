@@ -16,6 +16,7 @@ type SQLFile struct {
 	FilePath   string
 	Statements []*tengo.Statement
 	Dirty      bool
+	written    bool // true if Write has successfully created, replaced, or deleted the file
 }
 
 // FileName returns the file name of sqlFile without its directory path.
@@ -54,12 +55,17 @@ func (sqlFile *SQLFile) Write() (n int, err error) {
 		}
 	}
 	if keepFile {
-		n, err = b.Len(), os.WriteFile(sqlFile.FilePath, b.Bytes(), 0666)
+		// The file may be in a subdirectory which doesn't exist yet, depending on
+		// the dir's layout
+		if err = os.MkdirAll(filepath.Dir(sqlFile.FilePath), 0777); err == nil {
+			n, err = b.Len(), os.WriteFile(sqlFile.FilePath, b.Bytes(), 0666)
+		}
 	} else {
 		err = sqlFile.Delete()
 	}
 	if err == nil {
 		sqlFile.Dirty = false
+		sqlFile.written = true
 	}
 	return n, err
 }
@@ -74,12 +80,25 @@ func makeDelimiterCommand(newDelimiter, defaultDatabase, filePath string) *tengo
 	}
 }
 
+func makeUseCommand(defaultDatabase, delimiter, filePath string) *tengo.Statement {
+	return &tengo.Statement{
+		File:            filePath,
+		Text:            "USE " + tengo.EscapeIdentifier(defaultDatabase) + "\n",
+		Type:            tengo.StatementTypeCommand,
+		Delimiter:       delimiter,
+		DefaultDatabase: defaultDatabase,
+	}
+}
+
 // AddStatement appends stmt to sqlFile's list of statements. This method marks
 // the file as dirty, but does not rewrite the file.
 // This method may adjust stmt.Text and stmt.Delimiter as needed to ensure the
 // text contains the appropriate delimiter for the type of statement, as well as
 // a trailing newline. DELIMITER command statements may also be inserted into
-// sqlFile as necessary for stmt.
+// sqlFile as necessary for stmt. If stmt.DefaultDatabase is non-empty and
+// differs from the default database in effect at the end of the file, a USE
+// command is inserted as well; otherwise, stmt.DefaultDatabase is set to the
+// file's default database.
 func (sqlFile *SQLFile) AddStatement(stmt *tengo.Statement) {
 	// Prune any trailing DELIMITER or USE commands from the end of the file, as
 	// these have no effect at the end of the file anyway.
@@ -99,6 +118,12 @@ func (sqlFile *SQLFile) AddStatement(stmt *tengo.Statement) {
 		lastStmt.NormalizeTrailer()
 	}
 
+	// Add a USE command before stmt, if needed
+	if stmt.DefaultDatabase != "" && stmt.DefaultDatabase != defaultDatabase {
+		defaultDatabase = stmt.DefaultDatabase
+		sqlFile.Statements = append(sqlFile.Statements, makeUseCommand(defaultDatabase, currentDelimiter, sqlFile.FilePath))
+	}
+
 	// Add a DELIMITER command before stmt, if needed
 	if stmt.Compound && currentDelimiter == ";" {
 		sqlFile.Statements = append(sqlFile.Statements, makeDelimiterCommand("//", defaultDatabase, sqlFile.FilePath))
@@ -174,6 +199,13 @@ func (sqlFile *SQLFile) statementIndex(stmt *tengo.Statement) int {
 	panic(fmt.Errorf("Statement previously at %s not actually found in file", stmt.Location()))
 }
 
+// IsDataFile returns true if filePath names a file which may contain managed
+// table data, meaning its base name ends in ".data.sql". INSERT statements in
+// other *.sql files are ignored, like any other unsupported statement type.
+func IsDataFile(filePath string) bool {
+	return strings.HasSuffix(filepath.Base(filePath), ".data.sql")
+}
+
 // FileNameForObject returns a string containing the filename to use for the
 // SQLFile representing the supplied object name. Special characters in the
 // objectName will be removed; however, there is no risk of "conflicts" since
@@ -91,6 +91,38 @@ func TestSQLFileAddStatement(t *testing.T) {
 	if len(sf.Statements) != 6 || !sf.Dirty || sf.Statements[5].Text != create+";\n" {
 		t.Fatalf("Unexpected values in SQLFile: dirty=%t, len(statements)=%d", sf.Dirty, len(sf.Statements))
 	}
+
+	// Add a table with a specific default database. This should insert a USE
+	// command before it, but only the first time.
+	for n, name := range []string{"foo", "bar"} {
+		create = "CREATE TABLE " + name + " (id int)"
+		stmt = &tengo.Statement{
+			Type:            tengo.StatementTypeCreate,
+			ObjectType:      tengo.ObjectTypeTable,
+			ObjectName:      name,
+			Text:            create,
+			Delimiter:       ";",
+			DefaultDatabase: "otherdb",
+		}
+		sf.AddStatement(stmt)
+		if expectedLen := 8 + n; len(sf.Statements) != expectedLen || sf.Statements[6].Text != "USE `otherdb`\n" || sf.Statements[expectedLen-1].Text != create+";\n" {
+			t.Fatalf("Unexpected values in SQLFile: len(statements)=%d", len(sf.Statements))
+		}
+	}
+
+	// Add a table without any default database: it should be placed under the
+	// file's existing default database
+	stmt = &tengo.Statement{
+		Type:       tengo.StatementTypeCreate,
+		ObjectType: tengo.ObjectTypeTable,
+		ObjectName: "baz",
+		Text:       "CREATE TABLE baz (id int)",
+		Delimiter:  ";",
+	}
+	sf.AddStatement(stmt)
+	if len(sf.Statements) != 10 || stmt.DefaultDatabase != "otherdb" {
+		t.Errorf("Unexpected values in SQLFile: len(statements)=%d, default database=%q", len(sf.Statements), stmt.DefaultDatabase)
+	}
 }
 
 func TestSQLFileEditStatementText(t *testing.T) {
@@ -0,0 +1,4 @@
+CREATE TABLE baz (
+  id int unsigned NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
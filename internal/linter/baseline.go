@@ -0,0 +1,153 @@
+package linter
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/skeema/skeema/internal/fs"
+)
+
+// BaselineEntry is a fingerprint of a linter problem. Fingerprints purposely
+// exclude file names and line numbers, so that they remain stable as unrelated
+// parts of the schema change. Count tracks how many identical problems exist.
+type BaselineEntry struct {
+	Dir     string `json:"dir"` // relative to the baseline's base path, using forward slashes
+	Rule    string `json:"rule"`
+	Object  string `json:"object"`
+	Summary string `json:"summary"`
+	Count   int    `json:"count"`
+}
+
+// baselineKey is a BaselineEntry without its Count.
+type baselineKey struct {
+	dir, rule, object, summary string
+}
+
+// Baseline tracks known linter problems, so that they can be suppressed in
+// order for only new problems to be reported. It also records all problems
+// found, so that a new baseline may be written.
+type Baseline struct {
+	basePath string
+	known    map[baselineKey]int // from baseline file; only populated if ReadBaseline was used
+	found    map[baselineKey]int // all problems found by Apply, including suppressed ones
+	dirs     map[string]bool     // relative dir paths which have been linted
+}
+
+// NewBaseline returns an empty Baseline. Directories are tracked relative to
+// basePath.
+func NewBaseline(basePath string) *Baseline {
+	return &Baseline{
+		basePath: basePath,
+		known:    make(map[baselineKey]int),
+		found:    make(map[baselineKey]int),
+		dirs:     make(map[string]bool),
+	}
+}
+
+// ReadBaseline returns a Baseline populated with the known problems listed in
+// the supplied baseline file.
+func ReadBaseline(basePath, filePath string) (*Baseline, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var entries []BaselineEntry
+	if err := json.Unmarshal(contents, &entries); err != nil {
+		return nil, err
+	}
+	b := NewBaseline(basePath)
+	for _, entry := range entries {
+		b.known[entry.key()] += entry.Count
+	}
+	return b, nil
+}
+
+func (entry BaselineEntry) key() baselineKey {
+	return baselineKey{dir: entry.Dir, rule: entry.Rule, object: entry.Object, summary: entry.Summary}
+}
+
+func (key baselineKey) entry(count int) BaselineEntry {
+	return BaselineEntry{Dir: key.dir, Rule: key.rule, Object: key.object, Summary: key.summary, Count: count}
+}
+
+// Apply records the annotations in result, which should be the result of
+// linting dir, and then removes any annotations which are known problems.
+// SQL errors from the workspace are never recorded or suppressed.
+func (b *Baseline) Apply(dir *fs.Dir, result *Result) {
+	relPath, err := filepath.Rel(b.basePath, dir.Path)
+	if err != nil {
+		relPath = dir.Path
+	}
+	relPath = filepath.ToSlash(relPath)
+	b.dirs[relPath] = true
+
+	remaining := make(map[baselineKey]int)
+	kept := result.Annotations[:0]
+	for _, a := range result.Annotations {
+		if strings.HasPrefix(a.RuleName, "sql-") {
+			kept = append(kept, a)
+			continue
+		}
+		key := baselineKey{dir: relPath, rule: a.RuleName, object: a.Statement.ObjectKey().String(), summary: a.Summary}
+		b.found[key]++
+		if _, ok := remaining[key]; !ok {
+			remaining[key] = b.known[key]
+		}
+		if remaining[key] > 0 {
+			remaining[key]--
+			switch a.Severity {
+			case SeverityError:
+				result.ErrorCount--
+			case SeverityWarning:
+				result.WarningCount--
+			}
+			result.Debug("Suppressed %s problem at %s due to baseline", a.RuleName, a.Location())
+			continue
+		}
+		kept = append(kept, a)
+	}
+	result.Annotations = kept
+}
+
+// Fixed returns known problems which were not found in any linted directory,
+// or were found fewer times than expected. The Count of each returned entry
+// indicates how many fewer times the problem was found.
+func (b *Baseline) Fixed() []BaselineEntry {
+	var fixed []BaselineEntry
+	for key, count := range b.known {
+		if b.dirs[key.dir] && b.found[key] < count {
+			fixed = append(fixed, key.entry(count-b.found[key]))
+		}
+	}
+	sortBaselineEntries(fixed)
+	return fixed
+}
+
+// Write writes a baseline file containing all problems found by Apply.
+func (b *Baseline) Write(filePath string) error {
+	entries := make([]BaselineEntry, 0, len(b.found))
+	for key, count := range b.found {
+		entries = append(entries, key.entry(count))
+	}
+	sortBaselineEntries(entries)
+	contents, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(contents, '\n'), 0666)
+}
+
+func sortBaselineEntries(entries []BaselineEntry) {
+	slices.SortFunc(entries, func(a, b BaselineEntry) int {
+		return cmp.Or(
+			cmp.Compare(a.Dir, b.Dir),
+			cmp.Compare(a.Object, b.Object),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.Summary, b.Summary),
+		)
+	})
+}
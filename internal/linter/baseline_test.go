@@ -0,0 +1,76 @@
+package linter
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestBaseline(t *testing.T) {
+	basePath := t.TempDir()
+	dir := &fs.Dir{Path: filepath.Join(basePath, "product")}
+	foo := &tengo.Statement{File: filepath.Join(dir.Path, "foo.sql"), LineNo: 1, Type: tengo.StatementTypeCreate, ObjectType: tengo.ObjectTypeTable, ObjectName: "foo"}
+	bar := &tengo.Statement{File: filepath.Join(dir.Path, "bar.sql"), LineNo: 1, Type: tengo.StatementTypeCreate, ObjectType: tengo.ObjectTypeTable, ObjectName: "bar"}
+	makeResult := func() *Result {
+		r := &Result{}
+		r.Annotate(foo, SeverityWarning, "has-float", Note{LineOffset: 2, Summary: "Column using floating point type"})
+		r.Annotate(foo, SeverityWarning, "has-float", Note{LineOffset: 3, Summary: "Column using floating point type"})
+		r.Annotate(bar, SeverityError, "pk", Note{Summary: "No primary key"})
+		r.Annotate(bar, SeverityError, "sql-1064", Note{Summary: "SQL statement returned an error"})
+		return r
+	}
+
+	// Record all problems and write them to a file. SQL errors are excluded.
+	b := NewBaseline(basePath)
+	b.Apply(dir, makeResult())
+	filePath := filepath.Join(basePath, "baseline.json")
+	if err := b.Write(filePath); err != nil {
+		t.Fatalf("Unexpected error from Write: %v", err)
+	}
+
+	// Reading the file back should suppress all of the recorded problems
+	b, err := ReadBaseline(basePath, filePath)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadBaseline: %v", err)
+	}
+	result := makeResult()
+	b.Apply(dir, result)
+	if len(result.Annotations) != 1 || result.Annotations[0].RuleName != "sql-1064" || result.ErrorCount != 1 || result.WarningCount != 0 {
+		t.Errorf("Unexpected result after Apply: %+v", result)
+	}
+	if fixed := b.Fixed(); len(fixed) != 0 {
+		t.Errorf("Expected no fixed problems, instead found %+v", fixed)
+	}
+
+	// A new problem should not be suppressed, and problems which went away should
+	// be reported as fixed
+	b, _ = ReadBaseline(basePath, filePath)
+	result = &Result{}
+	result.Annotate(foo, SeverityWarning, "has-float", Note{LineOffset: 5, Summary: "Column using floating point type"})
+	result.Annotate(foo, SeverityWarning, "nullable", Note{LineOffset: 5, Summary: "Nullable column"})
+	b.Apply(dir, result)
+	if len(result.Annotations) != 1 || result.Annotations[0].RuleName != "nullable" || result.WarningCount != 1 {
+		t.Errorf("Unexpected result after Apply: %+v", result)
+	}
+	expected := []BaselineEntry{
+		{Dir: "product", Rule: "pk", Object: "table `bar`", Summary: "No primary key", Count: 1},
+		{Dir: "product", Rule: "has-float", Object: "table `foo`", Summary: "Column using floating point type", Count: 1},
+	}
+	if fixed := b.Fixed(); !slices.Equal(fixed, expected) {
+		t.Errorf("Unexpected result from Fixed:\nExpected: %+v\nActual:   %+v", expected, fixed)
+	}
+
+	// Problems in dirs which weren't linted aren't considered fixed
+	b, _ = ReadBaseline(basePath, filePath)
+	b.Apply(&fs.Dir{Path: filepath.Join(basePath, "other")}, &Result{})
+	if fixed := b.Fixed(); len(fixed) != 0 {
+		t.Errorf("Expected no fixed problems, instead found %+v", fixed)
+	}
+
+	if _, err := ReadBaseline(basePath, filepath.Join(basePath, "missing.json")); err == nil {
+		t.Error("Expected error from ReadBaseline on nonexistent file, but err was nil")
+	}
+}
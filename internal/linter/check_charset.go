@@ -14,6 +14,7 @@ func init() {
 		Name:            "charset",
 		Description:     "Only allow character sets listed in --allow-charset",
 		DefaultSeverity: SeverityWarning,
+		Fixer:           charsetFixer,
 	}
 	rule.RelatedListOption(
 		"allow-charset",
@@ -67,6 +68,39 @@ func charsetChecker(table *tengo.Table, createStatement string, _ *tengo.Schema,
 	return results
 }
 
+// charsetFixer converts the table's default character set, and any columns
+// using character sets which aren't permitted, to utf8mb4 if it is listed in
+// option allow-charset. Otherwise, the first character set in allow-charset is
+// used. Collations are changed to the default collation of the new character
+// set.
+func charsetFixer(table *tengo.Table, opts *Options) {
+	allowList := opts.AllowList("charset")
+	if len(allowList) == 0 {
+		return
+	}
+	newCharSet := allowList[0]
+	if opts.IsAllowed("charset", "utf8mb4") {
+		newCharSet = "utf8mb4"
+	}
+	if !opts.IsAllowed("charset", table.CharSet) {
+		table.CharSet = newCharSet
+		table.Collation = ""
+		table.ShowCollation = false
+	}
+	for _, col := range table.Columns {
+		if col.CharSet == "" {
+			continue
+		}
+		if !opts.IsAllowed("charset", col.CharSet) {
+			col.CharSet = newCharSet
+			col.Collation = ""
+			col.ShowCollation = false
+		}
+		// Columns using the table's default character set don't need to specify it
+		col.ShowCharSet = (col.CharSet != table.CharSet)
+	}
+}
+
 func makeCharsetMessage(table *tengo.Table, column *tengo.Column, opts *Options) string {
 	var subject, charSet, using, allowedList, moreInfo string
 	if column == nil {
@@ -12,6 +12,7 @@ func init() {
 		Name:            "display-width",
 		Description:     "Only allow default display width for int types",
 		DefaultSeverity: SeverityWarning,
+		Fixer:           displayWidthFixer,
 		Deprecation:     "This option will be removed in Skeema v2. For more information, visit https://www.skeema.io/v2-changes",
 	})
 }
@@ -28,36 +29,54 @@ var signedDefaultWidths = map[string]uint16{
 func displayWidthChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, _ *Options) []Note {
 	results := make([]Note, 0)
 	for _, col := range table.Columns {
-		if col.Type.Size == 0 || !col.Type.Integer() { // non-int, or no display width due to MySQL 8.0.19+
+		defaultWidth, suffix, ok := nonDefaultDisplayWidth(col)
+		if !ok {
 			continue
 		}
-		if col.Type.Zerofill {
-			continue // non-default display width may be intentional with zerofill
-		}
-		if col.Type.Base == "tinyint" && col.Type.Size == 1 {
-			continue // allow tinyint(1) since bool is an alias for this
-		}
-		defaultWidth := signedDefaultWidths[col.Type.Base]
-		var suffix string
-		if col.Type.Unsigned {
-			suffix = " unsigned"
-			if col.Type.Base != "bigint" {
-				defaultWidth--
-			}
+		message := fmt.Sprintf(
+			"Column %s of %s is using display width %d, but the default for %s%s is %d.\nInteger display widths do not control what range of values may be stored in a column. Typically they have no effect whatsoever. If in doubt, omit the width entirely, or use the default of %s(%d)%s.",
+			col.Name, table.ObjectKey(), col.Type.Size,
+			col.Type.Base, suffix, defaultWidth,
+			col.Type.Base, defaultWidth, suffix,
+		)
+		results = append(results, Note{
+			LineOffset: FindColumnLineOffset(col, createStatement),
+			Summary:    "Non-default display width detected",
+			Message:    message,
+		})
+	}
+	return results
+}
+
+// displayWidthFixer changes non-default integer display widths to the default
+// for each type.
+func displayWidthFixer(table *tengo.Table, _ *Options) {
+	for _, col := range table.Columns {
+		if defaultWidth, suffix, ok := nonDefaultDisplayWidth(col); ok {
+			col.Type = tengo.ParseColumnType(fmt.Sprintf("%s(%d)%s", col.Type.Base, defaultWidth, suffix))
 		}
-		if col.Type.Size != defaultWidth {
-			message := fmt.Sprintf(
-				"Column %s of %s is using display width %d, but the default for %s%s is %d.\nInteger display widths do not control what range of values may be stored in a column. Typically they have no effect whatsoever. If in doubt, omit the width entirely, or use the default of %s(%d)%s.",
-				col.Name, table.ObjectKey(), col.Type.Size,
-				col.Type.Base, suffix, defaultWidth,
-				col.Type.Base, defaultWidth, suffix,
-			)
-			results = append(results, Note{
-				LineOffset: FindColumnLineOffset(col, createStatement),
-				Summary:    "Non-default display width detected",
-				Message:    message,
-			})
+	}
+}
+
+// nonDefaultDisplayWidth returns ok=true if col is an integer column using a
+// non-default display width, along with the default width for its type and a
+// " unsigned" suffix if applicable.
+func nonDefaultDisplayWidth(col *tengo.Column) (defaultWidth uint16, suffix string, ok bool) {
+	if col.Type.Size == 0 || !col.Type.Integer() { // non-int, or no display width due to MySQL 8.0.19+
+		return 0, "", false
+	}
+	if col.Type.Zerofill {
+		return 0, "", false // non-default display width may be intentional with zerofill
+	}
+	if col.Type.Base == "tinyint" && col.Type.Size == 1 {
+		return 0, "", false // allow tinyint(1) since bool is an alias for this
+	}
+	defaultWidth = signedDefaultWidths[col.Type.Base]
+	if col.Type.Unsigned {
+		suffix = " unsigned"
+		if col.Type.Base != "bigint" {
+			defaultWidth--
 		}
 	}
-	return results
+	return defaultWidth, suffix, col.Type.Size != defaultWidth
 }
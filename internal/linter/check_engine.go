@@ -13,6 +13,7 @@ func init() {
 		Name:            "engine",
 		Description:     "Only allow storage engines listed in --allow-engine",
 		DefaultSeverity: SeverityWarning,
+		Fixer:           engineFixer,
 	}
 	rule.RelatedListOption(
 		"allow-engine",
@@ -41,3 +42,11 @@ func engineChecker(table *tengo.Table, createStatement string, _ *tengo.Schema,
 		Message:    message,
 	}
 }
+
+// engineFixer changes the table's storage engine to the first one listed in
+// option allow-engine.
+func engineFixer(table *tengo.Table, opts *Options) {
+	if allowedEngines := opts.AllowList("engine"); len(allowedEngines) > 0 {
+		table.Engine = allowedEngines[0]
+	}
+}
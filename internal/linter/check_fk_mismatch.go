@@ -0,0 +1,93 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(foreignKeyMismatchChecker),
+		Name:            "fk-mismatch",
+		Description:     "Flag foreign keys where column types, signedness, character sets, or collations differ from same-schema parent table",
+		DefaultSeverity: SeverityWarning,
+	})
+}
+
+func foreignKeyMismatchChecker(table *tengo.Table, createStatement string, schema *tengo.Schema, _ *Options) []Note {
+	results := make([]Note, 0)
+	var childCols map[string]*tengo.Column
+	for _, fk := range table.ForeignKeys {
+		// Parent tables in other schemas cannot be checked, and missing parent tables
+		// are already handled by the fk-parent rule
+		if fk.ReferencedSchemaName != "" {
+			continue
+		}
+		parentTable := schema.Table(fk.ReferencedTableName)
+		if parentTable == nil {
+			continue
+		}
+		if childCols == nil {
+			childCols = table.ColumnsByName()
+		}
+		parentCols := parentTable.ColumnsByName()
+		var diffs []string
+		for n, colName := range fk.ColumnNames {
+			if n >= len(fk.ReferencedColumnNames) {
+				break
+			}
+			childCol, parentCol := childCols[colName], parentCols[fk.ReferencedColumnNames[n]]
+			if childCol == nil || parentCol == nil || foreignKeyColumnsMatch(childCol, parentCol) {
+				continue
+			}
+			diffs = append(diffs, fmt.Sprintf(
+				"%s %s vs parent %s %s",
+				tengo.EscapeIdentifier(childCol.Name), foreignKeyColumnDescription(childCol),
+				tengo.EscapeIdentifier(parentCol.Name), foreignKeyColumnDescription(parentCol),
+			))
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		message := fmt.Sprintf(
+			"In table %s, foreign key constraint %s references columns in parent table %s which do not match exactly:\n%s\nMismatched column types or collations may cause implicit conversions, preventing efficient use of indexes in joins and foreign key checks.",
+			tengo.EscapeIdentifier(table.Name),
+			tengo.EscapeIdentifier(fk.Name),
+			tengo.EscapeIdentifier(fk.ReferencedTableName),
+			strings.Join(diffs, "\n"),
+		)
+		results = append(results, Note{
+			LineOffset: FindForeignKeyLineOffset(fk, createStatement),
+			Summary:    "Foreign key column mismatch",
+			Message:    message,
+		})
+	}
+	return results
+}
+
+// foreignKeyColumnsMatch returns true if the child and parent column have
+// equivalent types, signedness, character sets, and collations. Lengths of
+// string types are permitted to differ, since this does not require any
+// conversion.
+func foreignKeyColumnsMatch(child, parent *tengo.Column) bool {
+	if child.CharSet != parent.CharSet || child.Collation != parent.Collation {
+		return false
+	}
+	childType, parentType := child.Type, parent.Type
+	switch childType.Base {
+	case "char", "varchar", "binary", "varbinary":
+		return childType.Base == parentType.Base
+	}
+	return childType.Equivalent(parentType)
+}
+
+// foreignKeyColumnDescription returns the column's type, along with its
+// collation for textual columns.
+func foreignKeyColumnDescription(col *tengo.Column) string {
+	if col.Collation != "" {
+		return col.Type.String() + " COLLATE " + col.Collation
+	}
+	return col.Type.String()
+}
@@ -0,0 +1,126 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(keyLengthChecker),
+		Name:            "key-length",
+		Description:     "Flag InnoDB indexes whose maximum key length is over or close to the limit",
+		DefaultSeverity: SeverityWarning,
+		RelatedOption:   mybase.StringOption("innodb-default-row-format", 0, "dynamic", `InnoDB row format assumed by --lint-row-size and --lint-key-length for tables lacking ROW_FORMAT (valid values: "dynamic", "compact", "redundant")`),
+		ConfigFunc:      RuleConfigFunc(innodbLimitsConfiger), // also uses option innodb-page-size from row-size rule
+	})
+}
+
+var rePrimaryKey = regexp.MustCompile(`(?i)primary\s+key`)
+
+// innodbMaxKeyLength returns InnoDB's maximum index key length in bytes, for
+// the supplied page size in kilobytes.
+func innodbMaxKeyLength(pageSizeKB int) uint64 {
+	switch pageSizeKB {
+	case 4:
+		return 768
+	case 8:
+		return 1536
+	default:
+		return 3072
+	}
+}
+
+func keyLengthChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, opts *Options) []Note {
+	results := make([]Note, 0)
+	if table.Engine != "InnoDB" {
+		return results
+	}
+	cfg := opts.RuleConfig["key-length"].(innodbLimitsConfig)
+	pageSizeKB := cfg.pageSizeKB
+	limit := innodbMaxKeyLength(pageSizeKB)
+	compactFormat := tableHasCompactRowFormat(table, cfg)
+	cols := table.ColumnsByName()
+	indexes := table.SecondaryIndexes
+	if table.PrimaryKey != nil {
+		indexes = append([]*tengo.Index{table.PrimaryKey}, indexes...)
+	}
+	for _, idx := range indexes {
+		if idx.Type != "BTREE" && idx.Type != "" {
+			continue // FULLTEXT, SPATIAL, etc have different limits
+		}
+		var lineOffset int
+		var indexDesc string
+		if idx.PrimaryKey {
+			lineOffset = FindFirstLineOffset(rePrimaryKey, createStatement)
+			indexDesc = "Primary key"
+		} else {
+			lineOffset = findNamedClauseLineOffset(`(?:key|index)`, idx.Name, createStatement)
+			indexDesc = "Index " + tengo.EscapeIdentifier(idx.Name)
+		}
+
+		var keyLength uint64
+		for _, part := range idx.Parts {
+			col := cols[part.ColumnName]
+			if col == nil {
+				continue // functional index part; length cannot be determined
+			}
+			partLength := indexPartMaxBytes(part, col)
+			keyLength += partLength
+
+			// Older row formats also limit each column (or column prefix) to 767 bytes
+			if compactFormat && partLength > 767 {
+				results = append(results, Note{
+					LineOffset: lineOffset,
+					Summary:    "Index column exceeds length limit",
+					Message: fmt.Sprintf(
+						"%s of %s indexes column %s with a maximum length of %d bytes, which exceeds the 767 byte limit for %s. This will cause a \"Specified key was too long\" error. Consider using ROW_FORMAT=DYNAMIC, or indexing a shorter prefix of the column.",
+						indexDesc, table.ObjectKey(), tengo.EscapeIdentifier(col.Name), partLength, rowFormatDesc(table, cfg),
+					),
+				})
+			}
+		}
+
+		if keyLength > limit {
+			results = append(results, Note{
+				LineOffset: lineOffset,
+				Summary:    "Index key length exceeds limit",
+				Message: fmt.Sprintf(
+					"%s of %s has a maximum key length of %d bytes, which exceeds InnoDB's limit of %d bytes for a %dKB page size. This will cause a \"Specified key was too long\" error. Consider indexing fewer columns, or shorter prefixes of string columns.",
+					indexDesc, table.ObjectKey(), keyLength, limit, pageSizeKB,
+				),
+			})
+		} else if float64(keyLength) >= sizeLimitWarnRatio*float64(limit) {
+			results = append(results, Note{
+				LineOffset: lineOffset,
+				Summary:    "Index key length close to limit",
+				Message: fmt.Sprintf(
+					"%s of %s has a maximum key length of %d bytes, which is close to InnoDB's limit of %d bytes for a %dKB page size. Widening its columns or changing their character set may cause a \"Specified key was too long\" error.",
+					indexDesc, table.ObjectKey(), keyLength, limit, pageSizeKB,
+				),
+			})
+		}
+	}
+	return results
+}
+
+// indexPartMaxBytes returns the maximum number of bytes that an index part may
+// occupy in an index key. Prefix lengths are specified in characters for string
+// types, or bytes for binary types.
+func indexPartMaxBytes(part tengo.IndexPart, col *tengo.Column) uint64 {
+	if fixed, ok := columnFixedBytes(col.Type); ok {
+		return fixed
+	}
+	maxBytes, _ := columnMaxBytes(col)
+	if part.PrefixLength > 0 {
+		prefixBytes := uint64(part.PrefixLength)
+		if _, isString := col.Type.StringMaxBytes(col.CharSet); isString {
+			prefixBytes, _ = tengo.ParseColumnType(fmt.Sprintf("varchar(%d)", part.PrefixLength)).StringMaxBytes(col.CharSet)
+		}
+		maxBytes = min(maxBytes, prefixBytes)
+	}
+	return maxBytes
+}
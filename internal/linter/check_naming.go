@@ -0,0 +1,171 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// namingKind describes one kind of named table component which may be checked
+// by a naming convention rule.
+type namingKind struct {
+	noun           string // human-readable name of the component type
+	defaultPattern string
+}
+
+var namingKinds = map[string]namingKind{
+	"table":  {noun: "table", defaultPattern: "[a-z][a-z0-9_]*"},
+	"column": {noun: "column", defaultPattern: "[a-z][a-z0-9_]*"},
+	"index":  {noun: "index", defaultPattern: "idx_{columns}"},
+	"unique": {noun: "unique index", defaultPattern: "uk_{columns}"},
+	"fk":     {noun: "foreign key", defaultPattern: "fk_{table}_{parent}"},
+	"check":  {noun: "check constraint", defaultPattern: "chk_.+"},
+}
+
+func init() {
+	for kind, nk := range namingKinds {
+		rule := Rule{
+			CheckerFunc:     TableChecker(makeNamingChecker(kind)),
+			Name:            "name-" + kind,
+			Description:     fmt.Sprintf("Flag %s names which don't match the pattern in --name-%s-pattern", nk.noun, kind),
+			DefaultSeverity: SeverityIgnore,
+		}
+		rule.RelatedOption = mybase.StringOption(
+			"name-"+kind+"-pattern",
+			0,
+			nk.defaultPattern,
+			fmt.Sprintf("Regular expression for %s names in --lint-name-%s; may use {table}, {columns}, {parent}", nk.noun, kind),
+		)
+		rule.ConfigFunc = RuleConfigFunc(func(config *mybase.Config) any {
+			optionName := "name-" + kind + "-pattern"
+			pattern := config.Get(optionName)
+			if _, err := expandNamingPattern(pattern, "", "", ""); err != nil {
+				return fmt.Errorf("Invalid value for option %s: %w", optionName, err)
+			}
+			return pattern
+		})
+		RegisterRule(rule)
+	}
+}
+
+// expandNamingPattern replaces any placeholders in pattern with the supplied
+// values, and then compiles it into a case-sensitive regular expression which
+// must match the entire name. Placeholders which aren't meaningful for a given
+// kind of component are replaced with an empty string.
+func expandNamingPattern(pattern, table, columns, parent string) (*regexp.Regexp, error) {
+	replacer := strings.NewReplacer(
+		"{table}", regexp.QuoteMeta(table),
+		"{columns}", regexp.QuoteMeta(columns),
+		"{parent}", regexp.QuoteMeta(parent),
+	)
+	return regexp.Compile("^(?:" + replacer.Replace(pattern) + ")$")
+}
+
+// expectedNamingPatternName returns the exact name required by pattern, if
+// pattern uses placeholders but is otherwise just literal text. Otherwise, an
+// empty string is returned.
+func expectedNamingPatternName(pattern, table, columns, parent string) string {
+	literal := strings.NewReplacer("{table}", "", "{columns}", "", "{parent}", "").Replace(pattern)
+	if literal == pattern || regexp.QuoteMeta(literal) != literal {
+		return ""
+	}
+	return strings.NewReplacer("{table}", table, "{columns}", columns, "{parent}", parent).Replace(pattern)
+}
+
+// namedComponent is a name within a table, along with the values that a
+// naming pattern's placeholders should be replaced with when checking it.
+type namedComponent struct {
+	name       string
+	columns    []string
+	parent     string
+	lineOffset int
+}
+
+func makeNamingChecker(kind string) TableChecker {
+	ruleName := "name-" + kind
+	noun := namingKinds[kind].noun
+	capitalizedNoun := strings.ToUpper(noun[:1]) + noun[1:]
+	return func(table *tengo.Table, createStatement string, _ *tengo.Schema, opts *Options) (notes []Note) {
+		pattern := opts.RuleConfig[ruleName].(string)
+		for _, comp := range namedComponents(kind, table, createStatement) {
+			columns := strings.Join(comp.columns, "_")
+			re, err := expandNamingPattern(pattern, table.Name, columns, comp.parent)
+			if err != nil || re.MatchString(comp.name) {
+				continue
+			}
+			message := fmt.Sprintf(
+				"%s %s of %s does not follow the configured naming convention. Names of this type must match option name-%s-pattern: %s",
+				capitalizedNoun, tengo.EscapeIdentifier(comp.name), table.ObjectKey(), kind, pattern,
+			)
+			if expected := expectedNamingPatternName(pattern, table.Name, columns, comp.parent); expected != "" {
+				message += fmt.Sprintf("\nBased on this pattern, the expected name is %s.", tengo.EscapeIdentifier(expected))
+			}
+			notes = append(notes, Note{
+				LineOffset: comp.lineOffset,
+				Summary:    capitalizedNoun + " name does not follow naming convention",
+				Message:    message,
+			})
+		}
+		return notes
+	}
+}
+
+// namedComponents returns the names in table which correspond to the supplied
+// kind of naming rule.
+func namedComponents(kind string, table *tengo.Table, createStatement string) (result []namedComponent) {
+	switch kind {
+	case "table":
+		result = append(result, namedComponent{name: table.Name})
+	case "column":
+		for _, col := range table.Columns {
+			result = append(result, namedComponent{
+				name:       col.Name,
+				lineOffset: FindColumnLineOffset(col, createStatement),
+			})
+		}
+	case "index", "unique":
+		for _, idx := range table.SecondaryIndexes {
+			if idx.Unique != (kind == "unique") {
+				continue
+			}
+			comp := namedComponent{
+				name:       idx.Name,
+				lineOffset: findNamedClauseLineOffset(`(?:key|index)`, idx.Name, createStatement),
+			}
+			for _, part := range idx.Parts {
+				if part.ColumnName != "" {
+					comp.columns = append(comp.columns, part.ColumnName)
+				}
+			}
+			result = append(result, comp)
+		}
+	case "fk":
+		for _, fk := range table.ForeignKeys {
+			result = append(result, namedComponent{
+				name:       fk.Name,
+				columns:    fk.ColumnNames,
+				parent:     fk.ReferencedTableName,
+				lineOffset: FindForeignKeyLineOffset(fk, createStatement),
+			})
+		}
+	case "check":
+		for _, check := range table.Checks {
+			result = append(result, namedComponent{
+				name:       check.Name,
+				lineOffset: findNamedClauseLineOffset(`constraint`, check.Name, createStatement),
+			})
+		}
+	}
+	return result
+}
+
+// findNamedClauseLineOffset returns the line offset of the first line in
+// createStatement where the supplied keyword regex is followed by name. If no
+// match occurs, 0 is returned.
+func findNamedClauseLineOffset(keyword, name, createStatement string) int {
+	re := regexp.MustCompile(fmt.Sprintf("(?i)\\b%s\\s+`?%s\\b", keyword, regexp.QuoteMeta(name)))
+	return FindFirstLineOffset(re, createStatement)
+}
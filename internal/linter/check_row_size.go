@@ -0,0 +1,267 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(rowSizeChecker),
+		Name:            "row-size",
+		Description:     "Flag tables whose maximum row size is over or close to the server or InnoDB limit",
+		DefaultSeverity: SeverityWarning,
+		RelatedOption:   mybase.StringOption("innodb-page-size", 0, "16k", "InnoDB page size assumed by --lint-row-size and --lint-key-length"),
+		ConfigFunc:      RuleConfigFunc(innodbLimitsConfiger),
+	})
+}
+
+// sizeLimitWarnRatio controls how close a size must be to its limit in order
+// for the row-size and key-length rules to flag it.
+const sizeLimitWarnRatio = 0.9
+
+// innodbPageSizes maps permitted values of option innodb-page-size to a page
+// size in kilobytes.
+var innodbPageSizes = map[string]int{
+	"4k": 4, "4096": 4,
+	"8k": 8, "8192": 8,
+	"16k": 16, "16384": 16,
+	"32k": 32, "32768": 32,
+	"64k": 64, "65536": 64,
+}
+
+// innodbLimitsConfig is the supplemental configuration of both the row-size
+// and key-length rules, describing the InnoDB settings of the target server.
+type innodbLimitsConfig struct {
+	pageSizeKB       int    // from option innodb-page-size
+	defaultRowFormat string // from option innodb-default-row-format, uppercased
+}
+
+// innodbLimitsConfiger returns an innodbLimitsConfig based on the values of
+// options innodb-page-size and innodb-default-row-format.
+func innodbLimitsConfiger(config *mybase.Config) any {
+	value := config.Get("innodb-page-size")
+	kb, ok := innodbPageSizes[strings.ToLower(value)]
+	if !ok {
+		return fmt.Errorf("Option innodb-page-size must be one of 4k, 8k, 16k, 32k, or 64k; instead found %q", value)
+	}
+	rowFormat, err := config.GetEnum("innodb-default-row-format", "dynamic", "compact", "redundant")
+	if err != nil {
+		return err
+	}
+	return innodbLimitsConfig{pageSizeKB: kb, defaultRowFormat: strings.ToUpper(rowFormat)}
+}
+
+// innodbMaxRecordSize returns the approximate maximum size of an InnoDB record
+// in bytes, for the supplied page size in kilobytes. This is slightly less than
+// half of the page size, for page sizes up to 16KB.
+func innodbMaxRecordSize(pageSizeKB int) uint64 {
+	switch pageSizeKB {
+	case 4:
+		return 1982
+	case 8:
+		return 4030
+	case 16:
+		return 8126
+	default:
+		return 16318
+	}
+}
+
+func rowSizeChecker(table *tengo.Table, _ string, _ *tengo.Schema, opts *Options) []Note {
+	results := make([]Note, 0)
+	var serverSize, innodbSize uint64
+	var nullable int
+	cfg := opts.RuleConfig["row-size"].(innodbLimitsConfig)
+	compactFormat := tableHasCompactRowFormat(table, cfg)
+	for _, col := range table.Columns {
+		if col.Virtual {
+			continue
+		}
+		if col.Nullable {
+			nullable++
+		}
+		serverBytes, innodbBytes := columnRowBytes(col, compactFormat)
+		serverSize += serverBytes
+		innodbSize += innodbBytes
+	}
+	nullBitmap := uint64(nullable+7) / 8
+	serverSize += nullBitmap
+
+	// The server imposes a 65535 byte row size limit, regardless of storage
+	// engine. BLOB and TEXT types only contribute their length and pointer size.
+	if note := rowSizeNote(table, serverSize, 65535, "the server's row size limit"); note != nil {
+		results = append(results, *note)
+		if serverSize > 65535 {
+			return results // no need to also check InnoDB limit
+		}
+	}
+
+	// InnoDB's limit is based on the page size, and also includes record header
+	// and hidden system columns (transaction ID and rollback pointer, as well as
+	// a row ID if there's no primary key)
+	if table.Engine == "InnoDB" {
+		innodbSize += nullBitmap + 5 + 6 + 7
+		if table.PrimaryKey == nil {
+			innodbSize += 6
+		}
+		limitDesc := fmt.Sprintf("InnoDB's record size limit for a %dKB page size", cfg.pageSizeKB)
+		if rowFormat := rowFormatDesc(table, cfg); rowFormat != "" {
+			limitDesc += " with " + rowFormat
+		}
+		if note := rowSizeNote(table, innodbSize, innodbMaxRecordSize(cfg.pageSizeKB), limitDesc); note != nil {
+			results = append(results, *note)
+		}
+	}
+	return results
+}
+
+func rowSizeNote(table *tengo.Table, size, limit uint64, limitDesc string) *Note {
+	if size > limit {
+		return &Note{
+			Summary: "Table row size exceeds limit",
+			Message: fmt.Sprintf(
+				"%s has a maximum row size of approximately %d bytes, which exceeds %s of %d bytes. Creating or altering this table will fail with a \"Row size too large\" error, or inserting wide rows will fail in some configurations. Consider using smaller column types, or TEXT/BLOB types which are stored off-page.",
+				table.ObjectKey(), size, limitDesc, limit,
+			),
+		}
+	} else if float64(size) >= sizeLimitWarnRatio*float64(limit) {
+		return &Note{
+			Summary: "Table row size close to limit",
+			Message: fmt.Sprintf(
+				"%s has a maximum row size of approximately %d bytes, which is close to %s of %d bytes. Adding columns or widening existing columns may cause a \"Row size too large\" error.",
+				table.ObjectKey(), size, limitDesc, limit,
+			),
+		}
+	}
+	return nil
+}
+
+// tableRowFormat returns the table's InnoDB row format. If the table does not
+// specify a row format, the configured default row format is returned.
+func tableRowFormat(table *tengo.Table, cfg innodbLimitsConfig) string {
+	if rowFormat := table.RowFormat(); rowFormat != "" {
+		return rowFormat
+	}
+	return cfg.defaultRowFormat
+}
+
+// tableHasCompactRowFormat returns true if the table uses an older InnoDB row
+// format, which stores the first 768 bytes of long columns in-row and limits
+// index column prefixes to 767 bytes.
+func tableHasCompactRowFormat(table *tengo.Table, cfg innodbLimitsConfig) bool {
+	rowFormat := tableRowFormat(table, cfg)
+	return rowFormat == "COMPACT" || rowFormat == "REDUNDANT"
+}
+
+// rowFormatDesc returns a description of the table's row format for use in
+// linter messages, or an empty string if the table uses the server's default
+// row format of DYNAMIC.
+func rowFormatDesc(table *tengo.Table, cfg innodbLimitsConfig) string {
+	if rowFormat := table.RowFormat(); rowFormat != "" {
+		return "ROW_FORMAT=" + rowFormat
+	} else if cfg.defaultRowFormat != "DYNAMIC" {
+		return "innodb_default_row_format=" + cfg.defaultRowFormat
+	}
+	return ""
+}
+
+// columnRowBytes returns the maximum number of bytes that col may contribute
+// to a row, for purposes of the server's row size limit and InnoDB's record
+// size limit respectively.
+func columnRowBytes(col *tengo.Column, compactFormat bool) (serverBytes, innodbBytes uint64) {
+	if fixed, ok := columnFixedBytes(col.Type); ok {
+		return fixed, fixed
+	}
+	maxBytes, ok := columnMaxBytes(col)
+	if !ok {
+		// Treat unknown types as a pointer to off-page storage
+		return 12, 20
+	}
+	lengthBytes := uint64(1)
+	if maxBytes > 255 {
+		lengthBytes = 2
+	}
+
+	// BLOB and TEXT types (and JSON, which is stored similarly) only count
+	// their length and pointer towards the server's limit
+	var isBlob bool
+	switch col.Type.Base {
+	case "char", "varchar", "binary", "varbinary":
+		serverBytes = maxBytes + lengthBytes
+	default:
+		isBlob = true
+		serverBytes = 12
+	}
+
+	// InnoDB may store long columns off-page. With DYNAMIC or COMPRESSED row
+	// format, long columns are stored fully off-page with only a pointer in-row.
+	// With COMPACT or REDUNDANT, the first 768 bytes are stored in-row along with
+	// a pointer.
+	if maxBytes <= 40 || (maxBytes <= 255 && !isBlob) {
+		innodbBytes = maxBytes + lengthBytes
+	} else if compactFormat {
+		innodbBytes = min(maxBytes, 768+20) + lengthBytes
+	} else {
+		innodbBytes = 40 + lengthBytes
+	}
+	return serverBytes, innodbBytes
+}
+
+// columnMaxBytes returns the maximum number of bytes that may be stored in a
+// variable-length or string column.
+func columnMaxBytes(col *tengo.Column) (uint64, bool) {
+	if maxBytes, ok := col.Type.StringMaxBytes(col.CharSet); ok {
+		return maxBytes, true
+	} else if maxBytes, ok := col.Type.BinaryMaxBytes(); ok {
+		return maxBytes, true
+	} else if col.Type.Base == "json" {
+		return 4294967295, true
+	}
+	return 0, false
+}
+
+// columnFixedBytes returns the storage size of fixed-length column types. If
+// ct is not a fixed-length type, 0,false is returned.
+func columnFixedBytes(ct tengo.ColumnType) (uint64, bool) {
+	switch ct.Base {
+	case "tinyint", "year":
+		return 1, true
+	case "smallint", "enum":
+		return 2, true
+	case "mediumint", "date":
+		return 3, true
+	case "int":
+		return 4, true
+	case "bigint", "double", "set":
+		return 8, true
+	case "float":
+		if ct.Size > 24 && ct.Scale == 0 { // float(p) with p > 24 is a double
+			return 8, true
+		}
+		return 4, true
+	case "bit":
+		return (uint64(ct.Size) + 7) / 8, true
+	case "time":
+		return 3 + (uint64(ct.Size)+1)/2, true
+	case "datetime":
+		return 5 + (uint64(ct.Size)+1)/2, true
+	case "timestamp":
+		return 4 + (uint64(ct.Size)+1)/2, true
+	case "decimal":
+		// Each group of 9 digits uses 4 bytes; leftover digits use a partial group
+		leftover := []uint64{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+		precision, scale := uint64(ct.Size), uint64(ct.Scale)
+		if precision == 0 {
+			precision = 10
+		}
+		digitBytes := func(digits uint64) uint64 {
+			return (digits/9)*4 + leftover[digits%9]
+		}
+		return digitBytes(precision-scale) + digitBytes(scale), true
+	}
+	return 0, false
+}
@@ -13,35 +13,54 @@ func init() {
 		Name:            "zero-date",
 		Description:     "Flag DATE, DATETIME, and TIMESTAMP columns that have zero-date default values",
 		DefaultSeverity: SeverityWarning,
+		Fixer:           zeroDateFixer,
 	})
 }
 
 func zeroDateChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, _ *Options) []Note {
 	results := make([]Note, 0)
 	for _, col := range table.Columns {
-		if col.Type.Base == "timestamp" || strings.HasPrefix(col.Type.Base, "date") {
-			var summary, subject string
-			if strings.HasPrefix(col.Default, "'0000-00-00") {
-				summary = "Default value is zero date"
-				subject = "Zero dates"
-			} else if strings.HasPrefix(col.Default, "'0000-") || strings.Contains(col.Default, "-00") {
-				summary = "Default value contains zero in date"
-				subject = "Dates with zero year, month, or day"
-			}
-			if summary != "" {
-				// Depending on the flavor and/or use of explicit_defaults_for_timestamp,
-				// timestamp columns must explicitly be declared NULL to permit DEFAULT NULL
-				var recoNullable string
-				if col.Type.Base == "timestamp" {
-					recoNullable = "NULL "
-				}
-				results = append(results, Note{
-					LineOffset: FindColumnLineOffset(col, createStatement),
-					Summary:    summary,
-					Message:    fmt.Sprintf("Column %s of %s has a default value of %s. %s prevent use of strict sql_mode, which provides important safety checks. Consider making the column %sDEFAULT NULL instead.", col.Name, table.ObjectKey(), col.Default, subject, recoNullable),
-				})
-			}
+		summary, subject := zeroDateDefault(col)
+		if summary == "" {
+			continue
 		}
+		// Depending on the flavor and/or use of explicit_defaults_for_timestamp,
+		// timestamp columns must explicitly be declared NULL to permit DEFAULT NULL
+		var recoNullable string
+		if col.Type.Base == "timestamp" {
+			recoNullable = "NULL "
+		}
+		results = append(results, Note{
+			LineOffset: FindColumnLineOffset(col, createStatement),
+			Summary:    summary,
+			Message:    fmt.Sprintf("Column %s of %s has a default value of %s. %s prevent use of strict sql_mode, which provides important safety checks. Consider making the column %sDEFAULT NULL instead.", col.Name, table.ObjectKey(), col.Default, subject, recoNullable),
+		})
 	}
 	return results
 }
+
+// zeroDateFixer makes columns with zero-date defaults nullable, with a default
+// of NULL.
+func zeroDateFixer(table *tengo.Table, _ *Options) {
+	for _, col := range table.Columns {
+		if summary, _ := zeroDateDefault(col); summary != "" {
+			col.Nullable = true
+			col.Default = "NULL"
+		}
+	}
+}
+
+// zeroDateDefault returns a non-empty summary if col is a date-related column
+// with a default value that is a zero date, or contains zeroes in any of its
+// date components.
+func zeroDateDefault(col *tengo.Column) (summary, subject string) {
+	if col.Type.Base != "timestamp" && !strings.HasPrefix(col.Type.Base, "date") {
+		return "", ""
+	}
+	if strings.HasPrefix(col.Default, "'0000-00-00") {
+		return "Default value is zero date", "Zero dates"
+	} else if strings.HasPrefix(col.Default, "'0000-") || strings.Contains(col.Default, "-00") {
+		return "Default value contains zero in date", "Dates with zero year, month, or day"
+	}
+	return "", ""
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/shellout"
 	"github.com/skeema/skeema/internal/tengo"
 )
 
@@ -37,6 +38,8 @@ func AddCommandOptions(cmd *mybase.Command) {
 		}
 	}
 
+	cmd.AddOptions("linter rule", mybase.StringOption("lint-plugin", 0, "", "Shell command for an external linter plugin, which receives schema JSON on STDIN and returns annotations JSON on STDOUT"))
+
 	// Prior to Skeema v1.3 (Sept 2019), linter checks were configured using these
 	// two centralized list-style options, which have been deprecated since then
 	cmd.AddOptions("linter rule", mybase.StringOption("warnings", 0, "", "(deprecated and hidden)").Hidden().MarkDeprecated("This option will be removed in Skeema v2."))
@@ -49,6 +52,7 @@ type Options struct {
 	RuleConfig              map[string]any
 	StripAnnotationNewlines bool                     // if true, remove newlines inside annotation messages
 	flavor                  tengo.Flavor             // actual workspace flavor; set automatically by CheckSchema
+	plugin                  *shellout.Command        // external linter plugin, or nil if none configured
 	onlyKeys                map[tengo.ObjectKey]bool // if map is non-nil, only format objects with true values
 }
 
@@ -97,6 +101,9 @@ func (opts *Options) Equals(other *Options) bool {
 	if opts.flavor != other.flavor {
 		return false
 	}
+	if (opts.plugin == nil) != (other.plugin == nil) || (opts.plugin != nil && opts.plugin.String() != other.plugin.String()) {
+		return false
+	}
 	return true
 }
 
@@ -167,6 +174,12 @@ func OptionsForDir(dir *fs.Dir) (*Options, error) {
 		}
 	}
 
+	plugin, err := pluginCommand(dir)
+	if err != nil {
+		return nil, NewConfigError(dir, "Invalid value for option lint-plugin: %s", err)
+	}
+	opts.plugin = plugin
+
 	return opts, nil
 }
 
@@ -0,0 +1,96 @@
+package linter
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+// TableFixer is a function that modifies table in-place, in order to resolve
+// the problems found by the corresponding rule's checker. Fixers may only
+// modify top-level fields of table and fields of its columns, since they
+// operate on a copy of the table which does not copy any other nested values.
+type TableFixer func(table *tengo.Table, opts *Options)
+
+// Fix represents a rewritten CREATE TABLE statement, which resolves problems
+// found by one or more rules that support automatic fixes.
+type Fix struct {
+	Statement *tengo.Statement
+	Table     *tengo.Table // modified copy of the table from the workspace
+	RuleNames []string
+}
+
+// Text returns the new CREATE TABLE statement for the fixed table.
+func (fix Fix) Text(flavor tengo.Flavor) string {
+	return fix.Table.GeneratedCreateStatement(flavor)
+}
+
+// FixSchema runs the fixers of all enabled rules that support automatic fixes,
+// for tables in wsSchema which have problems found by those rules. Problems
+// which are suppressed by skeema:lint-ignore comments are not fixed. The tables
+// in wsSchema are not modified. Returned fixes are ordered by table name.
+func FixSchema(wsSchema *workspace.Schema, opts *Options) []Fix {
+	opts.flavor = wsSchema.Flavor
+	var fixes []Fix
+	for _, table := range wsSchema.Tables {
+		stmt := wsSchema.LogicalSchema.Creates[table.ObjectKey()]
+		if stmt == nil || table.UnsupportedDDL || opts.shouldIgnore(table) {
+			continue
+		}
+		var fix *Fix
+		for _, ruleName := range sortedRuleNames(opts) {
+			r := rulesByName[ruleName]
+			if r.Fixer == nil || !hasUnsuppressedNotes(r, table, stmt, wsSchema.Schema, opts) {
+				continue
+			}
+			if fix == nil {
+				fix = &Fix{Statement: stmt, Table: copyTableForFix(table)}
+			}
+			r.Fixer(fix.Table, opts)
+			fix.RuleNames = append(fix.RuleNames, ruleName)
+		}
+		if fix != nil && fix.Text(opts.flavor) != table.GeneratedCreateStatement(opts.flavor) {
+			fixes = append(fixes, *fix)
+		}
+	}
+	slices.SortFunc(fixes, func(a, b Fix) int {
+		return strings.Compare(a.Table.Name, b.Table.Name)
+	})
+	return fixes
+}
+
+// sortedRuleNames returns the names of all rules which are not ignored in opts,
+// in alphabetical order.
+func sortedRuleNames(opts *Options) []string {
+	names := make([]string, 0, len(opts.RuleSeverity))
+	for name, severity := range opts.RuleSeverity {
+		if severity != SeverityIgnore {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func hasUnsuppressedNotes(r *Rule, table *tengo.Table, stmt *tengo.Statement, schema *tengo.Schema, opts *Options) bool {
+	for _, note := range r.CheckerFunc.CheckObject(table, stmt.Text, schema, opts) {
+		if matchingLintIgnore(stmt, r.Name, note.LineOffset) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// copyTableForFix returns a copy of table, which includes copies of its
+// columns.
+func copyTableForFix(table *tengo.Table) *tengo.Table {
+	result := *table
+	result.Columns = make([]*tengo.Column, len(table.Columns))
+	for n, col := range table.Columns {
+		colCopy := *col
+		result.Columns[n] = &colCopy
+	}
+	return &result
+}
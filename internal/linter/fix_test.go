@@ -0,0 +1,94 @@
+package linter
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestFixSchema(t *testing.T) {
+	dirPath := t.TempDir()
+	contents := "CREATE TABLE broken (\n" +
+		"  id int(5) unsigned NOT NULL,\n" +
+		"  name varchar(30) CHARACTER SET utf8mb3 NOT NULL,\n" +
+		"  created_at datetime NOT NULL DEFAULT '0000-00-00 00:00:00',\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=MyISAM DEFAULT CHARSET=latin1;\n" +
+		"CREATE TABLE ignored (\n" +
+		"  id int(5) unsigned NOT NULL, -- skeema:lint-ignore display-width\n" +
+		"  price float NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1;\n"
+	fs.WriteTestFile(t, filepath.Join(dirPath, "tables.sql"), contents)
+	fs.WriteTestFile(t, filepath.Join(dirPath, ".skeema"), "allow-charset=latin1,utf8mb4\nallow-engine=innodb\n")
+	dir := getDir(t, dirPath)
+	forceOnlyRulesWarning(dir.Config, "display-width", "charset", "engine", "zero-date", "has-float")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+
+	broken := &tengo.Table{
+		Name:    "broken",
+		Engine:  "MyISAM",
+		CharSet: "latin1",
+		Columns: []*tengo.Column{
+			{Name: "id", Type: tengo.ParseColumnType("int(5) unsigned")},
+			{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), CharSet: "utf8mb3", Collation: "utf8mb3_general_ci", ShowCharSet: true},
+			{Name: "created_at", Type: tengo.ParseColumnType("datetime"), Default: "'0000-00-00 00:00:00'"},
+		},
+		PrimaryKey: &tengo.Index{Name: "PRIMARY", Parts: []tengo.IndexPart{{ColumnName: "id"}}, PrimaryKey: true, Unique: true},
+	}
+	ignored := &tengo.Table{
+		Name:    "ignored",
+		Engine:  "InnoDB",
+		CharSet: "latin1",
+		Columns: []*tengo.Column{
+			{Name: "id", Type: tengo.ParseColumnType("int(5) unsigned")},
+			{Name: "price", Type: tengo.ParseColumnType("float")},
+		},
+		PrimaryKey: &tengo.Index{Name: "PRIMARY", Parts: []tengo.IndexPart{{ColumnName: "id"}}, PrimaryKey: true, Unique: true},
+	}
+	origBrokenCreate := broken.GeneratedCreateStatement(tengo.FlavorUnknown)
+	wsSchema := &workspace.Schema{
+		Schema:        &tengo.Schema{Tables: []*tengo.Table{broken, ignored}},
+		LogicalSchema: dir.LogicalSchemas[0],
+	}
+
+	fixes := FixSchema(wsSchema, opts)
+	if len(fixes) != 1 {
+		t.Fatalf("Expected 1 fix, instead found %d: %+v", len(fixes), fixes)
+	}
+	fix := fixes[0]
+	expectedRules := []string{"charset", "display-width", "engine", "zero-date"}
+	if fix.Table.Name != "broken" || !slices.Equal(fix.RuleNames, expectedRules) {
+		t.Errorf("Unexpected fix: table %s, rules %v", fix.Table.Name, fix.RuleNames)
+	}
+	expectedCreate := "CREATE TABLE `broken` (\n" +
+		"  `id` int(10) unsigned NOT NULL,\n" +
+		"  `name` varchar(30) CHARACTER SET utf8mb4 NOT NULL,\n" +
+		"  `created_at` datetime DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=innodb DEFAULT CHARSET=latin1"
+	if actual := fix.Text(tengo.FlavorUnknown); actual != expectedCreate {
+		t.Errorf("Unexpected fixed CREATE statement:\nExpected:\n%s\nActual:\n%s", expectedCreate, actual)
+	}
+
+	// The original table in the workspace schema must not be modified
+	if broken.GeneratedCreateStatement(tengo.FlavorUnknown) != origBrokenCreate {
+		t.Error("FixSchema unexpectedly modified the original table")
+	}
+
+	// With all fixable rules ignored, no fixes should be returned
+	forceOnlyRulesWarning(dir.Config, "has-float")
+	if opts, err = OptionsForDir(dir); err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	if fixes := FixSchema(wsSchema, opts); len(fixes) != 0 {
+		t.Errorf("Expected no fixes, instead found %d", len(fixes))
+	}
+}
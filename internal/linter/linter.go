@@ -24,6 +24,18 @@ func CheckSchema(wsSchema *workspace.Schema, opts *Options) *Result {
 	result := &Result{}
 	objects := wsSchema.Objects()
 
+	// Run the external linter plugin, if one is configured. Its annotations are
+	// handled alongside those of the built-in rules below, so that they may also
+	// be suppressed by skeema:lint-ignore comments.
+	var pluginAnnotations map[tengo.ObjectKey][]PluginAnnotation
+	if opts.plugin != nil {
+		var err error
+		if pluginAnnotations, err = runPlugin(opts.plugin, wsSchema.Schema); err != nil {
+			result.Fatal(err)
+			return result
+		}
+	}
+
 	for key, stmt := range wsSchema.LogicalSchema.Creates {
 		// Attempt to look up the corresponding object. Might not be found if there
 		// was a syntax error in its creation SQL though.
@@ -31,29 +43,66 @@ func CheckSchema(wsSchema *workspace.Schema, opts *Options) *Result {
 		if !ok || opts.shouldIgnore(object) {
 			continue
 		}
+		usedIgnores := make([]bool, len(stmt.LintIgnores))
+		annotate := func(ruleName string, severity Severity, lo Note) {
+			if n := matchingLintIgnore(stmt, ruleName, lo.LineOffset); n >= 0 {
+				usedIgnores[n] = true
+				result.Debug("Suppressed %s problem at %s:%d due to skeema:lint-ignore comment", ruleName, stmt.File, stmt.LineNo+lo.LineOffset)
+				return
+			}
+
+			// Capitalize first letter of message -- this helps with checkers that start
+			// their message using ObjectKey.String(), which doesn't capitalize its
+			// return value. So this converts "table `foo`" to "Table `foo`".
+			firstRune, runeLen := utf8.DecodeRuneInString(lo.Message)
+			lo.Message = string(unicode.ToUpper(firstRune)) + lo.Message[runeLen:]
+
+			if opts.StripAnnotationNewlines {
+				lo.Message = strings.ReplaceAll(lo.Message, "\n", " ")
+			}
+			result.Annotate(stmt, severity, ruleName, lo)
+		}
 		for ruleName, severity := range opts.RuleSeverity {
 			if severity == SeverityIgnore {
 				continue
 			}
 			r := rulesByName[ruleName]
-			output := r.CheckerFunc.CheckObject(object, stmt.Text, wsSchema.Schema, opts)
-			for _, lo := range output {
-				// Capitalize first letter of message -- this helps with checkers that start
-				// their message using ObjectKey.String(), which doesn't capitalize its
-				// return value. So this converts "table `foo`" to "Table `foo`".
-				firstRune, runeLen := utf8.DecodeRuneInString(lo.Message)
-				lo.Message = string(unicode.ToUpper(firstRune)) + lo.Message[runeLen:]
-
-				if opts.StripAnnotationNewlines {
-					lo.Message = strings.ReplaceAll(lo.Message, "\n", " ")
+			for _, lo := range r.CheckerFunc.CheckObject(object, stmt.Text, wsSchema.Schema, opts) {
+				annotate(ruleName, severity, lo)
+			}
+		}
+		for _, pa := range pluginAnnotations[key] {
+			annotate(pa.Rule, pa.Severity, Note{LineOffset: pa.LineOffset, Summary: pa.Summary, Message: pa.Message})
+		}
+
+		// Warn about suppressions which didn't match any problems, since these are
+		// likely obsolete
+		for n, li := range stmt.LintIgnores {
+			if !usedIgnores[n] {
+				note := Note{
+					LineOffset: li.LineNo - stmt.LineNo,
+					Summary:    "Unused lint-ignore comment",
+					Message:    fmt.Sprintf("Comment \"%s\" does not suppress any problems in %s, and may be removed.", strings.TrimPrefix(li.String(), "-- "), key),
 				}
-				result.Annotate(stmt, severity, ruleName, lo)
+				result.Annotate(stmt, SeverityWarning, "lint-ignore", note)
 			}
 		}
 	}
 	return result
 }
 
+// matchingLintIgnore returns the index of the first element of
+// stmt.LintIgnores which suppresses the supplied rule at the supplied line
+// offset, or -1 if none do.
+func matchingLintIgnore(stmt *tengo.Statement, ruleName string, lineOffset int) int {
+	for n, li := range stmt.LintIgnores {
+		if li.Matches(ruleName, lineOffset) {
+			return n
+		}
+	}
+	return -1
+}
+
 // ObjectChecker values may be used to check for problems in database objects.
 type ObjectChecker interface {
 	CheckObject(object tengo.DefKeyer, createStatement string, schema *tengo.Schema, opts *Options) []Note
@@ -128,7 +177,8 @@ type Rule struct {
 	DefaultSeverity Severity
 	RelatedOption   *mybase.Option // for rules that have supplemental options, e.g. list of allowed values
 	ConfigFunc      RuleConfigFunc
-	Deprecation     string // if non-blank, rule is deprecated
+	Fixer           TableFixer // for rules that support automatic fixes with `skeema lint --fix`
+	Deprecation     string     // if non-blank, rule is deprecated
 }
 
 // RelatedListOption populates RelatedOption and ConfigFunc by creating a
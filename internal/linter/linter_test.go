@@ -374,6 +374,115 @@ func (s LinterIntegrationSuite) TestCheckSchemaSpatialIndexSRID(t *testing.T) {
 	compareAnnotations(t, expected, result)
 }
 
+// TestCheckSchemaNaming runs the naming convention rules against the dir
+// ./testdata/naming, which uses the default patterns except for
+// name-fk-pattern. See expectedAnnotations() for more information.
+func (s LinterIntegrationSuite) TestCheckSchemaNaming(t *testing.T) {
+	dir := getDir(t, "testdata/naming")
+	forceOnlyRulesWarning(dir.Config, "name-table", "name-column", "name-index", "name-unique", "name-fk", "name-check")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+
+	logicalSchema := dir.LogicalSchemas[0]
+	wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
+	} else if len(wsSchema.Failures) != 0 {
+		t.Fatalf("Unexpectedly found %d workspace failures", len(wsSchema.Failures))
+	}
+
+	result := CheckSchema(wsSchema, opts)
+	expected := expectedAnnotations(logicalSchema, s.d.Flavor())
+	compareAnnotations(t, expected, result)
+	for _, a := range result.Annotations {
+		if a.RuleName == "name-fk" && !strings.Contains(a.Message, "expected name is `fk_customers`") {
+			t.Errorf("Expected name-fk message to include expected name, instead found: %s", a.Message)
+		}
+	}
+}
+
+// TestCheckSchemaInnoDBLimits runs the row-size and key-length rules against
+// the dir ./testdata/innodblimits, which configures innodb-default-row-format
+// to differ from the server's default. Tables lacking ROW_FORMAT are therefore
+// created successfully in the workspace, but are still flagged by the linter.
+// See expectedAnnotations() for more information.
+func (s LinterIntegrationSuite) TestCheckSchemaInnoDBLimits(t *testing.T) {
+	dir := getDir(t, "testdata/innodblimits")
+	forceOnlyRulesWarning(dir.Config, "row-size", "key-length")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+
+	logicalSchema := dir.LogicalSchemas[0]
+	wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
+	} else if len(wsSchema.Failures) != 0 {
+		t.Fatalf("Unexpectedly found %d workspace failures", len(wsSchema.Failures))
+	}
+
+	result := CheckSchema(wsSchema, opts)
+	expected := expectedAnnotations(logicalSchema, s.d.Flavor())
+	compareAnnotations(t, expected, result)
+	for _, a := range result.Annotations {
+		exceeds := strings.Contains(a.Summary, "exceeds")
+		if usesDefault := a.Statement.ObjectName == "toowide" || a.Statement.ObjectName == "compactkey"; usesDefault != exceeds {
+			t.Errorf("Unexpected summary %q for table %s", a.Summary, a.Statement.ObjectName)
+		} else if usesDefault && !strings.Contains(a.Message, "innodb_default_row_format=COMPACT") {
+			t.Errorf("Expected message for table %s to mention the default row format, instead found: %s", a.Statement.ObjectName, a.Message)
+		}
+	}
+}
+
+// TestNamingPatternInvalid confirms that invalid regular expressions in the
+// naming convention options are rejected.
+func TestNamingPatternInvalid(t *testing.T) {
+	dir := getDir(t, "testdata/naming")
+	forceOnlyRulesWarning(dir.Config, "name-check")
+	if _, err := OptionsForDir(dir); err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	dir.Config.SetRuntimeOverride("name-check-pattern", "chk_(")
+	if _, err := OptionsForDir(dir); err == nil {
+		t.Error("Expected error from OptionsForDir with invalid name-check-pattern, but err was nil")
+	}
+}
+
+// TestInnoDBLimitsConfig confirms that the supplemental configuration of the
+// row-size and key-length rules reflects options innodb-page-size and
+// innodb-default-row-format, and that invalid values are rejected.
+func TestInnoDBLimitsConfig(t *testing.T) {
+	dir := getDir(t, "testdata/innodblimits")
+	forceOnlyRulesWarning(dir.Config, "row-size", "key-length")
+	dir.Config.SetRuntimeOverride("innodb-page-size", "8k")
+	expectCfg := innodbLimitsConfig{pageSizeKB: 8, defaultRowFormat: "COMPACT"}
+	if opts, err := OptionsForDir(dir); err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	} else if opts.RuleConfig["row-size"] != expectCfg || opts.RuleConfig["key-length"] != expectCfg {
+		t.Errorf("Unexpected InnoDB limits in rule config: %+v", opts.RuleConfig)
+	}
+	dir.Config.SetRuntimeOverride("innodb-page-size", "12k")
+	if _, err := OptionsForDir(dir); err == nil {
+		t.Error("Expected error from OptionsForDir with invalid innodb-page-size, but err was nil")
+	}
+	dir.Config.SetRuntimeOverride("innodb-page-size", "16k")
+	dir.Config.SetRuntimeOverride("innodb-default-row-format", "compressed")
+	if _, err := OptionsForDir(dir); err == nil {
+		t.Error("Expected error from OptionsForDir with invalid innodb-default-row-format, but err was nil")
+	}
+}
+
 func TestRegisterRuleDuplicate(t *testing.T) {
 	rule := Rule{
 		Name:            "fake-test-rule",
@@ -408,9 +517,9 @@ func getDir(t *testing.T, dirPath string, cliArgs ...string) *fs.Dir {
 	workspace.AddCommandOptions(cmd)
 	AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
-	commandLine := "lintertest"
+	commandLine := "lintertest --skip-workspace-cache"
 	if len(cliArgs) > 0 {
-		commandLine = fmt.Sprintf("lintertest %s", strings.Join(cliArgs, " "))
+		commandLine = fmt.Sprintf("%s %s", commandLine, strings.Join(cliArgs, " "))
 	}
 	cfg := mybase.ParseFakeCLI(t, cmd, commandLine)
 	dir, err := fs.ParseDir(dirPath, cfg)
@@ -0,0 +1,83 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/shellout"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// PluginAnnotation is the JSON representation of a problem reported by an
+// external linter plugin. Plugins are configured using option lint-plugin.
+// They receive the workspace schema as JSON on STDIN, and must write a JSON
+// array of PluginAnnotation values to STDOUT.
+type PluginAnnotation struct {
+	Type       tengo.ObjectType `json:"type"` // defaults to "table" if omitted
+	Name       string           `json:"name"`
+	LineOffset int              `json:"lineOffset,omitempty"`
+	Severity   Severity         `json:"severity,omitempty"` // defaults to "warning" if omitted
+	Rule       string           `json:"rule,omitempty"`     // defaults to "plugin" if omitted
+	Summary    string           `json:"summary"`
+	Message    string           `json:"message"`
+}
+
+// pluginCommand returns the shellout.Command configured by option lint-plugin,
+// or nil if the option is not set.
+func pluginCommand(dir *fs.Dir) (*shellout.Command, error) {
+	commandLine := dir.Config.Get("lint-plugin")
+	if commandLine == "" {
+		return nil, nil
+	}
+	variables := map[string]string{
+		"ENVIRONMENT": dir.Config.Get("environment"),
+		"DIRNAME":     dir.BaseName(),
+		"DIRPATH":     dir.Path,
+		"SCHEMA":      dir.Config.GetAllowEnvVar("schema"),
+	}
+	return shellout.New(commandLine).WithVariables(variables)
+}
+
+// runPlugin executes the plugin command, supplying schema as JSON on STDIN,
+// and returns the plugin's annotations grouped by object. Annotations for
+// objects which don't exist in schema are discarded.
+func runPlugin(command *shellout.Command, schema *tengo.Schema) (map[tengo.ObjectKey][]PluginAnnotation, error) {
+	input, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	output, err := command.WithStdin(bytes.NewReader(input)).RunCapture()
+	if err != nil {
+		return nil, fmt.Errorf("lint-plugin command %s failed: %w", command, err)
+	}
+	var annotations []PluginAnnotation
+	if err := json.Unmarshal([]byte(output), &annotations); err != nil {
+		return nil, fmt.Errorf("lint-plugin command %s returned invalid JSON: %w", command, err)
+	}
+	objects := schema.Objects()
+	result := make(map[tengo.ObjectKey][]PluginAnnotation)
+	for _, pa := range annotations {
+		if pa.Type == tengo.ObjectTypeNil {
+			pa.Type = tengo.ObjectTypeTable
+		}
+		if pa.Rule == "" {
+			pa.Rule = "plugin"
+		}
+		switch pa.Severity {
+		case "":
+			pa.Severity = SeverityWarning
+		case SeverityWarning, SeverityError:
+		case SeverityIgnore:
+			continue
+		default:
+			return nil, fmt.Errorf("lint-plugin command %s returned invalid severity %q for %s %s", command, pa.Severity, pa.Type, tengo.EscapeIdentifier(pa.Name))
+		}
+		key := tengo.ObjectKey{Type: pa.Type, Name: pa.Name}
+		if _, ok := objects[key]; ok {
+			result[key] = append(result[key], pa)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package linter
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestCheckSchemaPlugin(t *testing.T) {
+	dirPath := t.TempDir()
+	contents := "CREATE TABLE foo (\n" +
+		"  id int NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n" +
+		"-- skeema:lint-ignore timestamps\n" +
+		"CREATE TABLE bar (\n" +
+		"  id int NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		");\n"
+	fs.WriteTestFile(t, filepath.Join(dirPath, "tables.sql"), contents)
+
+	// The plugin saves its input for inspection, and then reports problems on
+	// both tables, along with a nonexistent table which should be discarded
+	output := []PluginAnnotation{
+		{Name: "foo", LineOffset: 1, Rule: "timestamps", Summary: "Missing timestamp columns", Message: "table `foo` lacks created_at"},
+		{Name: "bar", Rule: "timestamps", Summary: "Missing timestamp columns", Message: "table `bar` lacks created_at"},
+		{Type: tengo.ObjectTypeTable, Name: "foo", Severity: SeverityError, Summary: "Bad table", Message: "table `foo` is bad"},
+		{Name: "doesnt_exist", Summary: "Missing timestamp columns", Message: "whatever"},
+	}
+	outputJSON, _ := json.Marshal(output)
+	fs.WriteTestFile(t, filepath.Join(dirPath, "output.json"), string(outputJSON))
+	inputPath := filepath.Join(dirPath, "input.json")
+	dir := getDir(t, dirPath)
+	forceOnlyRulesWarning(dir.Config)
+	dir.Config.SetRuntimeOverride("lint-plugin", "cat > "+inputPath+" && cat {DIRPATH}/output.json")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	makeTable := func(name string) *tengo.Table {
+		return &tengo.Table{Name: name, Columns: []*tengo.Column{{Name: "id", Type: tengo.ParseColumnType("int")}}}
+	}
+	wsSchema := &workspace.Schema{
+		Schema:        &tengo.Schema{Name: "whatever", Tables: []*tengo.Table{makeTable("foo"), makeTable("bar")}},
+		LogicalSchema: dir.LogicalSchemas[0],
+	}
+
+	result := CheckSchema(wsSchema, opts)
+	result.SortByFile()
+	if len(result.Exceptions) > 0 {
+		t.Fatalf("Unexpected exceptions: %v", result.Exceptions)
+	}
+	if len(result.Annotations) != 2 || result.ErrorCount != 1 || result.WarningCount != 1 {
+		t.Fatalf("Unexpected annotations: %+v", result.Annotations)
+	}
+	if a := result.Annotations[0]; a.RuleName != "plugin" || a.LineNo() != 1 || a.Severity != SeverityError || a.Message != "Table `foo` is bad" {
+		t.Errorf("Unexpected first annotation: %s %s", a.RuleName, a.MessageWithLocation())
+	}
+	if a := result.Annotations[1]; a.RuleName != "timestamps" || a.LineNo() != 2 || a.Severity != SeverityWarning {
+		t.Errorf("Unexpected second annotation: %s %s", a.RuleName, a.MessageWithLocation())
+	}
+	var input tengo.Schema
+	if err := json.Unmarshal([]byte(fs.ReadTestFile(t, inputPath)), &input); err != nil {
+		t.Errorf("Unable to unmarshal plugin input: %v", err)
+	} else if input.Name != "whatever" || len(input.Tables) != 2 {
+		t.Errorf("Unexpected plugin input: %+v", input)
+	}
+
+	// Invalid output, or failure of the command, should result in an exception
+	fs.WriteTestFile(t, filepath.Join(dirPath, "badseverity.json"), `[{"name": "foo", "severity": "fatal"}]`)
+	for _, command := range []string{"echo not-json", "false", "cat {DIRPATH}/badseverity.json"} {
+		dir.Config.SetRuntimeOverride("lint-plugin", command)
+		if opts, err = OptionsForDir(dir); err != nil {
+			t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+		}
+		if result := CheckSchema(wsSchema, opts); len(result.Exceptions) != 1 {
+			t.Errorf("Expected 1 exception with lint-plugin=%q, instead found %d", command, len(result.Exceptions))
+		} else if !strings.Contains(result.Exceptions[0].Error(), "lint-plugin") {
+			t.Errorf("Unexpected exception with lint-plugin=%q: %v", command, result.Exceptions[0])
+		}
+	}
+
+	// Unknown variables in the command are a config error
+	dir.Config.SetRuntimeOverride("lint-plugin", "echo {NOPE}")
+	if _, err := OptionsForDir(dir); err == nil {
+		t.Error("Expected error from OptionsForDir with invalid lint-plugin, but err was nil")
+	}
+}
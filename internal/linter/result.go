@@ -132,6 +132,7 @@ type Result struct {
 	ErrorCount    int
 	WarningCount  int
 	ReformatCount int
+	WrittenFiles  []string // paths of *.sql files written by fixes or reformatting
 }
 
 // Annotate constructs an annotation on the supplied statement, and stores it
@@ -251,6 +252,7 @@ func (r *Result) Merge(other *Result) {
 	r.ErrorCount += other.ErrorCount
 	r.WarningCount += other.WarningCount
 	r.ReformatCount += other.ReformatCount
+	r.WrittenFiles = append(r.WrittenFiles, other.WrittenFiles...)
 }
 
 // SortByFile sorts the error, warning and format notice messages according
@@ -43,7 +43,7 @@ func TestResultMerge(t *testing.T) {
 	r1.Debug("hello world")
 	r1.Debug("debug debug")
 
-	r2 := &Result{ReformatCount: 3}
+	r2 := &Result{ReformatCount: 3, WrittenFiles: []string{"foo.sql"}}
 	r2.Annotate(nil, SeverityWarning, "", Note{})
 	r1.Annotate(nil, SeverityError, "", Note{})
 	r2.Debug("something unimportant")
@@ -51,7 +51,7 @@ func TestResultMerge(t *testing.T) {
 
 	r1.Merge(nil) // should be a no-op
 	r1.Merge(r2)
-	if len(r1.Annotations) != 5 || len(r1.DebugLogs) != 3 || len(r1.Exceptions) != 1 {
+	if len(r1.Annotations) != 5 || len(r1.DebugLogs) != 3 || len(r1.Exceptions) != 1 || len(r1.WrittenFiles) != 1 {
 		t.Errorf("Unexpected slice counts in %+v", *r1)
 	}
 	if r1.ErrorCount != 3 || r1.WarningCount != 2 || r1.ReformatCount != 3 {
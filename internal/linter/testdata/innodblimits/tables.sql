@@ -0,0 +1,70 @@
+CREATE TABLE wide ( /* annotations: row-size */
+  id bigint unsigned NOT NULL,
+  a varchar(8000) NOT NULL,
+  b varchar(8000) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE nearlywide ( /* annotations: row-size */
+  id bigint unsigned NOT NULL,
+  c0 varchar(1000) NOT NULL,
+  c1 varchar(1000) NOT NULL,
+  c2 varchar(1000) NOT NULL,
+  c3 varchar(1000) NOT NULL,
+  c4 varchar(1000) NOT NULL,
+  c5 varchar(1000) NOT NULL,
+  c6 varchar(1000) NOT NULL,
+  c7 varchar(1000) NOT NULL,
+  c8 varchar(1000) NOT NULL,
+  c9 varchar(1000) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1 ROW_FORMAT=COMPACT;
+
+CREATE TABLE offpage (
+  id bigint unsigned NOT NULL,
+  c0 text NOT NULL,
+  c1 text NOT NULL,
+  c2 text NOT NULL,
+  c3 text NOT NULL,
+  c4 text NOT NULL,
+  c5 text NOT NULL,
+  c6 text NOT NULL,
+  c7 text NOT NULL,
+  c8 text NOT NULL,
+  c9 text NOT NULL,
+  c10 text NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE toowide ( /* annotations: row-size */
+  id bigint unsigned NOT NULL,
+  c0 text NOT NULL,
+  c1 text NOT NULL,
+  c2 text NOT NULL,
+  c3 text NOT NULL,
+  c4 text NOT NULL,
+  c5 text NOT NULL,
+  c6 text NOT NULL,
+  c7 text NOT NULL,
+  c8 text NOT NULL,
+  c9 text NOT NULL,
+  c10 text NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
+
+CREATE TABLE compactkey (
+  id bigint unsigned NOT NULL,
+  name varchar(255) NOT NULL,
+  notes text NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_name (name), /* annotations: key-length */
+  KEY notes (notes(100))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE longkey (
+  id bigint unsigned NOT NULL,
+  a varchar(400) NOT NULL,
+  b varchar(300) NOT NULL,
+  PRIMARY KEY (id),
+  KEY ab (a, b) /* annotations: key-length */
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1,17 @@
+CREATE TABLE customers (
+  id int NOT NULL,
+  email varchar(100) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_email (email)
+) ENGINE=InnoDB;
+
+CREATE TABLE Orders ( /* annotations: name-table */
+  id int NOT NULL,
+  customerId int NOT NULL, /* annotations: name-column */
+  code char(8) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_code (code),
+  KEY cust (customerId), /* annotations: name-index */
+  CONSTRAINT orders_customer FOREIGN KEY (customerId) REFERENCES customers (id), /* annotations: name-fk */
+  CONSTRAINT chk_code CHECK (code <> '')
+) ENGINE=InnoDB;
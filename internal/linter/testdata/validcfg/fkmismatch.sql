@@ -0,0 +1,21 @@
+CREATE TABLE mismatchparent (
+  id int unsigned NOT NULL,
+  code varchar(20) COLLATE utf8mb4_bin NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE mismatchchild (
+  id int unsigned NOT NULL,
+  parent_id int(10) unsigned NOT NULL,
+  same_code varchar(40) COLLATE utf8mb4_bin NOT NULL,
+  fixed_code char(20) COLLATE utf8mb4_bin NOT NULL,
+  PRIMARY KEY (id),
+  KEY parent (parent_id),
+  KEY same_code (same_code),
+  KEY fixed_code (fixed_code),
+  CONSTRAINT fk_ok FOREIGN KEY (parent_id) REFERENCES mismatchparent (id), /* annotations: has-fk */
+  CONSTRAINT fk_ok_code FOREIGN KEY (same_code) REFERENCES mismatchparent (code),
+  CONSTRAINT fk_fixed_code FOREIGN KEY (fixed_code) REFERENCES mismatchparent (code), /* annotations: fk-mismatch */
+  CONSTRAINT fk_elsewhere FOREIGN KEY (parent_id) REFERENCES elsewhere.mismatchparent (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
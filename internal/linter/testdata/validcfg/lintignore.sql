@@ -0,0 +1,18 @@
+CREATE TABLE lintignoreline (
+  id int unsigned NOT NULL,
+  price float NOT NULL, -- skeema:lint-ignore has-float
+  cost double NOT NULL, /* annotations: has-float */
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- skeema:lint-ignore has-float
+CREATE TABLE lintignorestmt (
+  id int unsigned NOT NULL,
+  amount float NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE lintignoreunused (
+  id int unsigned NOT NULL, /* annotations: lint-ignore */ -- skeema:lint-ignore has-enum
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
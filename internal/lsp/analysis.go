@@ -0,0 +1,170 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/skeema/skeema/internal/dumper"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+// analysis is the result of executing and linting a directory's *.sql files,
+// as they exist in the filesystem.
+type analysis struct {
+	dir         *fs.Dir
+	flavor      tengo.Flavor
+	schemas     map[string]*schemaAnalysis // keyed by logical schema name
+	diagnostics map[string][]Diagnostic    // keyed by absolute file path
+	exceptions  []error                    // fatal errors which prevented full analysis
+}
+
+// schemaAnalysis is the portion of an analysis for a single logical schema.
+type schemaAnalysis struct {
+	logicalSchema *fs.LogicalSchema
+	schema        *tengo.Schema
+	canonical     map[tengo.ObjectKey]string // canonical CREATE for each object which executed successfully
+}
+
+// analyzeDir executes each logical schema in dir in a workspace, and lints
+// the result. Problems preventing analysis of the entire dir are returned as
+// an error; problems limited to one logical schema are tracked in the
+// analysis's exceptions instead.
+func analyzeDir(dir *fs.Dir) (*analysis, error) {
+	if dir.ParseError != nil {
+		return nil, dir.ParseError
+	}
+	opts, err := linter.OptionsForDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	a := &analysis{
+		dir:         dir,
+		schemas:     make(map[string]*schemaAnalysis, len(dir.LogicalSchemas)),
+		diagnostics: make(map[string][]Diagnostic),
+	}
+	if len(dir.LogicalSchemas) == 0 {
+		return a, nil
+	}
+
+	wsOpts, err := workspace.OptionsForDirFirstInstance(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &linter.Result{}
+	for _, logicalSchema := range dir.LogicalSchemas {
+		wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
+		if err != nil {
+			result.Fatal(err)
+			continue
+		}
+		a.flavor = wsSchema.Flavor
+		result.AnnotateStatementErrors(wsSchema.Failures, opts)
+		result.Merge(linter.CheckSchema(wsSchema, opts))
+
+		sa := &schemaAnalysis{
+			logicalSchema: logicalSchema,
+			schema:        wsSchema.Schema,
+			canonical:     make(map[tengo.ObjectKey]string),
+		}
+		dumpOpts := dumper.Options{IncludeAutoInc: true}
+		for key, object := range wsSchema.Objects() {
+			if stmt := logicalSchema.Creates[key]; stmt != nil {
+				sa.canonical[key] = dumper.CanonicalCreate(object, stmt, dumpOpts)
+			}
+		}
+		for _, key := range wsSchema.FailedKeys() {
+			delete(sa.canonical, key)
+		}
+		a.schemas[logicalSchema.Name] = sa
+	}
+	result.AnnotateMixedSchemaNames(dir, opts)
+	if len(result.Exceptions) == 0 {
+		for _, stmt := range dir.UnparsedStatements {
+			note := linter.Note{
+				Summary: "Unable to parse statement",
+				Message: "Ignoring unsupported or unparseable SQL statement",
+			}
+			result.Annotate(stmt, linter.SeverityWarning, "", note)
+		}
+	}
+	a.exceptions = result.Exceptions
+
+	for _, annotation := range result.Annotations {
+		a.addDiagnostic(annotation)
+	}
+	return a, nil
+}
+
+// addDiagnostic converts annotation to a Diagnostic, and tracks it for the
+// annotation's file.
+func (a *analysis) addDiagnostic(annotation *linter.Annotation) {
+	stmt := annotation.Statement
+	if stmt.File == "" || stmt.LineNo == 0 {
+		return
+	}
+	severity := DiagnosticSeverityWarning
+	if annotation.Severity == linter.SeverityError {
+		severity = DiagnosticSeverityError
+	}
+
+	// The range spans the problematic line of the statement. The first line of
+	// the statement begins at the statement's character offset.
+	lines := strings.Split(stmt.Text, "\n")
+	var lineText string
+	if annotation.LineOffset >= 0 && annotation.LineOffset < len(lines) {
+		lineText = strings.TrimRight(lines[annotation.LineOffset], "\r")
+	}
+	rng := Range{Start: Position{Line: annotation.LineNo() - 1}}
+	if annotation.LineOffset == 0 && stmt.CharNo > 1 {
+		rng.Start.Character = stmt.CharNo - 1
+	}
+	rng.End = Position{Line: rng.Start.Line, Character: rng.Start.Character + utf16Len(lineText)}
+
+	a.diagnostics[stmt.File] = append(a.diagnostics[stmt.File], Diagnostic{
+		Range:    rng,
+		Severity: severity,
+		Code:     annotation.RuleName,
+		Source:   "skeema",
+		Message:  annotation.Message,
+	})
+}
+
+// hasFile returns true if the analyzed dir includes the supplied *.sql file.
+func (a *analysis) hasFile(filePath string) bool {
+	return dirHasFile(a.dir, filePath)
+}
+
+// dirHasFile returns true if dir includes the supplied *.sql file, either
+// directly or in a subdir used by its layout.
+func dirHasFile(dir *fs.Dir, filePath string) bool {
+	for _, sqlFile := range dir.SQLFiles {
+		if sqlFile.FilePath == filePath {
+			return true
+		}
+	}
+	return false
+}
+
+// table returns the table with the supplied name from the logical schema with
+// the supplied name, or nil if no such table exists in the analysis.
+func (a *analysis) table(schemaName, tableName string) *tengo.Table {
+	if sa := a.schemas[schemaName]; sa != nil {
+		return sa.schema.Table(tableName)
+	}
+	return nil
+}
+
+// createStatement returns the filesystem CREATE statement for the supplied
+// object in the logical schema with the supplied name, or nil if no such
+// statement exists in the analysis.
+func (a *analysis) createStatement(schemaName string, key tengo.ObjectKey) *tengo.Statement {
+	for _, logicalSchema := range a.dir.LogicalSchemas {
+		if logicalSchema.Name == schemaName {
+			return logicalSchema.Creates[key]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// statementAndWordAt parses text, and returns the statement containing pos,
+// along with the identifier at pos and the full line of text containing pos.
+// The statement is nil if pos is outside of all statements, or if text cannot
+// be parsed. The identifier is unquoted, and blank if pos is not located on an
+// identifier.
+func statementAndWordAt(text string, pos Position) (stmt *tengo.Statement, word, line string) {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return nil, "", ""
+	}
+	line = strings.TrimRight(lines[pos.Line], "\r")
+	col := byteOffset(line, pos.Character)
+	statements, err := tengo.ParseStatementsInString(text)
+	if err != nil {
+		return nil, "", line
+	}
+	for _, candidate := range statements {
+		startLine, startCol := candidate.LineNo-1, candidate.CharNo-1
+		if startLine > pos.Line || (startLine == pos.Line && startCol > col) {
+			break
+		}
+		stmt = candidate
+	}
+	return stmt, identifierAt(line, col), line
+}
+
+// identifierAt returns the identifier located at byte offset col of line, or
+// a blank string if there isn't one. Identifiers consist of letters, digits,
+// underscores, dollar signs, and non-ASCII characters. Surrounding backticks
+// are not included.
+func identifierAt(line string, col int) string {
+	isIdentByte := func(b byte) bool {
+		return b == '_' || b == '$' || b >= utf8.RuneSelf ||
+			(b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+	}
+	start, end := col, col
+	for start > 0 && isIdentByte(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdentByte(line[end]) {
+		end++
+	}
+	return line[start:end]
+}
+
+// format returns text with each CREATE statement rewritten to its canonical
+// format, as obtained from the workspace. Statements which have been modified
+// since the analysis are left as-is, since their canonical format is not yet
+// known.
+func (a *analysis) format(text string) (string, error) {
+	statements, err := tengo.ParseStatementsInString(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, stmt := range statements {
+		b.WriteString(a.formatStatement(stmt))
+	}
+	return b.String(), nil
+}
+
+func (a *analysis) formatStatement(stmt *tengo.Statement) string {
+	sa := a.schemas[stmt.Schema()]
+	if stmt.Type != tengo.StatementTypeCreate || sa == nil {
+		return stmt.Text
+	}
+	key := stmt.ObjectKey()
+	canonical, ok := sa.canonical[key]
+	fsStmt := sa.logicalSchema.Creates[key]
+	if !ok || fsStmt == nil || fsStmt.Body() != stmt.Body() {
+		return stmt.Text
+	}
+	newStmt := tengo.ParseStatementInString(canonical)
+	if newStmt.Compound && stmt.Delimiter == ";" {
+		return stmt.Text // would require adding DELIMITER commands
+	}
+	_, suffix := stmt.SplitTextBody()
+	if stmt.ObjectQualifier != "" {
+		return newStmt.ReplaceNameClause(stmt.NameClause()) + suffix
+	}
+	return canonical + suffix
+}
+
+// hover returns Markdown describing the column, index, foreign key, or table
+// named word, as referenced in stmt. A blank string is returned if word does
+// not refer to any such object.
+func (a *analysis) hover(stmt *tengo.Statement, word string) string {
+	if stmt.Type != tengo.StatementTypeCreate {
+		return ""
+	}
+	schemaName := stmt.Schema()
+	var defs []string
+	if table := a.table(schemaName, stmt.ObjectName); table != nil && stmt.ObjectType == tengo.ObjectTypeTable {
+		if col := columnNamed(table, word); col != nil {
+			defs = append(defs, col.Definition(a.flavor))
+			for _, idx := range table.IndexesWithColumn(col) {
+				defs = append(defs, idx.Definition(a.flavor))
+			}
+		} else if idx := indexNamed(table, word); idx != nil {
+			defs = append(defs, idx.Definition(a.flavor))
+		} else {
+			for _, fk := range table.ForeignKeys {
+				if strings.EqualFold(fk.Name, word) {
+					defs = append(defs, fk.Definition(a.flavor))
+				}
+			}
+		}
+	}
+	if len(defs) == 0 {
+		if table := a.table(schemaName, word); table != nil {
+			defs = append(defs, table.CreateStatement)
+		}
+	}
+	if len(defs) == 0 {
+		return ""
+	}
+	return "```sql\n" + strings.Join(defs, "\n") + "\n```"
+}
+
+// definition returns the location of the definition of word, as referenced in
+// stmt on the supplied line of text. This handles foreign key references to
+// parent tables and their columns, as well as references to columns of the
+// same table, for example in index definitions. Nil is returned if word does
+// not refer to any such object.
+func (a *analysis) definition(stmt *tengo.Statement, word, line string) *Location {
+	if stmt.Type != tengo.StatementTypeCreate || stmt.ObjectType != tengo.ObjectTypeTable {
+		return nil
+	}
+	schemaName := stmt.Schema()
+	table := a.table(schemaName, stmt.ObjectName)
+	if table == nil {
+		return nil
+	}
+
+	// Columns of a foreign key's parent table
+	if strings.Contains(strings.ToUpper(line), "REFERENCES") {
+		for _, fk := range table.ForeignKeys {
+			parentSchemaName := schemaName
+			if fk.ReferencedSchemaName != "" {
+				parentSchemaName = fk.ReferencedSchemaName
+			}
+			parent := a.table(parentSchemaName, fk.ReferencedTableName)
+			if parent == nil || !strings.Contains(line, fk.ReferencedTableName) {
+				continue
+			}
+			for _, colName := range fk.ReferencedColumnNames {
+				if col := columnNamed(parent, colName); col != nil && strings.EqualFold(colName, word) {
+					return a.columnLocation(parentSchemaName, parent, col)
+				}
+			}
+		}
+	}
+
+	// Tables, typically a foreign key's parent table
+	if other := a.table(schemaName, word); other != nil {
+		if otherStmt := a.createStatement(schemaName, other.ObjectKey()); otherStmt != nil {
+			return statementLocation(otherStmt, 0)
+		}
+	}
+
+	// Columns of the same table
+	if col := columnNamed(table, word); col != nil {
+		return a.columnLocation(schemaName, table, col)
+	}
+	return nil
+}
+
+// columnLocation returns the location of col's definition in the filesystem
+// CREATE statement for table.
+func (a *analysis) columnLocation(schemaName string, table *tengo.Table, col *tengo.Column) *Location {
+	stmt := a.createStatement(schemaName, table.ObjectKey())
+	if stmt == nil {
+		return nil
+	}
+	return statementLocation(stmt, linter.FindColumnLineOffset(col, stmt.Text))
+}
+
+// statementLocation returns the start of the supplied line offset of stmt.
+func statementLocation(stmt *tengo.Statement, lineOffset int) *Location {
+	pos := Position{Line: stmt.LineNo - 1 + lineOffset}
+	if lineOffset == 0 && stmt.CharNo > 1 {
+		pos.Character = stmt.CharNo - 1
+	}
+	return &Location{
+		URI:   pathToURI(stmt.File),
+		Range: Range{Start: pos, End: pos},
+	}
+}
+
+// columnNamed returns the column of table with the supplied name, using
+// case-insensitive comparison, or nil if there is no such column.
+func columnNamed(table *tengo.Table, name string) *tengo.Column {
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return col
+		}
+	}
+	return nil
+}
+
+// indexNamed returns the index of table with the supplied name, using
+// case-insensitive comparison, or nil if there is no such index.
+func indexNamed(table *tengo.Table, name string) *tengo.Index {
+	if table.PrimaryKey != nil && strings.EqualFold(name, "PRIMARY") {
+		return table.PrimaryKey
+	}
+	for _, idx := range table.SecondaryIndexes {
+		if strings.EqualFold(idx.Name, name) {
+			return idx
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// This file contains the subset of the JSON-RPC 2.0 wire format and Language
+// Server Protocol types which are used by Server. Field names and numeric
+// constants follow the LSP specification.
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// message is a JSON-RPC request, notification, or response. Requests have
+// both an ID and a Method; notifications lack an ID; responses lack a Method.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (re *responseError) Error() string {
+	return re.Message
+}
+
+// readMessage reads a single base-protocol message, consisting of headers
+// followed by a JSON body of length Content-Length.
+func readMessage(r *bufio.Reader) (*message, error) {
+	contentLength := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if contentLength, err = strconv.Atoi(strings.TrimSpace(value)); err != nil || contentLength < 0 {
+				return nil, fmt.Errorf("invalid Content-Length header %q", line)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+	return &msg, nil
+}
+
+// writeMessage writes msg to w, preceded by a Content-Length header.
+func writeMessage(w io.Writer, msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// Position is a zero-based line and character offset in a document. The
+// character offset is measured in UTF-16 code units, per the LSP default
+// position encoding.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document, with an exclusive End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a Range within a specific document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// DiagnosticSeverity values
+const (
+	DiagnosticSeverityError   = 1
+	DiagnosticSeverityWarning = 2
+)
+
+// Diagnostic is a problem reported for a range of a document.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// TextEdit is a replacement of a range of a document.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// MarkupContent is documentation text in a specific format.
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is the result of a textDocument/hover request.
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Range *Range `json:"range,omitempty"`
+		Text  string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type textDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// MessageType value for window/showMessage
+const messageTypeError = 1
+
+type showMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+// uriToPath converts a file:// URI to an absolute filesystem path.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	} else if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme in %s", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathToURI converts an absolute filesystem path to a file:// URI.
+func pathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// documentRange returns a Range spanning all of text.
+func documentRange(text string) Range {
+	lines := strings.Split(text, "\n")
+	return Range{
+		End: Position{Line: len(lines) - 1, Character: utf16Len(lines[len(lines)-1])},
+	}
+}
+
+// byteOffset converts a UTF-16 character offset within line into a byte
+// offset. Offsets past the end of the line are clamped to its length.
+func byteOffset(line string, character int) int {
+	var units int
+	for pos, r := range line {
+		if units >= character {
+			return pos
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) (n int) {
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,394 @@
+// Package lsp implements a Language Server Protocol server for the *.sql files
+// in a Skeema schema repo. Each directory is executed in a workspace and
+// linted, in order to provide diagnostics, canonical formatting, hover
+// information, and navigation to foreign key parent tables.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+)
+
+// Server is a language server which communicates with a single client, using
+// JSON-RPC over a pair of streams. Requests are handled sequentially.
+type Server struct {
+	cfg       *mybase.Config
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]string    // absolute path => text, for documents opened by the client
+	analyses  map[string]*analysis // absolute dir path => most recent analysis of dir
+	shutdown  bool
+}
+
+// NewServer returns a Server which reads client messages from r and writes
+// server messages to w. Directories are parsed using cfg as their base
+// configuration.
+func NewServer(cfg *mybase.Config, r io.Reader, w io.Writer) *Server {
+	return &Server{
+		cfg:       cfg,
+		reader:    bufio.NewReader(r),
+		writer:    w,
+		documents: make(map[string]string),
+		analyses:  make(map[string]*analysis),
+	}
+}
+
+// Run processes client messages until the client sends an exit notification
+// or closes the input stream. An error is returned if the input stream could
+// not be read, or if the client exits without first requesting a shutdown.
+func (s *Server) Run() error {
+	for {
+		msg, err := readMessage(s.reader)
+		var rerr *responseError
+		if err == io.EOF {
+			return nil
+		} else if errors.As(err, &rerr) {
+			s.respond(nil, nil, rerr)
+			continue
+		} else if err != nil {
+			return err
+		}
+		if msg.Method == "" {
+			continue // response to a request from the server, which we don't use
+		} else if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("received exit notification without a prior shutdown request")
+			}
+			return nil
+		}
+		result, err := s.handle(msg.Method, msg.Params)
+		if msg.ID != nil {
+			s.respond(msg.ID, result, err)
+		} else if err != nil {
+			log.Warnf("Error handling %s notification: %s", msg.Method, err)
+		}
+	}
+}
+
+// handle dispatches a request or notification to the appropriate handler.
+func (s *Server) handle(method string, params json.RawMessage) (any, error) {
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+	switch method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p didOpenParams
+		return nil, withParams(params, &p, func() error { return s.didOpen(p) })
+	case "textDocument/didChange":
+		var p didChangeParams
+		return nil, withParams(params, &p, func() error { return s.didChange(p) })
+	case "textDocument/didSave":
+		var p textDocumentParams
+		return nil, withParams(params, &p, func() error { return s.didSave(p) })
+	case "textDocument/didClose":
+		var p textDocumentParams
+		return nil, withParams(params, &p, func() error { return s.didClose(p) })
+	case "textDocument/formatting":
+		var p textDocumentParams
+		var edits []TextEdit
+		err := withParams(params, &p, func() (err error) { edits, err = s.formatting(p); return err })
+		return edits, err
+	case "textDocument/hover":
+		var p textDocumentPositionParams
+		var hover *Hover
+		err := withParams(params, &p, func() (err error) { hover, err = s.hover(p); return err })
+		return hover, err
+	case "textDocument/definition":
+		var p textDocumentPositionParams
+		var location *Location
+		err := withParams(params, &p, func() (err error) { location, err = s.definition(p); return err })
+		return location, err
+	}
+	if strings.HasPrefix(method, "$/") {
+		return nil, nil // optional protocol-level notifications may be ignored
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "unsupported method " + method}
+}
+
+// withParams unmarshals params into dest, and then calls handler.
+func withParams(params json.RawMessage, dest any, handler func() error) error {
+	if err := json.Unmarshal(params, dest); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return handler()
+}
+
+func (s *Server) initialize() any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    1, // full document sync
+				"save":      map[string]any{"includeText": false},
+			},
+			"documentFormattingProvider": true,
+			"hoverProvider":              true,
+			"definitionProvider":         true,
+		},
+		"serverInfo": map[string]any{"name": "skeema"},
+	}
+}
+
+// respond sends a response to the request with the supplied ID.
+func (s *Server) respond(id *json.RawMessage, result any, err error) {
+	msg := &message{ID: id}
+	if id == nil {
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+	if err != nil {
+		var rerr *responseError
+		if !errors.As(err, &rerr) {
+			rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		msg.Error = rerr
+	} else if msg.Result, err = json.Marshal(result); err != nil {
+		msg.Error = &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	if err := writeMessage(s.writer, msg); err != nil {
+		log.Errorf("Unable to write response: %s", err)
+	}
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params any) {
+	msg := &message{Method: method}
+	var err error
+	if msg.Params, err = json.Marshal(params); err == nil {
+		err = writeMessage(s.writer, msg)
+	}
+	if err != nil {
+		log.Errorf("Unable to write %s notification: %s", method, err)
+	}
+}
+
+func (s *Server) showMessage(messageType int, text string) {
+	s.notify("window/showMessage", showMessageParams{Type: messageType, Message: text})
+}
+
+func (s *Server) didOpen(p didOpenParams) error {
+	filePath, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	s.documents[filePath] = p.TextDocument.Text
+	if isSQLFile(filePath) && s.analysisFor(filePath) == nil {
+		s.analyze(s.dirPathFor(filePath))
+	}
+	return nil
+}
+
+func (s *Server) didChange(p didChangeParams) error {
+	filePath, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	// With full document sync, each change contains the entire new text
+	if n := len(p.ContentChanges); n > 0 {
+		s.documents[filePath] = p.ContentChanges[n-1].Text
+	}
+	return nil
+}
+
+// didSave re-analyzes the directory affected by a saved file. Saving a .skeema
+// file re-analyzes all previously-analyzed directories which it may affect,
+// since options cascade to subdirectories.
+func (s *Server) didSave(p textDocumentParams) error {
+	filePath, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	if isSQLFile(filePath) {
+		s.analyze(s.dirPathFor(filePath))
+	} else if filepath.Base(filePath) == ".skeema" {
+		optionDirPath := filepath.Dir(filePath)
+		for dirPath := range s.analyses {
+			if rel, err := filepath.Rel(optionDirPath, dirPath); err == nil && !strings.HasPrefix(rel, "..") {
+				s.analyze(dirPath)
+			}
+		}
+	}
+	return nil
+}
+
+// didClose discards the document. Once no documents remain open in an analyzed
+// directory, the analysis is discarded and its diagnostics are cleared.
+func (s *Server) didClose(p textDocumentParams) error {
+	filePath, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	delete(s.documents, filePath)
+	for dirPath, a := range s.analyses {
+		if !a.hasFile(filePath) {
+			continue
+		}
+		for docPath := range s.documents {
+			if a.hasFile(docPath) {
+				return nil
+			}
+		}
+		delete(s.analyses, dirPath)
+		s.publishDiagnostics(a, nil)
+	}
+	return nil
+}
+
+// analyze parses, executes, and lints the dir at dirPath, replacing any
+// previous analysis of the dir, and publishes the resulting diagnostics.
+// Errors are reported to the client as messages.
+func (s *Server) analyze(dirPath string) {
+	oldAnalysis := s.analyses[dirPath]
+	dir, err := fs.ParseDir(dirPath, s.cfg)
+	var a *analysis
+	if err == nil {
+		a, err = analyzeDir(dir)
+	}
+	if err != nil {
+		delete(s.analyses, dirPath)
+		s.publishDiagnostics(oldAnalysis, nil)
+		s.showMessage(messageTypeError, fmt.Sprintf("Unable to analyze %s: %s", dirPath, err))
+		return
+	}
+	for _, err := range a.exceptions {
+		s.showMessage(messageTypeError, fmt.Sprintf("Unable to fully analyze %s: %s", dirPath, err))
+	}
+	s.analyses[dirPath] = a
+	s.publishDiagnostics(oldAnalysis, a)
+}
+
+// publishDiagnostics sends the diagnostics of newAnalysis for each of its
+// files. Files which previously had diagnostics in oldAnalysis, but no longer
+// do, have their diagnostics cleared. Either analysis may be nil.
+func (s *Server) publishDiagnostics(oldAnalysis, newAnalysis *analysis) {
+	filePaths := make(map[string]bool)
+	if oldAnalysis != nil {
+		for filePath := range oldAnalysis.diagnostics {
+			filePaths[filePath] = true
+		}
+	}
+	if newAnalysis != nil {
+		for _, sqlFile := range newAnalysis.dir.SQLFiles {
+			filePaths[sqlFile.FilePath] = true
+		}
+	}
+	for filePath := range filePaths {
+		diagnostics := []Diagnostic{}
+		if newAnalysis != nil && newAnalysis.diagnostics[filePath] != nil {
+			diagnostics = newAnalysis.diagnostics[filePath]
+		}
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         pathToURI(filePath),
+			Diagnostics: diagnostics,
+		})
+	}
+}
+
+// analysisFor returns the most recent analysis including the *.sql file at
+// filePath, or nil if there isn't one.
+func (s *Server) analysisFor(filePath string) *analysis {
+	for _, a := range s.analyses {
+		if a.hasFile(filePath) {
+			return a
+		}
+	}
+	return nil
+}
+
+// dirPathFor returns the path of the dir which contains the *.sql file at
+// filePath. Normally this is simply the file's parent dir. However, if that
+// dir lacks a .skeema file, the file may instead belong to the grandparent dir,
+// if its layout places files in subdirs.
+func (s *Server) dirPathFor(filePath string) string {
+	for dirPath, a := range s.analyses {
+		if a.hasFile(filePath) {
+			return dirPath
+		}
+	}
+	dirPath := filepath.Dir(filePath)
+	if _, err := os.Stat(filepath.Join(dirPath, ".skeema")); err == nil {
+		return dirPath
+	}
+	parentPath := filepath.Dir(dirPath)
+	if parent, err := fs.ParseDir(parentPath, s.cfg); err == nil && parent.ParseError == nil && dirHasFile(parent, filePath) {
+		return parentPath
+	}
+	return dirPath
+}
+
+// document returns the text and analysis of an open document, or an error if
+// the document has not been opened. The analysis may be nil if the document's
+// dir has not been successfully analyzed.
+func (s *Server) document(uri string) (string, *analysis, error) {
+	filePath, err := uriToPath(uri)
+	if err != nil {
+		return "", nil, err
+	}
+	text, ok := s.documents[filePath]
+	if !ok {
+		return "", nil, &responseError{Code: codeInvalidParams, Message: "document not open: " + uri}
+	}
+	return text, s.analysisFor(filePath), nil
+}
+
+// formatting returns an edit replacing the document with its canonical
+// format, or no edits if no formatting changes are needed.
+func (s *Server) formatting(p textDocumentParams) ([]TextEdit, error) {
+	text, a, err := s.document(p.TextDocument.URI)
+	if err != nil || a == nil {
+		return []TextEdit{}, err
+	}
+	newText, err := a.format(text)
+	if err != nil || newText == text {
+		return []TextEdit{}, nil // unparseable text can't be formatted, but this isn't a protocol error
+	}
+	return []TextEdit{{Range: documentRange(text), NewText: newText}}, nil
+}
+
+func (s *Server) hover(p textDocumentPositionParams) (*Hover, error) {
+	text, a, err := s.document(p.TextDocument.URI)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	stmt, word, _ := statementAndWordAt(text, p.Position)
+	if stmt == nil || word == "" {
+		return nil, nil
+	}
+	if contents := a.hover(stmt, word); contents != "" {
+		return &Hover{Contents: MarkupContent{Kind: "markdown", Value: contents}}, nil
+	}
+	return nil, nil
+}
+
+func (s *Server) definition(p textDocumentPositionParams) (*Location, error) {
+	text, a, err := s.document(p.TextDocument.URI)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	stmt, word, line := statementAndWordAt(text, p.Position)
+	if stmt == nil || word == "" {
+		return nil, nil
+	}
+	return a.definition(stmt, word, line), nil
+}
+
+func isSQLFile(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), ".sql")
+}
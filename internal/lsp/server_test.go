@@ -0,0 +1,302 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/tengo"
+	"github.com/skeema/skeema/internal/util"
+	"github.com/skeema/skeema/internal/workspace"
+)
+
+func TestReadWriteMessage(t *testing.T) {
+	var b bytes.Buffer
+	params := json.RawMessage(`{"foo":"bar"}`)
+	if err := writeMessage(&b, &message{Method: "test", Params: params}); err != nil {
+		t.Fatalf("Unexpected error from writeMessage: %v", err)
+	}
+	if !strings.HasPrefix(b.String(), "Content-Length: 56\r\n\r\n{") {
+		t.Errorf("Unexpected output from writeMessage: %q", b.String())
+	}
+	msg, err := readMessage(bufio.NewReader(&b))
+	if err != nil {
+		t.Fatalf("Unexpected error from readMessage: %v", err)
+	} else if msg.JSONRPC != "2.0" || msg.Method != "test" || string(msg.Params) != string(params) {
+		t.Errorf("Unexpected message from readMessage: %+v", msg)
+	}
+	if _, err := readMessage(bufio.NewReader(&b)); err != io.EOF {
+		t.Errorf("Expected io.EOF from readMessage on empty input, instead found %v", err)
+	}
+
+	for _, input := range []string{"Content-Length: 2\r\n\r\n", "Content-Type: x\r\n\r\n{}", "Content-Length: x\r\n\r\n{}", "bad\r\n\r\n{}"} {
+		if _, err := readMessage(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("Expected error from readMessage on input %q, but err was nil", input)
+		}
+	}
+	var rerr *responseError
+	if _, err := readMessage(bufio.NewReader(strings.NewReader("Content-Length: 2\r\n\r\n{x"))); err == nil || !errors.As(err, &rerr) || rerr.Code != codeParseError {
+		t.Errorf("Expected parse error from readMessage on invalid JSON, instead found %v", err)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	line := "a ü 😀 `b`"
+	cases := map[int]int{0: 0, 2: 2, 3: 4, 4: 5, 6: 9, 100: len(line)}
+	for character, expected := range cases {
+		if actual := byteOffset(line, character); actual != expected {
+			t.Errorf("Expected byteOffset(%q, %d) to return %d, instead found %d", line, character, expected, actual)
+		}
+	}
+	if actual := utf16Len(line); actual != 10 {
+		t.Errorf("Expected utf16Len to return 10, instead found %d", actual)
+	}
+	if rng := documentRange("foo\nbar😀"); rng.End.Line != 1 || rng.End.Character != 5 {
+		t.Errorf("Unexpected result from documentRange: %+v", rng)
+	}
+
+	line = "a  `foo` `b` "
+	identCases := map[int]string{0: "a", 1: "a", 2: "", 4: "foo", 7: "foo", 10: "b", 12: ""}
+	for col, expected := range identCases {
+		if actual := identifierAt(line, col); actual != expected {
+			t.Errorf("Expected identifierAt(%q, %d) to return %q, instead found %q", line, col, expected, actual)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "some file.sql")
+	uri := pathToURI(path)
+	if !strings.HasPrefix(uri, "file:///") || !strings.Contains(uri, "some%20file.sql") {
+		t.Errorf("Unexpected result from pathToURI: %s", uri)
+	}
+	if roundTrip, err := uriToPath(uri); err != nil || roundTrip != path {
+		t.Errorf("Unexpected result from uriToPath(%q): %q, %v", uri, roundTrip, err)
+	}
+	if _, err := uriToPath("untitled:Untitled-1"); err == nil {
+		t.Error("Expected error from uriToPath with non-file URI, but err was nil")
+	}
+}
+
+func TestServer(t *testing.T) {
+	dirPath := t.TempDir()
+	contents := "create table parent (id int not null primary key);\n" +
+		"CREATE TABLE child (\n" +
+		"  id int NOT NULL,\n" +
+		"  parent_id int NOT NULL,\n" +
+		"  PRIMARY KEY (id),\n" +
+		"  KEY parent_idx (parent_id),\n" +
+		"  CONSTRAINT parent_fk FOREIGN KEY (parent_id) REFERENCES parent (id)\n" +
+		");\n"
+	filePath := filepath.Join(dirPath, "tables.sql")
+	fs.WriteTestFile(t, filePath, contents)
+	cfg := getConfig(t)
+	dir, err := fs.ParseDir(dirPath, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	a := makeTestAnalysis(dir)
+
+	// Confirm conversion of linter annotations to diagnostics
+	childStmt := dir.LogicalSchemas[0].Creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "child"}]
+	a.addDiagnostic(&linter.Annotation{
+		RuleName:  "has-fk",
+		Statement: childStmt,
+		Severity:  linter.SeverityWarning,
+		Note:      linter.Note{LineOffset: 5, Message: "table has a foreign key"},
+	})
+	expectDiag := Diagnostic{
+		Range:    Range{Start: Position{Line: 6}, End: Position{Line: 6, Character: 69}},
+		Severity: DiagnosticSeverityWarning,
+		Code:     "has-fk",
+		Source:   "skeema",
+		Message:  "table has a foreign key",
+	}
+	if diags := a.diagnostics[filePath]; len(diags) != 1 || diags[0] != expectDiag {
+		t.Errorf("Unexpected diagnostics: %+v", diags)
+	}
+
+	// Run the server with a sequence of client messages. Since the dir already
+	// has an analysis, opening the file does not require a workspace.
+	uri := pathToURI(filePath)
+	changed := strings.Replace(contents, "primary key", "PRIMARY KEY", 1)
+	var input bytes.Buffer
+	requests := []struct {
+		id     int
+		method string
+		params any
+	}{
+		{1, "initialize", map[string]any{}},
+		{0, "initialized", map[string]any{}},
+		{0, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "sql", "version": 1, "text": contents}}},
+		{2, "textDocument/hover", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: uri}, Position: Position{Line: 5, Character: 22}}},
+		{3, "textDocument/hover", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: uri}, Position: Position{Line: 6, Character: 60}}},
+		{4, "textDocument/definition", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: uri}, Position: Position{Line: 6, Character: 60}}},
+		{5, "textDocument/definition", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: uri}, Position: Position{Line: 6, Character: 67}}},
+		{6, "textDocument/definition", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: uri}, Position: Position{Line: 5, Character: 22}}},
+		{7, "textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": uri}}},
+		{0, "textDocument/didChange", map[string]any{"textDocument": map[string]any{"uri": uri}, "contentChanges": []map[string]any{{"text": changed}}}},
+		{8, "textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": uri}}},
+		{9, "textDocument/hover", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: "file:///not/open.sql"}}},
+		{10, "textDocument/rename", map[string]any{}},
+		{11, "shutdown", nil},
+		{0, "exit", nil},
+	}
+	for _, req := range requests {
+		msg := &message{Method: req.method}
+		if req.id > 0 {
+			id := json.RawMessage(fmt.Sprint(req.id))
+			msg.ID = &id
+		}
+		msg.Params, _ = json.Marshal(req.params)
+		writeMessage(&input, msg)
+	}
+	var output bytes.Buffer
+	s := NewServer(cfg, &input, &output)
+	s.analyses[dirPath] = a
+	if err := s.Run(); err != nil {
+		t.Fatalf("Unexpected error from Run: %v", err)
+	}
+	responses := make(map[string]*message)
+	reader := bufio.NewReader(&output)
+	for {
+		msg, err := readMessage(reader)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Unexpected error reading server output: %v", err)
+		}
+		if msg.ID != nil {
+			responses[string(*msg.ID)] = msg
+		}
+	}
+	result := func(id string, dest any) {
+		t.Helper()
+		if msg := responses[id]; msg == nil {
+			t.Fatalf("No response to request %s", id)
+		} else if msg.Error != nil {
+			t.Fatalf("Unexpected error in response to request %s: %v", id, msg.Error)
+		} else if err := json.Unmarshal(msg.Result, dest); err != nil {
+			t.Fatalf("Unable to unmarshal result of request %s: %v", id, err)
+		}
+	}
+
+	var initResult struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	if result("1", &initResult); initResult.Capabilities["hoverProvider"] != true {
+		t.Errorf("Unexpected capabilities: %+v", initResult.Capabilities)
+	}
+
+	// Hover over a column name, and a table name
+	var hover Hover
+	if result("2", &hover); !strings.Contains(hover.Contents.Value, "`parent_id` int NOT NULL\nKEY `parent_idx` (`parent_id`)") {
+		t.Errorf("Unexpected hover for column: %+v", hover)
+	}
+	if result("3", &hover); !strings.Contains(hover.Contents.Value, "CREATE TABLE `parent`") {
+		t.Errorf("Unexpected hover for table: %+v", hover)
+	}
+
+	// Definition of FK parent table, FK parent column, and column of same table
+	expectLocations := map[string]Location{
+		"4": {URI: uri, Range: Range{Start: Position{Line: 0}, End: Position{Line: 0}}},
+		"5": {URI: uri, Range: Range{Start: Position{Line: 0}, End: Position{Line: 0}}},
+		"6": {URI: uri, Range: Range{Start: Position{Line: 3}, End: Position{Line: 3}}},
+	}
+	for id, expected := range expectLocations {
+		var location Location
+		if result(id, &location); location != expected {
+			t.Errorf("Unexpected definition location for request %s: %+v", id, location)
+		}
+	}
+
+	// Formatting should only affect the parent table, and only while its
+	// statement is unmodified since the analysis
+	var edits []TextEdit
+	if result("7", &edits); len(edits) != 1 {
+		t.Errorf("Expected 1 formatting edit, instead found %+v", edits)
+	} else if expected := strings.Replace(contents, "create table parent (id int not null primary key)", a.schemas[""].canonical[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "parent"}], 1); edits[0].NewText != expected {
+		t.Errorf("Unexpected formatting edit: %+v", edits[0])
+	}
+	if result("8", &edits); len(edits) != 0 {
+		t.Errorf("Expected no formatting edits for modified statement, instead found %+v", edits)
+	}
+
+	// Requests for unopened documents or unsupported methods should be errors
+	if msg := responses["9"]; msg == nil || msg.Error == nil || msg.Error.Code != codeInvalidParams {
+		t.Errorf("Expected invalid params error for unopened document, instead found %+v", msg)
+	}
+	if msg := responses["10"]; msg == nil || msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+		t.Errorf("Expected method not found error for unsupported method, instead found %+v", msg)
+	}
+
+	// Exit without a shutdown request is an error
+	input.Reset()
+	writeMessage(&input, &message{Method: "exit"})
+	if err := NewServer(cfg, &input, io.Discard).Run(); err == nil {
+		t.Error("Expected error from Run with exit prior to shutdown, but err was nil")
+	}
+}
+
+// makeTestAnalysis returns an analysis for dir, which must contain the tables
+// parent and child from TestServer. The tables are constructed directly, rather
+// than by using a workspace.
+func makeTestAnalysis(dir *fs.Dir) *analysis {
+	intCol := func(name string) *tengo.Column {
+		return &tengo.Column{Name: name, Type: tengo.ParseColumnType("int")}
+	}
+	pk := &tengo.Index{Name: "PRIMARY", Parts: []tengo.IndexPart{{ColumnName: "id"}}, PrimaryKey: true, Unique: true}
+	parent := &tengo.Table{
+		Name:       "parent",
+		Engine:     "InnoDB",
+		Columns:    []*tengo.Column{intCol("id")},
+		PrimaryKey: pk,
+	}
+	parent.CreateStatement = parent.GeneratedCreateStatement(tengo.FlavorUnknown)
+	child := &tengo.Table{
+		Name:             "child",
+		Engine:           "InnoDB",
+		Columns:          []*tengo.Column{intCol("id"), intCol("parent_id")},
+		PrimaryKey:       pk,
+		SecondaryIndexes: []*tengo.Index{{Name: "parent_idx", Parts: []tengo.IndexPart{{ColumnName: "parent_id"}}}},
+		ForeignKeys: []*tengo.ForeignKey{{
+			Name:                  "parent_fk",
+			ColumnNames:           []string{"parent_id"},
+			ReferencedTableName:   "parent",
+			ReferencedColumnNames: []string{"id"},
+		}},
+	}
+	child.CreateStatement = child.GeneratedCreateStatement(tengo.FlavorUnknown)
+	logicalSchema := dir.LogicalSchemas[0]
+	return &analysis{
+		dir: dir,
+		schemas: map[string]*schemaAnalysis{
+			"": {
+				logicalSchema: logicalSchema,
+				schema:        &tengo.Schema{Tables: []*tengo.Table{child, parent}},
+				canonical: map[tengo.ObjectKey]string{
+					parent.ObjectKey(): parent.CreateStatement,
+					child.ObjectKey():  logicalSchema.Creates[child.ObjectKey()].Body(),
+				},
+			},
+		},
+		diagnostics: make(map[string][]Diagnostic),
+	}
+}
+
+func getConfig(t *testing.T) *mybase.Config {
+	t.Helper()
+	cmd := mybase.NewCommand("lsptest", "", "", nil)
+	util.AddGlobalOptions(cmd)
+	workspace.AddCommandOptions(cmd)
+	linter.AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	return mybase.ParseFakeCLI(t, cmd, "lsptest --skip-workspace-cache")
+}
@@ -0,0 +1,310 @@
+package tengo
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TableData represents the rows of a table, for purposes of managing the full
+// contents of small reference or lookup tables. Rows are ordered by primary
+// key. Each value is the string form returned by the database server, with nil
+// representing NULL.
+type TableData struct {
+	Table   *Table
+	Columns []*Column
+	Rows    [][]*string
+}
+
+// DataColumns returns the columns of t which can be populated by INSERT
+// statements, omitting generated columns.
+func (t *Table) DataColumns() []*Column {
+	columns := make([]*Column, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		if col.GenerationExpr == "" {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// QueryTableData returns the rows of table in the schema that db's connection
+// pool defaults to, ordered by primary key. Only the supplied columns are
+// queried; if columns is nil, table.DataColumns() is used. An error is
+// returned if table lacks a primary key, or if any primary key column is
+// absent from columns.
+func QueryTableData(db *sql.DB, table *Table, columns []*Column) (*TableData, error) {
+	if columns == nil {
+		columns = table.DataColumns()
+	}
+	td := &TableData{
+		Table:   table,
+		Columns: columns,
+	}
+	if _, err := td.pkPositions(); err != nil {
+		return nil, err
+	}
+	return td, td.query(db, 0, 1)
+}
+
+// QueryTableDataSample returns up to limit rows of table, including all of its
+// DataColumns. If the table has more than limit rows, based on the row count
+// estimate in information_schema, rows are randomly sampled throughout the
+// table, rather than just returning its first rows. If limit is 0, or the
+// table has no more than limit rows, the result includes the full table.
+func QueryTableDataSample(db *sql.DB, table *Table, limit int) (*TableData, error) {
+	td := &TableData{
+		Table:   table,
+		Columns: table.DataColumns(),
+	}
+	fraction := 1.0
+	if limit > 0 {
+		var estimate int64
+		query := "SELECT COALESCE(table_rows, 0) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+		if err := db.QueryRow(query, table.Name).Scan(&estimate); err != nil {
+			return nil, err
+		} else if estimate > int64(limit) {
+			fraction = float64(limit) / float64(estimate)
+		}
+	}
+	return td, td.query(db, limit, fraction)
+}
+
+// query populates td.Rows by querying td.Columns from td.Table, using the
+// supplied row limit if it is positive, and only including a random fraction
+// of rows if fraction is less than 1.
+func (td *TableData) query(db *sql.DB, limit int, fraction float64) error {
+	query := td.selectQuery(limit, fraction)
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		raw := make([]sql.RawBytes, len(td.Columns))
+		dest := make([]any, len(td.Columns))
+		for n := range raw {
+			dest[n] = &raw[n]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		row := make([]*string, len(td.Columns))
+		for n := range raw {
+			if raw[n] != nil {
+				value := string(raw[n])
+				row[n] = &value
+			}
+		}
+		td.Rows = append(td.Rows, row)
+	}
+	return rows.Err()
+}
+
+// selectQuery returns a SELECT query for td.Columns of td.Table, ordered by
+// primary key if the table has one. See query for the meaning of the args.
+func (td *TableData) selectQuery(limit int, fraction float64) string {
+	colNames := make([]string, len(td.Columns))
+	for n, col := range td.Columns {
+		colNames[n] = EscapeIdentifier(col.Name)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(colNames, ", "), EscapeIdentifier(td.Table.Name))
+	if fraction < 1 {
+		query += fmt.Sprintf(" WHERE RAND() < %g", fraction)
+	}
+	if td.Table.PrimaryKey != nil {
+		orderBy := make([]string, len(td.Table.PrimaryKey.Parts))
+		for n, part := range td.Table.PrimaryKey.Parts {
+			orderBy[n] = EscapeIdentifier(part.ColumnName)
+		}
+		query += " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return query
+}
+
+// pkPositions returns the positions within td.Columns of each primary key
+// column of td.Table.
+func (td *TableData) pkPositions() ([]int, error) {
+	if td.Table.PrimaryKey == nil {
+		return nil, fmt.Errorf("Managed data is only supported for tables with a primary key, but %s does not have one", td.Table.ObjectKey())
+	}
+	positions := make([]int, len(td.Table.PrimaryKey.Parts))
+PartLoop:
+	for n, part := range td.Table.PrimaryKey.Parts {
+		for pos, col := range td.Columns {
+			if col.Name == part.ColumnName {
+				positions[n] = pos
+				continue PartLoop
+			}
+		}
+		return nil, fmt.Errorf("Managed data for %s is missing primary key column %s", td.Table.ObjectKey(), EscapeIdentifier(part.ColumnName))
+	}
+	return positions, nil
+}
+
+// rowsByKey returns a map of primary key string => row position, as well as a
+// slice of keys in row order.
+func (td *TableData) rowsByKey() (map[string]int, []string) {
+	positions, _ := td.pkPositions()
+	byKey := make(map[string]int, len(td.Rows))
+	keys := make([]string, len(td.Rows))
+	for n, row := range td.Rows {
+		var b strings.Builder
+		for _, pos := range positions {
+			if row[pos] != nil {
+				b.WriteString(*row[pos])
+			}
+			b.WriteByte(0)
+		}
+		keys[n] = b.String()
+		byKey[keys[n]] = n
+	}
+	return byKey, keys
+}
+
+// InsertStatement returns a single multi-row INSERT statement which populates
+// all rows of td, or an empty string if td has no rows. The statement does not
+// include a trailing delimiter.
+func (td *TableData) InsertStatement() string {
+	if len(td.Rows) == 0 {
+		return ""
+	}
+	colNames := make([]string, len(td.Columns))
+	for n, col := range td.Columns {
+		colNames[n] = EscapeIdentifier(col.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES\n", EscapeIdentifier(td.Table.Name), strings.Join(colNames, ", "))
+	for n, row := range td.Rows {
+		if n > 0 {
+			b.WriteString(",\n")
+		}
+		b.WriteString("  (")
+		for pos, value := range row {
+			if pos > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(dataLiteral(td.Columns[pos], value))
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// whereClause returns a WHERE clause matching the primary key of a row.
+func (td *TableData) whereClause(row []*string) string {
+	positions, _ := td.pkPositions()
+	conds := make([]string, len(positions))
+	for n, pos := range positions {
+		conds[n] = EscapeIdentifier(td.Columns[pos].Name) + " = " + dataLiteral(td.Columns[pos], row[pos])
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// dataLiteral returns value formatted as a SQL literal appropriate for col's
+// data type.
+func dataLiteral(col *Column, value *string) string {
+	if value == nil {
+		return "NULL"
+	}
+	switch col.Type.Base {
+	case "tinyint", "smallint", "mediumint", "int", "bigint", "decimal", "float", "double":
+		return *value
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit":
+		if *value != "" {
+			return "0x" + hex.EncodeToString([]byte(*value))
+		}
+	}
+	if !utf8.ValidString(*value) {
+		return "0x" + hex.EncodeToString([]byte(*value))
+	}
+	return "'" + EscapeValueForCreateTable(*value) + "'"
+}
+
+// RowDiff represents a difference in a single row of a table's managed data.
+// It satisfies the ObjectDiff interface. Its DiffType is DiffTypeCreate for an
+// INSERT, DiffTypeAlter for an UPDATE, or DiffTypeDrop for a DELETE.
+type RowDiff struct {
+	Type      DiffType
+	TableName string
+	stmt      string
+}
+
+// ObjectKey returns a value identifying the table containing the row.
+func (rd *RowDiff) ObjectKey() ObjectKey {
+	return ObjectKey{Type: ObjectTypeTable, Name: rd.TableName}
+}
+
+// DiffType returns the type of diff operation.
+func (rd *RowDiff) DiffType() DiffType {
+	return rd.Type
+}
+
+// Statement returns the DML statement corresponding to the row difference.
+// DELETE statements are considered unsafe unless mods.AllowUnsafe is true.
+func (rd *RowDiff) Statement(mods StatementModifiers) (string, error) {
+	if rd.Type == DiffTypeDrop && !mods.AllowUnsafe {
+		return rd.stmt, &UnsafeDiffError{
+			Reason: "Desired deletion of managed data row in " + rd.ObjectKey().String() + " would cause data loss.",
+		}
+	}
+	return rd.stmt, nil
+}
+
+// DiffTableData compares two versions of a table's rows by primary key, and
+// returns the INSERT, UPDATE, and DELETE statements needed to transform from
+// into to. Both must have the same Table; from.Columns may be a subset of
+// to.Columns (for example if to's table definition adds a column), in which
+// case values for the missing columns are always treated as differing.
+func DiffTableData(from, to *TableData) []*RowDiff {
+	fromByKey, fromKeys := from.rowsByKey()
+	toByKey, toKeys := to.rowsByKey()
+	fromPos := make(map[string]int, len(from.Columns))
+	for pos, col := range from.Columns {
+		fromPos[col.Name] = pos
+	}
+	tableName := EscapeIdentifier(to.Table.Name)
+	var diffs []*RowDiff
+
+	for n, key := range toKeys {
+		toRow := to.Rows[n]
+		fromIndex, exists := fromByKey[key]
+		if !exists {
+			single := &TableData{Table: to.Table, Columns: to.Columns, Rows: [][]*string{toRow}}
+			diffs = append(diffs, &RowDiff{Type: DiffTypeCreate, TableName: to.Table.Name, stmt: single.InsertStatement()})
+			continue
+		}
+		fromRow := from.Rows[fromIndex]
+		var sets []string
+		for pos, col := range to.Columns {
+			if fp, ok := fromPos[col.Name]; ok && equalDataValues(fromRow[fp], toRow[pos]) {
+				continue
+			}
+			sets = append(sets, EscapeIdentifier(col.Name)+" = "+dataLiteral(col, toRow[pos]))
+		}
+		if len(sets) > 0 {
+			stmt := "UPDATE " + tableName + " SET " + strings.Join(sets, ", ") + to.whereClause(toRow)
+			diffs = append(diffs, &RowDiff{Type: DiffTypeAlter, TableName: to.Table.Name, stmt: stmt})
+		}
+	}
+
+	for n, row := range from.Rows {
+		if _, exists := toByKey[fromKeys[n]]; !exists {
+			stmt := "DELETE FROM " + tableName + from.whereClause(row)
+			diffs = append(diffs, &RowDiff{Type: DiffTypeDrop, TableName: to.Table.Name, stmt: stmt})
+		}
+	}
+	return diffs
+}
+
+func equalDataValues(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
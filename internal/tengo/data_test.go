@@ -0,0 +1,137 @@
+package tengo
+
+import (
+	"testing"
+)
+
+func dataRow(values ...any) []*string {
+	row := make([]*string, len(values))
+	for n, v := range values {
+		if s, ok := v.(string); ok {
+			row[n] = &s
+		}
+	}
+	return row
+}
+
+func TestTableDataInsertStatement(t *testing.T) {
+	table := aTable(1)
+	data := &TableData{
+		Table:   &table,
+		Columns: table.Columns[0:3],
+	}
+	if stmt := data.InsertStatement(); stmt != "" {
+		t.Errorf("Expected blank statement for table data without rows, instead found %q", stmt)
+	}
+	data.Rows = [][]*string{
+		dataRow("1", "Tom", nil),
+		dataRow("2", "Miles O'Brien", "x\ny"),
+	}
+	expected := "INSERT INTO `actor` (`actor_id`, `first_name`, `last_name`) VALUES\n" +
+		"  (1, 'Tom', NULL),\n" +
+		"  (2, 'Miles O''Brien', 'x\\ny')"
+	if stmt := data.InsertStatement(); stmt != expected {
+		t.Errorf("Unexpected result from InsertStatement.\nExpected:\n%s\nActual:\n%s", expected, stmt)
+	}
+
+	// Binary columns should use hex literals
+	binData := &TableData{
+		Table:   &table,
+		Columns: []*Column{table.Columns[0], {Name: "hash", Type: ParseColumnType("binary(2)")}},
+		Rows:    [][]*string{dataRow("3", "\xff\x00")},
+	}
+	expected = "INSERT INTO `actor` (`actor_id`, `hash`) VALUES\n  (3, 0xff00)"
+	if stmt := binData.InsertStatement(); stmt != expected {
+		t.Errorf("Unexpected result from InsertStatement.\nExpected:\n%s\nActual:\n%s", expected, stmt)
+	}
+
+	// Tables without a primary key are not supported
+	table.PrimaryKey = nil
+	if _, err := QueryTableData(nil, &table, nil); err == nil {
+		t.Error("Expected QueryTableData to return an error for a table without a primary key, but it did not")
+	}
+}
+
+func TestTableDataSelectQuery(t *testing.T) {
+	table := aTable(1)
+	data := &TableData{
+		Table:   &table,
+		Columns: table.Columns[0:2],
+	}
+	cases := []struct {
+		limit    int
+		fraction float64
+		expected string
+	}{
+		{0, 1, "SELECT `actor_id`, `first_name` FROM `actor` ORDER BY `actor_id`"},
+		{10, 1, "SELECT `actor_id`, `first_name` FROM `actor` ORDER BY `actor_id` LIMIT 10"},
+		{10, 0.25, "SELECT `actor_id`, `first_name` FROM `actor` WHERE RAND() < 0.25 ORDER BY `actor_id` LIMIT 10"},
+		{100, 0.00001, "SELECT `actor_id`, `first_name` FROM `actor` WHERE RAND() < 1e-05 ORDER BY `actor_id` LIMIT 100"},
+	}
+	for _, c := range cases {
+		if actual := data.selectQuery(c.limit, c.fraction); actual != c.expected {
+			t.Errorf("Unexpected result from selectQuery(%d, %g):\nExpected: %s\nActual:   %s", c.limit, c.fraction, c.expected, actual)
+		}
+	}
+
+	// Tables without a primary key are not ordered
+	table.PrimaryKey = nil
+	if actual, expected := data.selectQuery(5, 1), "SELECT `actor_id`, `first_name` FROM `actor` LIMIT 5"; actual != expected {
+		t.Errorf("Unexpected result from selectQuery:\nExpected: %s\nActual:   %s", expected, actual)
+	}
+}
+
+func TestDiffTableData(t *testing.T) {
+	table := aTable(1)
+	from := &TableData{
+		Table:   &table,
+		Columns: table.Columns[0:2],
+		Rows: [][]*string{
+			dataRow("1", "Tom"),
+			dataRow("2", "Dick"),
+			dataRow("3", "Harry"),
+		},
+	}
+	to := &TableData{
+		Table:   &table,
+		Columns: table.Columns[0:3],
+		Rows: [][]*string{
+			dataRow("1", "Tom", nil),
+			dataRow("3", "Harriet", "Smith"),
+			dataRow("4", "Sally", nil),
+		},
+	}
+	diffs := DiffTableData(from, to)
+	expected := []struct {
+		diffType DiffType
+		stmt     string
+	}{
+		{DiffTypeAlter, "UPDATE `actor` SET `last_name` = NULL WHERE `actor_id` = 1"},
+		{DiffTypeAlter, "UPDATE `actor` SET `first_name` = 'Harriet', `last_name` = 'Smith' WHERE `actor_id` = 3"},
+		{DiffTypeCreate, "INSERT INTO `actor` (`actor_id`, `first_name`, `last_name`) VALUES\n  (4, 'Sally', NULL)"},
+		{DiffTypeDrop, "DELETE FROM `actor` WHERE `actor_id` = 2"},
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("Expected %d row diffs, instead found %d: %+v", len(expected), len(diffs), diffs)
+	}
+	for n, rd := range diffs {
+		if rd.DiffType() != expected[n].diffType || rd.ObjectKey() != table.ObjectKey() {
+			t.Errorf("Unexpected diff type or object key in diffs[%d]: %s %s", n, rd.DiffType(), rd.ObjectKey())
+		}
+		stmt, err := rd.Statement(StatementModifiers{})
+		if stmt != expected[n].stmt {
+			t.Errorf("Unexpected statement in diffs[%d].\nExpected: %s\nActual:   %s", n, expected[n].stmt, stmt)
+		}
+		if isDelete := (rd.DiffType() == DiffTypeDrop); isDelete != IsUnsafeDiff(err) {
+			t.Errorf("Unexpected error return from diffs[%d].Statement: %v", n, err)
+		}
+		if _, err := rd.Statement(StatementModifiers{AllowUnsafe: true}); err != nil {
+			t.Errorf("Unexpected error return from diffs[%d].Statement with AllowUnsafe: %v", n, err)
+		}
+	}
+
+	// Diffing identical data should yield no differences
+	if diffs := DiffTableData(to, to); len(diffs) != 0 {
+		t.Errorf("Expected no row diffs when comparing data to itself, instead found %+v", diffs)
+	}
+}
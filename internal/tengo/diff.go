@@ -67,6 +67,11 @@ const (
 	PartitioningKeep                               // negate REMOVE PARTITIONING clauses from ALTERs
 )
 
+// IndexDropFilter is a function which reports whether the named index of the
+// named table may be dropped. It is used in conjunction with
+// StatementModifiers.DeferIndexDrops.
+type IndexDropFilter func(tableName, indexName string) bool
+
 // StatementModifiers are options that may be applied to adjust the DDL emitted
 // for a particular table, and/or generate errors if certain clauses are
 // present.
@@ -85,6 +90,9 @@ type StatementModifiers struct {
 	CompareMetadata        bool             // If true, compare creation-time sql_mode and db collation for stored programs
 	VirtualColValidation   bool             // If true, add WITH VALIDATION clause for ALTER TABLE affecting virtual columns
 	SkipPreDropAlters      bool             // If true, skip ALTERs that were only generated to make DROP TABLE faster
+	DeferIndexDrops        bool             // If true, make visible secondary indexes invisible instead of dropping them (MySQL 8+, MariaDB 10.6+)
+	IndexDropReady         IndexDropFilter  // With DeferIndexDrops, determines whether already-invisible indexes may be dropped yet. Nil means always.
+	ReverseIndexDrops      bool             // If true, with DeferIndexDrops, undo deferred drops in a reversed diff: indexes being added are made visible instead, if a deferred drop would have retained them
 	Flavor                 Flavor           // Adjust generated DDL to match vendor/version. Zero value is FlavorUnknown which makes no adjustments.
 }
 
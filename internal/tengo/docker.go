@@ -8,8 +8,10 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -17,58 +19,158 @@ import (
 	"golang.org/x/term"
 )
 
-var dockerEngineArch string
+// containerEngine describes a container runtime command-line client which has
+// been confirmed to work, along with the architecture of its engine.
+type containerEngine struct {
+	cli  string // command-line client name, e.g. "docker" or "podman"
+	arch string // engine architecture, with values like those of runtime.GOARCH
+}
+
+// containerEngines tracks the requested container runtime, as well as memoized
+// results of checkDockerCLI, keyed by requested runtime. An empty-string key
+// corresponds to auto-detection. The mutex must be held when accessing any
+// other field.
+var containerEngines struct {
+	sync.Mutex
+	requested string
+	resolved  map[string]containerEngine
+}
+
+// ContainerRuntimes lists the supported container runtime command-line clients,
+// in the order that they are checked when auto-detecting.
+var ContainerRuntimes = []string{"docker", "podman", "nerdctl"}
 
 var ErrNoDockerCLI = errors.New("unable to find `docker` command-line client among directories in PATH")
 
-// checkDockerCLI confirms that we have a working `docker` command-line client
-// binary on the PATH, and it can communicate with a Docker Engine server and
-// fetch the engine's architecture. If successful, this result is memoized so
-// that subsequent calls have no effect.
+// SetContainerRuntime selects the container runtime command-line client to use
+// for subsequent creation or lookup of containers. The supplied name should be
+// one of the values in ContainerRuntimes, or "auto" or an empty string to use
+// the first of those which is present on the PATH. Existing DockerizedInstance
+// values are not affected, since each one retains the client that was used to
+// create or look it up.
+func SetContainerRuntime(name string) error {
+	if name == "auto" {
+		name = ""
+	} else if name != "" && !slices.Contains(ContainerRuntimes, name) {
+		return fmt.Errorf("unsupported container runtime %q", name)
+	}
+	containerEngines.Lock()
+	defer containerEngines.Unlock()
+	containerEngines.requested = name
+	return nil
+}
+
+// ContainerRuntime returns the name of the container runtime command-line
+// client in use, such as "docker" or "podman".
+func ContainerRuntime() (string, error) {
+	engine, err := checkDockerCLI()
+	return engine.cli, err
+}
+
+// checkDockerCLI confirms that we have a working command-line client binary on
+// the PATH for the container runtime selected by SetContainerRuntime, and it
+// can communicate with its engine and fetch the engine's architecture. If
+// successful, this result is memoized for the selected runtime, so that
+// subsequent calls do not need to shell out again.
 // This should be called at the start of any exported function that interacts
-// with Docker. It does not need to be called from DockerizedInstance methods
-// though, since if we already have a DockerizedInstance value, it means we've
-// successfully interacted with Docker already.
-func checkDockerCLI() error {
-	if dockerEngineArch != "" {
-		return nil
-	}
-	out, errOut, err := shellout.New(`docker info --format "{{json .}}"`).RunCaptureSeparate()
+// with containers, other than DockerizedInstance methods, which instead use the
+// client that was resolved when the DockerizedInstance was created.
+func checkDockerCLI() (containerEngine, error) {
+	containerEngines.Lock()
+	defer containerEngines.Unlock()
+	requested := containerEngines.requested
+	if engine, ok := containerEngines.resolved[requested]; ok {
+		return engine, nil
+	}
+	cli := requested
+	if cli == "" {
+		cli = ContainerRuntimes[0]
+		for _, candidate := range ContainerRuntimes {
+			if _, err := exec.LookPath(candidate); err == nil {
+				cli = candidate
+				break
+			}
+		}
+	}
+	out, errOut, err := shellout.New(cli + ` info --format "{{json .}}"`).RunCaptureSeparate()
 	if err != nil {
-		if _, pathErr := exec.LookPath("docker"); pathErr != nil && out == "" {
-			return ErrNoDockerCLI
+		if _, pathErr := exec.LookPath(cli); pathErr != nil && out == "" {
+			if cli == "docker" {
+				return containerEngine{}, ErrNoDockerCLI
+			}
+			return containerEngine{}, fmt.Errorf("unable to find `%s` command-line client among directories in PATH", cli)
 		}
-		return fmt.Errorf("error invoking `docker` command-line client: %w: %s", err, errOut)
+		return containerEngine{}, fmt.Errorf("error invoking `%s` command-line client: %w: %s", cli, err, errOut)
 	}
+
+	// Docker and nerdctl report Architecture at the top level, whereas podman
+	// reports it as host.arch
 	result := struct {
 		ServerErrors []string
 		Architecture string
+		Host         struct {
+			Arch string
+		}
 	}{}
 	if err := json.Unmarshal([]byte(out), &result); err != nil {
-		return fmt.Errorf("error decoding JSON response from `docker` command-line client: %w", err)
+		return containerEngine{}, fmt.Errorf("error decoding JSON response from `%s` command-line client: %w", cli, err)
 	}
 	if len(result.ServerErrors) > 0 {
-		return fmt.Errorf("error response from Docker engine: %s", strings.Join(result.ServerErrors, "; "))
+		return containerEngine{}, fmt.Errorf("error response from %s engine: %s", cli, strings.Join(result.ServerErrors, "; "))
+	}
+	arch := result.Architecture
+	if arch == "" {
+		arch = result.Host.Arch
+	}
+	if arch == "" {
+		return containerEngine{}, fmt.Errorf("unable to determine engine architecture from `%s info` output", cli)
 	}
-
-	dockerEngineArch = result.Architecture
 	conversions := map[string]string{
 		"x86_64":  "amd64",
 		"aarch64": "arm64",
 	}
-	if converted, ok := conversions[dockerEngineArch]; ok {
-		dockerEngineArch = converted
+	if converted, ok := conversions[arch]; ok {
+		arch = converted
 	}
-	return nil
+	engine := containerEngine{cli: cli, arch: arch}
+	if containerEngines.resolved == nil {
+		containerEngines.resolved = make(map[string]containerEngine)
+	}
+	containerEngines.resolved[requested] = engine
+	return engine, nil
 }
 
-// DockerEngineArchitecture returns the architecture of the Docker engine's
+// DockerEngineArchitecture returns the architecture of the container engine's
 // server, with values like those of runtime.GOARCH. The result is typically the
 // same as runtime.GOARCH in most situations, but may differ from GOARCH if e.g.
 // running an amd64 Skeema binary on Apple Silicon via Rosetta 2.
 func DockerEngineArchitecture() (string, error) {
-	err := checkDockerCLI()
-	return dockerEngineArch, err
+	engine, err := checkDockerCLI()
+	return engine.arch, err
+}
+
+// QualifiedImageName returns image in a form suitable for the supplied
+// container runtime. Podman does not assume Docker Hub for short image names,
+// and may fail or prompt when resolving them, so for podman the image is fully
+// qualified with Docker Hub's registry. Other runtimes receive image unchanged.
+func QualifiedImageName(image, runtime string) string {
+	if runtime != "podman" {
+		return image
+	}
+	first, _, hasSlash := strings.Cut(image, "/")
+	if !hasSlash {
+		return "docker.io/library/" + image
+	} else if strings.ContainsAny(first, ".:") || first == "localhost" { // already has a registry
+		return image
+	}
+	return "docker.io/" + image
+}
+
+// unqualifiedImageName strips the Docker Hub registry and "library/" namespace
+// from image, reversing QualifiedImageName.
+func unqualifiedImageName(image string) string {
+	image = strings.TrimPrefix(image, "docker.io/")
+	return strings.TrimPrefix(image, "library/")
 }
 
 // DockerizedInstanceOptions specifies options for creating or finding a
@@ -91,6 +193,7 @@ type DockerizedInstanceOptions struct {
 type DockerizedInstance struct {
 	*Instance
 	containerName    string
+	cli              string      // container runtime command-line client
 	portMap          map[int]int // keys are container ports, values are host ports
 	hasDataBindMount bool
 }
@@ -99,7 +202,8 @@ type DockerizedInstance struct {
 // Docker container. Of the opts fields, only Image is mandatory. A connection
 // pool will be established for the instance.
 func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance, error) {
-	if err := checkDockerCLI(); err != nil {
+	engine, err := checkDockerCLI()
+	if err != nil {
 		return nil, err
 	}
 	if opts.Image == "" {
@@ -125,9 +229,9 @@ func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstan
 	} else if opts.DataTmpfs {
 		// Some images require a specific uid for /var/lib/mysql
 		var uidOption string
-		if strings.HasPrefix(opts.Image, "percona/percona-server:") {
+		if image := unqualifiedImageName(opts.Image); strings.HasPrefix(image, "percona/percona-server:") {
 			uidOption = ":uid=1001"
-		} else if strings.HasPrefix(opts.Image, "percona:") {
+		} else if strings.HasPrefix(image, "percona:") {
 			uidOption = ":uid=999"
 		}
 		dflags = append(dflags, "--tmpfs /var/lib/mysql"+uidOption)
@@ -162,7 +266,7 @@ func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstan
 		"NAME":          opts.Name,
 		"DATABINDMOUNT": opts.DataBindMount + ":/var/lib/mysql",
 	}
-	dockerRunCmd := "docker run " + flagString + " " + opts.Image + argString
+	dockerRunCmd := engine.cli + " run " + flagString + " " + opts.Image + argString
 	c := shellout.New(dockerRunCmd).WithVariablesStrict(vars)
 	out, errOut, err := c.RunCaptureSeparate()
 	if err != nil {
@@ -171,7 +275,7 @@ func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstan
 	if opts.Name == "" {
 		opts.Name = strings.TrimSpace(out)
 	}
-	return newDockerizedInstance(opts)
+	return newDockerizedInstance(opts, engine.cli)
 }
 
 // GetInstance attempts to find an existing container with name equal to
@@ -183,17 +287,18 @@ func CreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstan
 // a different image, the instance's flavor will be examined as a fallback. If
 // it also does not match the requested image, an error will be returned.
 func GetDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance, error) {
-	if err := checkDockerCLI(); err != nil {
+	engine, err := checkDockerCLI()
+	if err != nil {
 		return nil, err
 	}
 	if opts.Name == "" {
 		return nil, errors.New("GetDockerizedInstance: Name field cannot be empty string")
 	}
 
-	if err := StartDockerContainer(opts.Name); err != nil {
+	if err := startContainer(engine.cli, opts.Name); err != nil {
 		return nil, err
 	}
-	di, err := newDockerizedInstance(opts)
+	di, err := newDockerizedInstance(opts, engine.cli)
 	if err != nil {
 		return nil, err
 	}
@@ -213,10 +318,12 @@ func GetDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance,
 
 // newDockerizedInstance creates a new DockerizedInstance value with all fields
 // populated. The container referred to in opts.Name should already exist and be
-// in the running state prior to calling this function.
-func newDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance, error) {
+// in the running state prior to calling this function. All future interactions
+// with the container will use the supplied container runtime client cli.
+func newDockerizedInstance(opts DockerizedInstanceOptions, cli string) (*DockerizedInstance, error) {
 	di := &DockerizedInstance{
 		containerName:    opts.Name,
+		cli:              cli,
 		hasDataBindMount: (opts.DataBindMount != ""),
 	}
 	if err := di.hydratePortMap(); err != nil {
@@ -238,7 +345,7 @@ func newDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedInstance,
 		vars := map[string]string{
 			"NAME": opts.Name,
 		}
-		s := shellout.New("docker logs --tail 100 {NAME}").WithVariablesStrict(vars)
+		s := shellout.New(di.cli + " logs --tail 100 {NAME}").WithVariablesStrict(vars)
 		if logs, logErr := s.RunCaptureCombined(); logErr == nil {
 			err = fmt.Errorf("%w\nLast 100 lines of container logs:\n%s", err, logs)
 		}
@@ -261,7 +368,7 @@ func (di *DockerizedInstance) hydratePortMap() (err error) {
 	vars := map[string]string{
 		"NAME": di.containerName,
 	}
-	inspectCommand := `docker inspect --type container --format="{{json .NetworkSettings.Ports}}" {NAME}`
+	inspectCommand := di.cli + ` inspect --type container --format="{{json .NetworkSettings.Ports}}" {NAME}`
 	c := shellout.New(inspectCommand).WithVariablesStrict(vars)
 
 	// Attempt this up to 5 times, since the port mapping often isn't immediately
@@ -275,7 +382,7 @@ func (di *DockerizedInstance) hydratePortMap() (err error) {
 		}
 		var ports map[string][]map[string]string
 		if err = json.Unmarshal([]byte(out), &ports); err != nil {
-			err = fmt.Errorf("unable to decode JSON response from `docker` command-line client: %w", err)
+			err = fmt.Errorf("unable to decode JSON response from `%s` command-line client: %w", di.cli, err)
 			continue
 		}
 		for containerPortProto, hostPortInfos := range ports {
@@ -288,6 +395,21 @@ func (di *DockerizedInstance) hydratePortMap() (err error) {
 		}
 	}
 
+	// Some runtimes, such as nerdctl in some configurations, may omit port
+	// mappings from inspect output, so fall back to the port subcommand, which
+	// outputs lines of form "127.0.0.1:12345"
+	if di.portMap[3306] == 0 {
+		if out, portErr := shellout.New(di.cli + " port {NAME} 3306/tcp").WithVariablesStrict(vars).RunCaptureCombined(); portErr == nil {
+			firstLine, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+			if pos := strings.LastIndex(firstLine, ":"); pos > -1 {
+				if hostPort, _ := strconv.Atoi(firstLine[pos+1:]); hostPort > 0 {
+					di.portMap[3306] = hostPort
+					err = nil
+				}
+			}
+		}
+	}
+
 	if err != nil {
 		err = fmt.Errorf("Unable to find port mapping for container %s: %w", di.containerName, err)
 	} else if di.portMap[3306] == 0 {
@@ -334,7 +456,7 @@ func GetOrCreateDockerizedInstance(opts DockerizedInstanceOptions) (*DockerizedI
 // server for the first time, or re-initializing in the case of a tmpfs data
 // directory mount. Use TryConnect to wait if needed.
 func (di *DockerizedInstance) Start() error {
-	if err := StartDockerContainer(di.containerName); err != nil {
+	if err := startContainer(di.cli, di.containerName); err != nil {
 		return err
 	}
 	// Randomly-assigned port on host side will have changed
@@ -348,15 +470,26 @@ func (di *DockerizedInstance) Start() error {
 	return nil
 }
 
-// StartDockerContainer shells out to `docker start` for the supplied container
-// name. If a non-zero exit code is returned, any STDOUT and STDERR output will
-// be captured and included in the returned error's message. If the container is
-// already running, nil is returned.
+// StartDockerContainer shells out to `docker start` (or the equivalent for the
+// selected container runtime) for the supplied container name. If a non-zero
+// exit code is returned, any STDOUT and STDERR output will be captured and
+// included in the returned error's message. If the container is already
+// running, nil is returned.
 func StartDockerContainer(name string) error {
+	engine, err := checkDockerCLI()
+	if err != nil {
+		return err
+	}
+	return startContainer(engine.cli, name)
+}
+
+// startContainer shells out to the supplied container runtime client to start
+// the named container.
+func startContainer(cli, name string) error {
 	vars := map[string]string{
 		"NAME": name,
 	}
-	s := shellout.New("docker start {NAME}").WithVariablesStrict(vars)
+	s := shellout.New(cli + " start {NAME}").WithVariablesStrict(vars)
 	out, err := s.RunCaptureCombined()
 	if err != nil {
 		err = fmt.Errorf("%w: %s", err, out)
@@ -370,18 +503,29 @@ func StartDockerContainer(name string) error {
 func (di *DockerizedInstance) Stop() error {
 	di.CloseAll()
 	di.portMap = nil
-	return StopDockerContainer(di.containerName)
+	return stopContainer(di.cli, di.containerName)
 }
 
-// StopDockerContainer shells out to `docker stop` for the supplied container
-// name. If a non-zero exit code is returned, any STDOUT and STDERR output will
-// be captured and included in the returned error's message. If the container
-// was already stopped, nil is returned.
+// StopDockerContainer shells out to `docker stop` (or the equivalent for the
+// selected container runtime) for the supplied container name. If a non-zero
+// exit code is returned, any STDOUT and STDERR output will be captured and
+// included in the returned error's message. If the container was already
+// stopped, nil is returned.
 func StopDockerContainer(name string) error {
+	engine, err := checkDockerCLI()
+	if err != nil {
+		return err
+	}
+	return stopContainer(engine.cli, name)
+}
+
+// stopContainer shells out to the supplied container runtime client to stop
+// the named container.
+func stopContainer(cli, name string) error {
 	vars := map[string]string{
 		"NAME": name,
 	}
-	s := shellout.New("docker stop {NAME}").WithVariablesStrict(vars)
+	s := shellout.New(cli + " stop {NAME}").WithVariablesStrict(vars)
 	out, err := s.RunCaptureCombined()
 	if err != nil {
 		err = fmt.Errorf("%w: %s", err, out)
@@ -396,7 +540,7 @@ func (di *DockerizedInstance) Destroy() error {
 	vars := map[string]string{
 		"NAME": di.containerName,
 	}
-	s := shellout.New("docker rm -v -f {NAME}").WithVariablesStrict(vars)
+	s := shellout.New(di.cli + " rm -v -f {NAME}").WithVariablesStrict(vars)
 	_, err := s.RunCaptureCombined()
 	return err
 }
@@ -469,20 +613,20 @@ func (di *DockerizedInstance) Exec(cmd []string, stdin io.Reader) (stdoutStr str
 		cmdPlaceholders[n] = "{" + varKey + "}"
 	}
 
-	commandString := "docker exec " + strings.Join(dflags, " ") + " {NAME} " + strings.Join(cmdPlaceholders, " ")
+	commandString := di.cli + " exec " + strings.Join(dflags, " ") + " {NAME} " + strings.Join(cmdPlaceholders, " ")
 	s := shellout.New(commandString).WithStdin(stdin).WithVariablesStrict(vars)
 	return s.RunCaptureSeparate()
 }
 
 // PutFile copies a file or directory from the host to the container by shelling
-// out to `docker cp`. For edge cases involving directories, nonexistent paths,
+// out to `docker cp` (or the equivalent for the selected container runtime). For edge cases involving directories, nonexistent paths,
 // etc refer to https://docs.docker.com/reference/cli/docker/container/cp/.
 func (di *DockerizedInstance) PutFile(src, dest string) error {
 	vars := map[string]string{
 		"SRC":  src,
 		"DEST": di.containerName + ":" + dest,
 	}
-	out, err := shellout.New("docker cp {SRC} {DEST}").WithVariablesStrict(vars).RunCaptureCombined()
+	out, err := shellout.New(di.cli + " cp {SRC} {DEST}").WithVariablesStrict(vars).RunCaptureCombined()
 	if err != nil {
 		return fmt.Errorf("%w: %s", err, out)
 	}
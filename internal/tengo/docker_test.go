@@ -1,6 +1,7 @@
 package tengo
 
 import (
+	"errors"
 	"os"
 	"runtime"
 	"strings"
@@ -121,8 +122,14 @@ func TestDocker(t *testing.T) {
 
 func TestDockerCLIMissing(t *testing.T) {
 	t.Setenv("PATH", "")
-	dockerEngineArch = "" // clear cached value
-	if err := checkDockerCLI(); err == nil {
+	clearContainerEngines := func() {
+		containerEngines.Lock()
+		containerEngines.resolved = nil
+		containerEngines.Unlock()
+	}
+	clearContainerEngines()
+	defer clearContainerEngines()
+	if _, err := checkDockerCLI(); err == nil {
 		t.Error("Expected checkDockerCLI to fail with blank PATH, but err was nil")
 	}
 
@@ -139,6 +146,64 @@ func TestDockerCLIMissing(t *testing.T) {
 	}
 }
 
+func TestSetContainerRuntime(t *testing.T) {
+	t.Setenv("PATH", "")
+	defer SetContainerRuntime("auto")
+	if err := SetContainerRuntime("rkt"); err == nil {
+		t.Error("Expected SetContainerRuntime to return an error for unsupported runtime, but err was nil")
+	}
+	if err := SetContainerRuntime("podman"); err != nil {
+		t.Fatalf("Unexpected error from SetContainerRuntime: %v", err)
+	}
+	if _, err := ContainerRuntime(); err == nil || errors.Is(err, ErrNoDockerCLI) || !strings.Contains(err.Error(), "podman") {
+		t.Errorf("Expected ContainerRuntime to return a podman-specific error with blank PATH, instead found %v", err)
+	}
+
+	// Switching runtimes does not discard the memoized result of another runtime
+	containerEngines.Lock()
+	origResolved := containerEngines.resolved
+	containerEngines.resolved = map[string]containerEngine{"nerdctl": {cli: "nerdctl", arch: "arm64"}}
+	containerEngines.Unlock()
+	defer func() {
+		containerEngines.Lock()
+		containerEngines.resolved = origResolved
+		containerEngines.Unlock()
+	}()
+	if err := SetContainerRuntime("nerdctl"); err != nil {
+		t.Fatalf("Unexpected error from SetContainerRuntime: %v", err)
+	}
+	if runtime, err := ContainerRuntime(); runtime != "nerdctl" || err != nil {
+		t.Errorf("Expected ContainerRuntime to return memoized nerdctl, instead found %q, %v", runtime, err)
+	}
+	if arch, err := DockerEngineArchitecture(); arch != "arm64" || err != nil {
+		t.Errorf("Expected DockerEngineArchitecture to return memoized arm64, instead found %q, %v", arch, err)
+	}
+}
+
+func TestQualifiedImageName(t *testing.T) {
+	testcases := []struct {
+		image    string
+		runtime  string
+		expected string
+	}{
+		{"mysql:8.4", "docker", "mysql:8.4"},
+		{"mysql:8.4", "nerdctl", "mysql:8.4"},
+		{"mysql:8.4", "podman", "docker.io/library/mysql:8.4"},
+		{"percona/percona-server:8.0", "podman", "docker.io/percona/percona-server:8.0"},
+		{"container-registry.oracle.com/mysql/community-server:8.1", "podman", "container-registry.oracle.com/mysql/community-server:8.1"},
+		{"docker.io/library/mariadb:11.4", "podman", "docker.io/library/mariadb:11.4"},
+	}
+	for _, tc := range testcases {
+		actual := QualifiedImageName(tc.image, tc.runtime)
+		if actual != tc.expected {
+			t.Errorf("Expected QualifiedImageName(%q, %q) to return %q, instead found %q", tc.image, tc.runtime, tc.expected, actual)
+		}
+		if unqualifiedImageName(actual) != tc.image && tc.runtime == "podman" && !strings.Contains(tc.image, ".") {
+			t.Errorf("Expected unqualifiedImageName(%q) to return %q, instead found %q", actual, tc.image, unqualifiedImageName(actual))
+		}
+	}
+}
+
 func TestContainerNameForImage(t *testing.T) {
 	testcases := map[string]string{
 		"mysql:8.4":                                                "mysql-8.4",
@@ -155,6 +220,8 @@ func TestContainerNameForImage(t *testing.T) {
 		"mysql/mysql-server:8.0":                                   "mysql-8.0",
 		"container-registry.oracle.com/mysql/community-server:8.1": "mysql-8.1",
 		"weird-tagless-value/mysql-server":                         "mysql",
+		"docker.io/library/mysql:8.4":                              "mysql-8.4",
+		"docker.io/percona/percona-server:8.0.37-aarch64":          "percona-8.0.37",
 	}
 	for input, expected := range testcases {
 		if actual := ContainerNameForImage(input); actual != expected {
@@ -29,6 +29,15 @@ const (
 
 	ER_ACCESS_DENIED_ERROR          = 1045
 	ER_SPECIFIC_ACCESS_DENIED_ERROR = 1227
+
+	ER_DUP_ENTRY                 = 1062
+	ER_INVALID_USE_OF_NULL       = 1138
+	ER_WARN_DATA_OUT_OF_RANGE    = 1264
+	WARN_DATA_TRUNCATED          = 1265
+	ER_DATA_TOO_LONG             = 1406
+	ER_TRUNCATED_WRONG_VALUE     = 1292
+	ER_CHECK_CONSTRAINT_VIOLATED = 3819 // MySQL
+	ER_CONSTRAINT_FAILED         = 4025 // MariaDB
 )
 
 // IsDatabaseError returns true if err came from a database server, typically
@@ -91,3 +100,22 @@ func IsAccessDeniedError(err error) bool {
 func IsAccessPrivilegeError(err error) bool {
 	return IsDatabaseError(err, ER_SPECIFIC_ACCESS_DENIED_ERROR)
 }
+
+// IsDuplicateKeyError returns true if err indicates a unique or primary key
+// constraint violation.
+func IsDuplicateKeyError(err error) bool {
+	return IsDatabaseError(err, ER_DUP_ENTRY)
+}
+
+// IsTruncationError returns true if err indicates that a value could not be
+// stored in a column without truncation, or without violating the column's
+// nullability or range, when using a strict sql_mode.
+func IsTruncationError(err error) bool {
+	return IsDatabaseError(err, ER_INVALID_USE_OF_NULL, ER_WARN_DATA_OUT_OF_RANGE, WARN_DATA_TRUNCATED, ER_DATA_TOO_LONG, ER_TRUNCATED_WRONG_VALUE)
+}
+
+// IsCheckConstraintError returns true if err indicates a row violated a check
+// constraint.
+func IsCheckConstraintError(err error) bool {
+	return IsDatabaseError(err, ER_CHECK_CONSTRAINT_VIOLATED, ER_CONSTRAINT_FAILED)
+}
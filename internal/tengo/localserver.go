@@ -0,0 +1,307 @@
+package tengo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LocalServerOptions specifies options for finding or starting a sandboxed
+// database server process on localhost, using a mysqld or mariadbd binary.
+type LocalServerOptions struct {
+	BinaryPath        string // Path to mysqld or mariadbd binary
+	BaseDir           string // Directory for the server's data dir, socket, pid file, and error log; created if missing, and must be private to the current user
+	DefaultConnParams string // Options formatted as URL query string, used for conns to the server
+
+	// Options that only affect new data directory initialization:
+	LowerCaseTableNames uint8 // lower_case_table_names setting (0 or 1) in database server
+}
+
+// LocalServer is a database instance running as a child process on localhost,
+// listening only on a Unix domain socket. Its data directory, socket, and logs
+// are all stored inside of a single base directory.
+type LocalServer struct {
+	*Instance
+	binaryPath string
+	baseDir    string
+	lctn       uint8
+	exited     chan struct{} // closed when server exits, if started by this process
+}
+
+// Regular expression for parsing the output of `mysqld --version`, for example
+// "/usr/sbin/mysqld  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)"
+var reServerVersion = regexp.MustCompile(`Ver\s+(\S+).*?(?:\((.*)\))?\s*$`)
+
+// LocalServerFlavor runs the supplied mysqld or mariadbd binary with --version,
+// and returns the corresponding Flavor.
+func LocalServerFlavor(binaryPath string) (Flavor, error) {
+	out, err := exec.Command(binaryPath, "--version").Output()
+	if err != nil {
+		return FlavorUnknown, fmt.Errorf("unable to run %s --version: %w", binaryPath, err)
+	}
+	flavor := flavorFromVersionOutput(string(out))
+	if flavor == FlavorUnknown {
+		return FlavorUnknown, fmt.Errorf("unable to determine flavor from output of %s --version: %q", binaryPath, out)
+	}
+	return flavor, nil
+}
+
+// flavorFromVersionOutput parses the output of `mysqld --version` or
+// `mariadbd --version` into a Flavor. FlavorUnknown is returned if parsing
+// fails.
+func flavorFromVersionOutput(out string) Flavor {
+	matches := reServerVersion.FindStringSubmatch(strings.TrimSpace(out))
+	if matches == nil {
+		return FlavorUnknown
+	}
+	flavor := IdentifyFlavor(matches[1], matches[2])
+	if flavor.Vendor == VendorUnknown {
+		return FlavorUnknown
+	}
+	return flavor
+}
+
+// GetOrCreateLocalServer returns a LocalServer for the supplied options. If a
+// server is already running using opts.BaseDir, it is used directly. Otherwise,
+// a data directory is initialized if one does not already exist, and a new
+// server process is started. In all cases, a connection pool will be
+// established for the instance.
+func GetOrCreateLocalServer(opts LocalServerOptions) (*LocalServer, error) {
+	if opts.BinaryPath == "" || opts.BaseDir == "" {
+		return nil, errors.New("GetOrCreateLocalServer: BinaryPath and BaseDir fields cannot be empty string")
+	}
+	if err := os.MkdirAll(opts.BaseDir, 0700); err != nil {
+		return nil, err
+	} else if err := checkPrivateDir(opts.BaseDir); err != nil {
+		return nil, fmt.Errorf("unable to use base directory for local server: %w", err)
+	}
+	ls := &LocalServer{
+		binaryPath: opts.BinaryPath,
+		baseDir:    opts.BaseDir,
+		lctn:       opts.LowerCaseTableNames,
+	}
+	dsn := fmt.Sprintf("root@unix(%s)/?%s", ls.SocketPath(), opts.DefaultConnParams)
+	if inst, err := NewInstance("mysql", dsn); err != nil {
+		return nil, err
+	} else {
+		ls.Instance = inst
+	}
+
+	// If a server is already listening on the socket, just use it
+	if _, err := os.Stat(ls.SocketPath()); err == nil {
+		if ok, _ := ls.Instance.CanConnect(); ok {
+			return ls, nil
+		}
+	}
+
+	if _, err := os.Stat(ls.dataDir()); errors.Is(err, os.ErrNotExist) {
+		if err := ls.initialize(); err != nil {
+			// Remove any partially-initialized data dir, so that a subsequent attempt
+			// starts from scratch
+			os.RemoveAll(ls.dataDir())
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if err := ls.Start(); err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
+
+// initialize creates a new data directory for the server, with a passwordless
+// root@localhost user.
+func (ls *LocalServer) initialize() error {
+	flavor, err := LocalServerFlavor(ls.binaryPath)
+	if err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	if flavor.IsMariaDB() {
+		// MariaDB's server binary does not support --initialize-insecure, so its
+		// install script must be used instead
+		script, err := ls.mariaDBInstallScript()
+		if err != nil {
+			return err
+		}
+		cmd = exec.Command(script,
+			"--no-defaults",
+			"--datadir="+ls.dataDir(),
+			"--basedir="+filepath.Dir(filepath.Dir(ls.binaryPath)),
+			"--auth-root-authentication-method=normal",
+			"--skip-test-db",
+		)
+	} else {
+		cmd = exec.Command(ls.binaryPath,
+			"--no-defaults",
+			"--initialize-insecure",
+			"--datadir="+ls.dataDir(),
+			"--log-error="+ls.errorLogPath(),
+			fmt.Sprintf("--lower-case-table-names=%d", ls.lctn),
+		)
+		if os.Geteuid() == 0 {
+			cmd.Args = append(cmd.Args, "--user=root")
+		}
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to initialize data directory %s: %w\n%s%s", ls.dataDir(), err, out, ls.errorLogTail())
+	}
+	return nil
+}
+
+// mariaDBInstallScript returns the path to MariaDB's data directory
+// installation script, looking in locations relative to the server binary
+// before falling back to the PATH.
+func (ls *LocalServer) mariaDBInstallScript() (string, error) {
+	binDir := filepath.Dir(ls.binaryPath)
+	for _, name := range []string{"mariadb-install-db", "mysql_install_db"} {
+		for _, dir := range []string{binDir, filepath.Join(binDir, "..", "bin"), filepath.Join(binDir, "..", "scripts")} {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+		}
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("unable to find mariadb-install-db script in %s or among directories in PATH", binDir)
+}
+
+// Start starts the server process using the existing data directory, and waits
+// for it to accept connections. The process is not tied to the lifetime of the
+// calling program; use Stop to shut it down.
+func (ls *LocalServer) Start() error {
+	args := []string{
+		"--no-defaults", // must be first arg
+		"--datadir=" + ls.dataDir(),
+		"--socket=" + ls.SocketPath(),
+		"--pid-file=" + filepath.Join(ls.baseDir, "mysqld.pid"),
+		"--log-error=" + ls.errorLogPath(),
+		"--skip-networking",
+		"--skip-log-bin",
+		"--loose-mysqlx=OFF", // loose- prefix: option is ignored in flavors lacking X Protocol
+		fmt.Sprintf("--lower-case-table-names=%d", ls.lctn),
+	}
+	if os.Geteuid() == 0 {
+		args = append(args, "--user=root")
+	}
+	cmd := exec.Command(ls.binaryPath, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to start %s: %w", ls.binaryPath, err)
+	}
+	ls.exited = make(chan struct{})
+	go func(exited chan struct{}) {
+		cmd.Wait()
+		close(exited)
+	}(ls.exited)
+
+	if err := ls.TryConnect(); err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("%w%s", err, ls.errorLogTail())
+	}
+	return nil
+}
+
+// TryConnect sets up a connection pool to the server, and tests connectivity.
+// It returns an error if a connection cannot be established within 60 seconds,
+// or if the server process exits first.
+func (ls *LocalServer) TryConnect() (err error) {
+	var ok bool
+	for attempts := 0; attempts < 240; attempts++ {
+		if ok, err = ls.Instance.CanConnect(); ok {
+			return err
+		}
+		select {
+		case <-ls.exited:
+			return fmt.Errorf("server process %s exited unexpectedly", ls.binaryPath)
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+	return err
+}
+
+// Stop shuts down the server, leaving its data directory in place for use by a
+// subsequent call to Start.
+func (ls *LocalServer) Stop() error {
+	db, err := ls.Instance.CachedConnectionPool("", "")
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec("SHUTDOWN"); err != nil {
+		return err
+	}
+	ls.Instance.CloseAll()
+
+	// Wait for the process to exit. If it wasn't started by this process, the
+	// removal of its socket file indicates shutdown completion.
+	deadline := time.Now().Add(60 * time.Second)
+	for time.Now().Before(deadline) {
+		if ls.exited != nil {
+			select {
+			case <-ls.exited:
+				return nil
+			case <-time.After(250 * time.Millisecond):
+			}
+		} else if _, err := os.Stat(ls.SocketPath()); errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else {
+			time.Sleep(250 * time.Millisecond)
+		}
+	}
+	return fmt.Errorf("timed out waiting for %s to shut down", ls)
+}
+
+// Destroy shuts down the server, and then deletes its base directory, including
+// the data directory.
+func (ls *LocalServer) Destroy() error {
+	if err := ls.Stop(); err != nil {
+		return err
+	}
+	return os.RemoveAll(ls.baseDir)
+}
+
+// BaseDir returns the directory containing the server's data directory,
+// socket, and logs.
+func (ls *LocalServer) BaseDir() string {
+	return ls.baseDir
+}
+
+// SocketPath returns the path to the server's Unix domain socket.
+func (ls *LocalServer) SocketPath() string {
+	return filepath.Join(ls.baseDir, "mysqld.sock")
+}
+
+func (ls *LocalServer) dataDir() string {
+	return filepath.Join(ls.baseDir, "data")
+}
+
+func (ls *LocalServer) errorLogPath() string {
+	return filepath.Join(ls.baseDir, "error.log")
+}
+
+// errorLogTail returns the last several lines of the server's error log,
+// prefixed with a newline and header, or an empty string if the error log
+// cannot be read.
+func (ls *LocalServer) errorLogTail() string {
+	contents, err := os.ReadFile(ls.errorLogPath())
+	if err != nil || len(contents) == 0 {
+		return ""
+	}
+	lines := bytes.Split(bytes.TrimSpace(contents), []byte("\n"))
+	if len(lines) > 20 {
+		lines = lines[len(lines)-20:]
+	}
+	return fmt.Sprintf("\nLast %d lines of server error log:\n%s", len(lines), bytes.Join(lines, []byte("\n")))
+}
+
+func (ls *LocalServer) String() string {
+	return "LocalServer:" + ls.SocketPath()
+}
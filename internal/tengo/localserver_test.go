@@ -0,0 +1,128 @@
+package tengo
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFlavorFromVersionOutput(t *testing.T) {
+	cases := map[string]string{
+		"/usr/sbin/mysqld  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)\n":                                 "mysql:8.0.36",
+		"/usr/local/mysql/bin/mysqld  Ver 8.4.3 for macos14 on arm64 (MySQL Community Server - GPL)":                        "mysql:8.4.3",
+		"/usr/sbin/mysqld  Ver 8.0.39-30 for Linux on x86_64 (Percona Server (GPL), Release '30', Revision 'fec2a6b7')":     "percona:8.0.39",
+		"/usr/sbin/mariadbd  Ver 11.4.2-MariaDB-ubu2404 for debian-linux-gnu on x86_64 (mariadb.org binary distribution)\n": "mariadb:11.4.2",
+		"mariadbd  Ver 10.6.18-MariaDB for Linux on x86_64 (MariaDB Server)":                                                "mariadb:10.6.18",
+		"mysqld  Ver 8.0.36":   "mysql:8.0.36",
+		"something unexpected": "",
+		"":                     "",
+	}
+	for input, expected := range cases {
+		flavor := flavorFromVersionOutput(input)
+		if expected == "" {
+			if flavor != FlavorUnknown {
+				t.Errorf("Expected flavorFromVersionOutput(%q) to return FlavorUnknown, instead found %s", input, flavor)
+			}
+		} else if flavor.String() != expected {
+			t.Errorf("Expected flavorFromVersionOutput(%q) to return %s, instead found %s", input, expected, flavor)
+		}
+	}
+}
+
+func TestCheckPrivateDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Private dir checks are not performed on Windows")
+	}
+	dirPath := filepath.Join(t.TempDir(), "private")
+	if err := os.Mkdir(dirPath, 0700); err != nil {
+		t.Fatalf("Unable to create dir: %v", err)
+	}
+	if err := checkPrivateDir(dirPath); err != nil {
+		t.Errorf("Unexpected error from checkPrivateDir on private dir: %v", err)
+	}
+
+	// Symlinks, regular files, and dirs accessible to other users are rejected
+	linkPath := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dirPath, linkPath); err != nil {
+		t.Fatalf("Unable to create symlink: %v", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(filePath, []byte("hello"), 0600); err != nil {
+		t.Fatalf("Unable to create file: %v", err)
+	}
+	for _, path := range []string{linkPath, filePath, filepath.Join(t.TempDir(), "missing")} {
+		if err := checkPrivateDir(path); err == nil {
+			t.Errorf("Expected error from checkPrivateDir(%q), but err was nil", path)
+		}
+	}
+	if err := os.Chmod(dirPath, 0755); err != nil {
+		t.Fatalf("Unable to chmod dir: %v", err)
+	}
+	if err := checkPrivateDir(dirPath); err == nil {
+		t.Error("Expected error from checkPrivateDir on dir with mode 0755, but err was nil")
+	}
+
+	// GetOrCreateLocalServer should refuse to use a dir which is not private,
+	// before attempting to connect to any socket inside of it
+	opts := LocalServerOptions{BinaryPath: "mysqld", BaseDir: dirPath}
+	if _, err := GetOrCreateLocalServer(opts); err == nil {
+		t.Error("Expected error from GetOrCreateLocalServer with non-private base dir, but err was nil")
+	}
+	if _, err := GetOrCreateLocalServer(LocalServerOptions{BaseDir: dirPath}); err == nil {
+		t.Error("Expected error from GetOrCreateLocalServer with blank BinaryPath, but err was nil")
+	}
+}
+
+// TestLocalServerLifecycle initializes, starts, stops, restarts, and destroys
+// a LocalServer. It requires env var SKEEMA_TEST_LOCAL_BINARY to be set to the
+// path of a mysqld or mariadbd binary.
+func TestLocalServerLifecycle(t *testing.T) {
+	binaryPath := os.Getenv("SKEEMA_TEST_LOCAL_BINARY")
+	if binaryPath == "" {
+		t.Skip("Skipping local server lifecycle testing. To run, set env SKEEMA_TEST_LOCAL_BINARY to the path of a mysqld or mariadbd binary.")
+	}
+	UseFilteredDriverLogger()
+	opts := LocalServerOptions{
+		BinaryPath: binaryPath,
+		BaseDir:    filepath.Join(t.TempDir(), "ls"),
+	}
+	ls, err := GetOrCreateLocalServer(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from GetOrCreateLocalServer: %v", err)
+	}
+	if ok, err := ls.CanConnect(); !ok {
+		t.Fatalf("Unable to connect to new local server: %v", err)
+	}
+	if _, err := ls.CreateSchema("lifecycle", SchemaCreationOptions{}); err != nil {
+		t.Fatalf("Unexpected error creating schema: %v", err)
+	}
+
+	// A second call should find the already-running server
+	ls2, err := GetOrCreateLocalServer(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from second GetOrCreateLocalServer: %v", err)
+	} else if ls2.exited != nil {
+		t.Error("Expected second GetOrCreateLocalServer to use running server, but a new process was started")
+	}
+
+	// After stopping, the server should be restarted using the existing data dir
+	if err := ls.Stop(); err != nil {
+		t.Fatalf("Unexpected error from Stop: %v", err)
+	}
+	if ls, err = GetOrCreateLocalServer(opts); err != nil {
+		t.Fatalf("Unexpected error from GetOrCreateLocalServer after Stop: %v", err)
+	} else if ls.exited == nil {
+		t.Error("Expected GetOrCreateLocalServer to start a new process after Stop, but it did not")
+	}
+	if has, err := ls.HasSchema("lifecycle"); err != nil || !has {
+		t.Errorf("Expected schema to persist across restart; HasSchema returned %t, %v", has, err)
+	}
+
+	if err := ls.Destroy(); err != nil {
+		t.Fatalf("Unexpected error from Destroy: %v", err)
+	}
+	if _, err := os.Stat(opts.BaseDir); !os.IsNotExist(err) {
+		t.Errorf("Expected base dir to be removed by Destroy, but Stat returned %v", err)
+	}
+}
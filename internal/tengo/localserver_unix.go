@@ -0,0 +1,34 @@
+// This file contains LocalServer functionality that is specific to UNIX-like
+// operating systems.
+
+//go:build !windows
+
+package tengo
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir confirms that path is a directory (not a symlink) which is
+// owned by the effective user of this process, and is inaccessible to other
+// users. This is required for a LocalServer's base directory, since its root
+// user has no password: any user able to reach the socket would have full
+// access to the server, and any user able to pre-create the directory could
+// substitute their own server.
+func checkPrivateDir(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Geteuid() {
+		return fmt.Errorf("%s is owned by a different user (uid %d)", path, st.Uid)
+	}
+	if perm := fi.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("%s must not be accessible by other users, but has permissions %s", path, perm)
+	}
+	return nil
+}
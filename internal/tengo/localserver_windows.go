@@ -0,0 +1,11 @@
+// This file contains LocalServer functionality that is specific to Windows.
+
+//go:build windows
+
+package tengo
+
+// checkPrivateDir is a no-op on Windows, where directory ownership and access
+// are controlled by ACLs instead of permission bits.
+func checkPrivateDir(path string) error {
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strings"
 	"unicode/utf8"
 )
@@ -97,8 +98,9 @@ type parser struct {
 	b    strings.Builder // buffer for building text of under-construction statement
 	err  error           // only set once an error occurs during scanning (eof, io error, etc)
 
-	defaultDatabase   string
-	explicitDelimiter bool // true only if a DELIMITER command has ever been encountered in this input
+	defaultDatabase    string
+	explicitDelimiter  bool         // true only if a DELIMITER command has ever been encountered in this input
+	pendingLintIgnores []LintIgnore // from comments preceding the next statement
 
 	filePath   string
 	lineNumber int
@@ -123,6 +125,14 @@ func init() {
 		"use":       processUseCommand,
 		"DELIMITER": processDelimiterCommand,
 		"delimiter": processDelimiterCommand,
+		"INSERT":    processInsertStatement,
+		"insert":    processInsertStatement,
+		"ALTER":     processAlterStatement,
+		"alter":     processAlterStatement,
+		"RENAME":    processRenameStatement,
+		"rename":    processRenameStatement,
+		"DROP":      processDropStatement,
+		"drop":      processDropStatement,
 	}
 	createProcessors = map[string]statementProcessor{
 		"TABLE":     processCreateTable,
@@ -135,6 +145,14 @@ func init() {
 		"definer":   processCreateWithDefiner,
 		"OR":        processCreateOrReplace,
 		"or":        processCreateOrReplace,
+		"INDEX":     processCreateIndex,
+		"index":     processCreateIndex,
+		"UNIQUE":    processCreateIndex,
+		"unique":    processCreateIndex,
+		"FULLTEXT":  processCreateIndex,
+		"fulltext":  processCreateIndex,
+		"SPATIAL":   processCreateIndex,
+		"spatial":   processCreateIndex,
 	}
 }
 
@@ -242,9 +260,41 @@ func (p *parser) nextToken() (Token, error) {
 	t.offset = uint32(p.b.Len())
 	p.b.Write(val)
 	t.val = p.b.String()[t.offset:]
+	if t.typ == TokenFiller && strings.Contains(t.val, "skeema:lint-ignore") {
+		p.parseLintIgnores(t)
+	}
 	return t, nil
 }
 
+var reLintIgnore = regexp.MustCompile(`skeema:lint-ignore(?:[ \t]+([\w-]+(?:[ \t]*,[ \t]*[\w-]+)*))?`)
+
+// parseLintIgnores finds any skeema:lint-ignore comments in the supplied
+// filler token, and tracks them in the current statement. A comment on the
+// same line as other SQL applies to that line, whereas a comment on a line by
+// itself applies to the following line. A comment on the statement's first
+// line applies to the entire statement.
+func (p *parser) parseLintIgnores(t Token) {
+	buf := p.b.String()
+	for _, loc := range reLintIgnore.FindAllStringSubmatchIndex(t.val, -1) {
+		pos := int(t.offset) + loc[0]
+		li := LintIgnore{LineOffset: strings.Count(buf[:pos], "\n")}
+		li.LineNo = p.lineNumber + li.LineOffset
+		if loc[2] >= 0 {
+			for _, rule := range strings.Split(t.val[loc[2]:loc[3]], ",") {
+				li.Rules = append(li.Rules, strings.ToLower(strings.TrimSpace(rule)))
+			}
+		}
+		lineStart := strings.LastIndexByte(buf[:pos], '\n') + 1
+		if strings.TrimLeft(buf[lineStart:pos], " \t-#/*") == "" {
+			li.LineOffset++
+		}
+		if li.LineOffset == 0 {
+			li.LineOffset = -1
+		}
+		p.stmt.LintIgnores = append(p.stmt.LintIgnores, li)
+	}
+}
+
 // nextTokens attempts to grow the supplied tokens list to ensure it is at
 // least n tokens in length, unless it already is. This method won't grow a
 // list beyond a delimiter token or error, so the result is not guaranteed to
@@ -309,6 +359,20 @@ func (p *parser) nextTokensMinBytes(tokens []Token, wantBytes int) []Token {
 // command, DELIMITER command, etc).
 func (p *parser) finishStatement() *Statement {
 	stmt := p.stmt
+
+	// skeema:lint-ignore comments in whitespace/comment "statements" or client
+	// commands apply to the entirety of the next real statement
+	if stmt.Type == StatementTypeNoop || stmt.Type == StatementTypeCommand {
+		for _, li := range stmt.LintIgnores {
+			li.LineOffset = -1
+			p.pendingLintIgnores = append(p.pendingLintIgnores, li)
+		}
+		stmt.LintIgnores = nil
+	} else if len(p.pendingLintIgnores) > 0 {
+		stmt.LintIgnores = append(p.pendingLintIgnores, stmt.LintIgnores...)
+		p.pendingLintIgnores = nil
+	}
+
 	stmt.Text = p.b.String()
 	p.lineNumber, p.colNumber = p.positionAfterBuffer()
 	p.b.Reset()
@@ -609,6 +673,99 @@ func processDelimiterCommand(p *parser, _ []Token) (stmt *Statement, err error)
 	return p.finishStatement(), err
 }
 
+// processInsertStatement handles INSERT statements, which are used to declare
+// the managed rows of reference or lookup tables. Only the destination table
+// name is parsed; the remainder of the statement is passed through as-is.
+func processInsertStatement(p *parser, tokens []Token) (*Statement, error) {
+	// Skip past the optional IGNORE modifier and optional INTO keyword
+	_, tokens = p.matchNextSequence(tokens, "IGNORE INTO", "IGNORE", "INTO")
+
+	// Attempt to parse table name; only set statement and object types if
+	// successful
+	tokens, ok := p.parseObjectNameClause(tokens)
+	if ok {
+		p.stmt.Type = StatementTypeInsert
+		p.stmt.ObjectType = ObjectTypeTable
+	}
+	return processUntilDelimiter(p, tokens)
+}
+
+// processAlterStatement handles ALTER TABLE statements. Other types of ALTER
+// statements are left as StatementTypeUnknown.
+func processAlterStatement(p *parser, tokens []Token) (*Statement, error) {
+	// Skip past the optional ONLINE and IGNORE modifiers
+	_, tokens = p.matchNextSequence(tokens, "ONLINE IGNORE", "ONLINE", "IGNORE")
+	matched, tokens := p.matchNextSequence(tokens, "TABLE")
+	if matched == nil {
+		return processUntilDelimiter(p, tokens) // not an ALTER TABLE
+	}
+	_, tokens = p.matchNextSequence(tokens, "IF EXISTS")
+	return processTableAlteration(p, tokens)
+}
+
+// processRenameStatement handles RENAME TABLE statements. If the statement
+// renames multiple tables, only the first table's original name is parsed.
+func processRenameStatement(p *parser, tokens []Token) (*Statement, error) {
+	matched, tokens := p.matchNextSequence(tokens, "TABLE", "TABLES")
+	if matched == nil {
+		return processUntilDelimiter(p, tokens) // cannot parse, unexpected token
+	}
+	_, tokens = p.matchNextSequence(tokens, "IF EXISTS")
+	return processTableAlteration(p, tokens)
+}
+
+// processDropStatement handles DROP INDEX statements. Other types of DROP
+// statements are left as StatementTypeUnknown.
+func processDropStatement(p *parser, tokens []Token) (*Statement, error) {
+	_, tokens = p.matchNextSequence(tokens, "ONLINE", "OFFLINE")
+	matched, tokens := p.matchNextSequence(tokens, "INDEX")
+	if matched == nil {
+		return processUntilDelimiter(p, tokens) // not a DROP INDEX
+	}
+	_, tokens = p.matchNextSequence(tokens, "IF EXISTS")
+	return processIndexOnTable(p, tokens)
+}
+
+// processCreateIndex handles CREATE INDEX statements, which are treated as
+// alterations to the index's table.
+func processCreateIndex(p *parser, tokens []Token) (*Statement, error) {
+	_, tokens = p.matchNextSequence(tokens, "UNIQUE", "FULLTEXT", "SPATIAL")
+	matched, tokens := p.matchNextSequence(tokens, "INDEX")
+	if matched == nil {
+		return processUntilDelimiter(p, tokens) // cannot parse, unexpected token
+	}
+	_, tokens = p.matchNextSequence(tokens, "IF NOT EXISTS")
+	return processIndexOnTable(p, tokens)
+}
+
+// processIndexOnTable parses the portion of a CREATE INDEX or DROP INDEX
+// statement beginning with the index name, followed by an ON clause with the
+// table name.
+func processIndexOnTable(p *parser, tokens []Token) (*Statement, error) {
+	rawClause, _, tokens := p.parseIdentifierClause(tokens)
+	if rawClause == "" || len(tokens) == 0 {
+		return processUntilDelimiter(p, tokens) // cannot parse index name
+	}
+	_, tokens, found := p.skipUntilSequence(tokens, "ON")
+	if !found {
+		return processUntilDelimiter(p, tokens)
+	}
+	return processTableAlteration(p, tokens[1:])
+}
+
+// processTableAlteration parses the name of the table being altered by an
+// imperative DDL statement, and then consumes the rest of the statement.
+// Statement and object types are only set if the table name was parsed
+// successfully.
+func processTableAlteration(p *parser, tokens []Token) (*Statement, error) {
+	tokens, ok := p.parseObjectNameClause(tokens)
+	if ok {
+		p.stmt.Type = StatementTypeAlter
+		p.stmt.ObjectType = ObjectTypeTable
+	}
+	return processUntilDelimiter(p, tokens)
+}
+
 func getCreateProcessor(tokens []Token) statementProcessor {
 	if len(tokens) < 2 || tokens[0].typ != TokenWord {
 		return processUntilDelimiter // cannot parse
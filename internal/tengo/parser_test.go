@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -22,7 +23,7 @@ func TestParseStatementsInFileSuccess(t *testing.T) {
 		if n >= len(expected) || n >= len(statements) {
 			break
 		}
-		if *statements[n] != *expected[n] {
+		if !reflect.DeepEqual(statements[n], expected[n]) {
 			t.Errorf("statement[%d] fields did not all match expected values.\nExpected:\n%+v\n\nActual:\n%+v", n, expected[n], statements[n])
 		}
 	}
@@ -50,7 +51,7 @@ func TestParseStatementsInFileSuccess(t *testing.T) {
 			expect.File = filePath
 			expect.Text = strings.ReplaceAll(expect.Text, "\n", "\r\n")
 			expect.nameClause = strings.ReplaceAll(expect.nameClause, "\n", "\r\n")
-			if *statements[n] != *expect {
+			if !reflect.DeepEqual(statements[n], expect) {
 				t.Errorf("statement[%d] fields did not all match expected values.\nExpected:\n%+v\n\nActual:\n%+v", n, expect, statements[n])
 			}
 		}
@@ -202,6 +203,9 @@ func TestParseStatementInString(t *testing.T) {
 		"/* hello */\nCREATE TABLE foo (id int);\n":                {},
 		"CREATE TABLE foo (id int);\n":                             {Type: ObjectTypeTable, Name: "foo"},
 		"CREATE TABLE foo (id int);\nCREATE TABLE bar (id int);\n": {Type: ObjectTypeTable, Name: "foo"},
+		"INSERT INTO foo (id) VALUES (1);\n":                       {Type: ObjectTypeTable, Name: "foo"},
+		"insert ignore into `bar` values (1), (2);\n":              {Type: ObjectTypeTable, Name: "bar"},
+		"INSERT foo VALUES (1);\n":                                 {Type: ObjectTypeTable, Name: "foo"},
 	}
 	for input, expected := range cases {
 		if actual := ParseStatementInString(input).ObjectKey(); actual != expected {
@@ -210,6 +214,89 @@ func TestParseStatementInString(t *testing.T) {
 	}
 }
 
+func TestParseStatementsAlter(t *testing.T) {
+	cases := map[string]ObjectKey{
+		"ALTER TABLE foo ADD COLUMN bar int":                     {Type: ObjectTypeTable, Name: "foo"},
+		"alter online ignore table `my db`.`foo` drop key bar":   {Type: ObjectTypeTable, Name: "foo"},
+		"ALTER TABLE IF EXISTS foo ENGINE=InnoDB":                {Type: ObjectTypeTable, Name: "foo"},
+		"RENAME TABLE foo TO bar, baz TO qux":                    {Type: ObjectTypeTable, Name: "foo"},
+		"CREATE INDEX idx_bar ON foo (bar)":                      {Type: ObjectTypeTable, Name: "foo"},
+		"create unique index `idx_bar` using btree on foo (bar)": {Type: ObjectTypeTable, Name: "foo"},
+		"CREATE OR REPLACE FULLTEXT INDEX ft ON foo (body)":      {Type: ObjectTypeTable, Name: "foo"},
+		"DROP INDEX IF EXISTS idx_bar ON foo":                    {Type: ObjectTypeTable, Name: "foo"},
+		"ALTER DATABASE foo CHARACTER SET utf8mb4":               {},
+		"ALTER TABLE":               {},
+		"DROP TABLE foo":            {},
+		"DROP INDEX idx_bar":        {},
+		"CREATE INDEX ON foo (bar)": {},
+	}
+	for input, expected := range cases {
+		stmt := ParseStatementInString(input)
+		if expected.Name == "" {
+			if stmt.Type != StatementTypeUnknown {
+				t.Errorf("For input %q, expected StatementTypeUnknown, instead found type %d", input, stmt.Type)
+			}
+		} else if stmt.Type != StatementTypeAlter || stmt.ObjectKey() != expected {
+			t.Errorf("For input %q, expected StatementTypeAlter for %s, instead found type %d for %s", input, expected, stmt.Type, stmt.ObjectKey())
+		}
+	}
+}
+
+func TestParseStatementsLintIgnores(t *testing.T) {
+	input := `CREATE TABLE noignores (id int);
+-- skeema:lint-ignore pk
+# unrelated comment
+
+CREATE TABLE foo ( -- skeema:lint-ignore
+  id int,
+  price float, -- skeema:lint-ignore has-float, Nullable
+  -- skeema:lint-ignore display-width
+  qty int(10) unsigned /* skeema:lint-ignore */
+);
+DELIMITER //
+CREATE TABLE bar (id int)//
+`
+	statements, err := ParseStatementsInString(input)
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseStatementsInString: %v", err)
+	}
+	actual := make(map[string][]LintIgnore)
+	for _, stmt := range statements {
+		if stmt.Type != StatementTypeCreate {
+			if stmt.LintIgnores != nil {
+				t.Errorf("Expected non-CREATE statement at %s to have no LintIgnores, but found %+v", stmt.Location(), stmt.LintIgnores)
+			}
+			continue
+		}
+		actual[stmt.ObjectName] = stmt.LintIgnores
+	}
+	expected := map[string][]LintIgnore{
+		"noignores": nil,
+		"foo": {
+			{Rules: []string{"pk"}, LineNo: 2, LineOffset: -1},
+			{LineNo: 5, LineOffset: -1},
+			{Rules: []string{"has-float", "nullable"}, LineNo: 7, LineOffset: 2},
+			{Rules: []string{"display-width"}, LineNo: 8, LineOffset: 4},
+			{LineNo: 9, LineOffset: 4},
+		},
+		"bar": nil,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected LintIgnores:\nExpected: %+v\nActual:   %+v", expected, actual)
+	}
+
+	li := expected["foo"][2]
+	if !li.Matches("has-float", 2) || !li.Matches("NULLABLE", 2) || li.Matches("has-float", 0) || li.Matches("pk", 2) {
+		t.Errorf("Unexpected result from Matches on %+v", li)
+	}
+	if li.String() != "-- skeema:lint-ignore has-float,nullable" || expected["foo"][1].String() != "-- skeema:lint-ignore" {
+		t.Errorf("Unexpected result from String: %q", li.String())
+	}
+	if parsed := ParseStatementInString("CREATE TABLE x (\n  y float, " + li.String() + "\n)"); len(parsed.LintIgnores) != 1 || !reflect.DeepEqual(parsed.LintIgnores[0].Rules, li.Rules) {
+		t.Errorf("Unexpected round-trip result of String: %+v", parsed.LintIgnores)
+	}
+}
+
 func TestStripAnyQuote(t *testing.T) {
 	cases := map[string]string{
 		"":                          "",
@@ -2,6 +2,7 @@ package tengo
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -17,7 +18,8 @@ const (
 	StatementTypeCommand               // currently just USE or DELIMITER
 	StatementTypeCreate
 	StatementTypeCreateUnsupported // edge cases like CREATE...SELECT
-	StatementTypeAlter             // not actually ever parsed yet
+	StatementTypeAlter             // ALTER TABLE, RENAME TABLE, CREATE INDEX, or DROP INDEX
+	StatementTypeInsert            // INSERT statements declaring managed table data
 	// Other types will be added once they are supported by the package
 )
 
@@ -34,9 +36,36 @@ type Statement struct {
 	ObjectType      ObjectType
 	ObjectName      string
 	ObjectQualifier string
-	Delimiter       string // delimiter in use at the time of statement; not necessarily present in Text though
-	Compound        bool   // if true, this is a compound statement (stored program with a BEGIN block, requiring alternative delimiter)
-	nameClause      string // raw version, potentially with schema name qualifier and/or surrounding backticks
+	Delimiter       string       // delimiter in use at the time of statement; not necessarily present in Text though
+	Compound        bool         // if true, this is a compound statement (stored program with a BEGIN block, requiring alternative delimiter)
+	LintIgnores     []LintIgnore // skeema:lint-ignore comments within the statement, or in comments immediately preceding it
+	nameClause      string       // raw version, potentially with schema name qualifier and/or surrounding backticks
+}
+
+// LintIgnore represents a "skeema:lint-ignore" comment, which suppresses
+// linter problems for a statement or for a single line of a statement.
+type LintIgnore struct {
+	Rules      []string // lowercased linter rule names; empty means all rules
+	LineNo     int      // line number of the comment itself
+	LineOffset int      // line offset within the statement which the comment applies to, or -1 for the entire statement
+}
+
+// Matches returns true if li suppresses the supplied rule name for a problem
+// located at the supplied line offset within its statement.
+func (li LintIgnore) Matches(ruleName string, lineOffset int) bool {
+	if li.LineOffset >= 0 && li.LineOffset != lineOffset {
+		return false
+	}
+	return len(li.Rules) == 0 || slices.Contains(li.Rules, strings.ToLower(ruleName))
+}
+
+// String returns a SQL comment which would be parsed into an equivalent
+// LintIgnore.
+func (li LintIgnore) String() string {
+	if len(li.Rules) == 0 {
+		return "-- skeema:lint-ignore"
+	}
+	return "-- skeema:lint-ignore " + strings.Join(li.Rules, ",")
 }
 
 // Location returns the file, line number, and character number where the
@@ -105,6 +134,46 @@ func (stmt *Statement) IdempotentBody() string {
 	return body
 }
 
+// NameClause returns the raw object name clause of the statement, as it
+// originally appeared in the statement text. This may include a schema name
+// qualifier, and/or backticks around names. An empty string is returned if the
+// statement does not refer to an object by name.
+func (stmt *Statement) NameClause() string {
+	return stmt.nameClause
+}
+
+// ReplaceNameClause returns the Statement's body (as returned by
+// SplitTextBody) with the object name clause replaced by newClause. This may
+// be used to add, remove, or adjust a schema name qualifier. If the statement
+// has no name clause, the body is returned unchanged.
+func (stmt *Statement) ReplaceNameClause(newClause string) string {
+	body, _ := stmt.SplitTextBody()
+	if stmt.nameClause == "" {
+		return body
+	}
+	// The name clause may also appear earlier in a DEFINER clause, for example if
+	// a routine's name matches the definer's user name, so skip past any matches
+	// which are adjacent to an @ or part of a longer identifier
+	var offset int
+	for {
+		pos := strings.Index(body[offset:], stmt.nameClause)
+		if pos == -1 {
+			return body
+		}
+		start, end := offset+pos, offset+pos+len(stmt.nameClause)
+		if (start > 0 && !isNameClauseBoundary(body[start-1])) || (end < len(body) && !isNameClauseBoundary(body[end])) {
+			offset = end
+			continue
+		}
+		return body[:start] + newClause + body[end:]
+	}
+}
+
+func isNameClauseBoundary(b byte) bool {
+	return b != '@' && b != '`' && b != '_' && b != '$' && b != '.' && b != '\'' && b != '"' &&
+		!(b >= 'a' && b <= 'z') && !(b >= 'A' && b <= 'Z') && !(b >= '0' && b <= '9') && b < 0x80
+}
+
 // SplitTextBody returns Text with its trailing delimiter and whitespace (if
 // any) separated out into a separate string.
 func (stmt *Statement) SplitTextBody() (body string, suffix string) {
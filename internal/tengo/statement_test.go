@@ -95,6 +95,26 @@ func TestStatementBody(t *testing.T) {
 	}
 }
 
+func TestStatementReplaceNameClause(t *testing.T) {
+	cases := []struct {
+		input     string
+		newClause string
+		expected  string
+	}{
+		{"CREATE TABLE foo (id int)", "mydb.`foo`", "CREATE TABLE mydb.`foo` (id int)"},
+		{"CREATE TABLE `mydb` . foo (id int);\n", "`foo`", "CREATE TABLE `foo` (id int)"},
+		{"CREATE DEFINER=`root`@`%` PROCEDURE `root`() SELECT 1", "`mydb`.`root`", "CREATE DEFINER=`root`@`%` PROCEDURE `mydb`.`root`() SELECT 1"},
+		{"CREATE DEFINER=root@`root` FUNCTION root() RETURNS int RETURN 1", "db.root", "CREATE DEFINER=root@`root` FUNCTION db.root() RETURNS int RETURN 1"},
+		{"USE foo\n", "bar", "USE foo"},
+	}
+	for _, c := range cases {
+		stmt := ParseStatementInString(c.input)
+		if actual := stmt.ReplaceNameClause(c.newClause); actual != c.expected {
+			t.Errorf("Incorrect result for ReplaceNameClause(%q) on input %q:\nExpected: %q\nActual:   %q", c.newClause, c.input, c.expected, actual)
+		}
+	}
+}
+
 func TestStatementIdempotentBody(t *testing.T) {
 	cases := map[string]string{
 		"create table ex1 (id int)":                                "create table  IF NOT EXISTS `ex1` (id int)",
@@ -81,6 +81,25 @@ func (ai AddIndex) Clause(mods StatementModifiers) string {
 	return "ADD " + ai.Index.Definition(mods.Flavor)
 }
 
+// reversedDeferred returns the clause to use in place of ai when generating a
+// reversed diff with mods.DeferIndexDrops and mods.ReverseIndexDrops enabled.
+// This undoes the effect of DropIndex.deferred on the same index in the
+// original diff: a visible index, which the original diff would have made
+// invisible, is made visible again instead of being added. An invisible index
+// is only re-added if mods.IndexDropReady permits it; otherwise nil is
+// returned, since the original diff would have omitted the drop. Primary keys,
+// as well as flavors lacking support for invisible indexes, are unaffected.
+func (ai AddIndex) reversedDeferred(tableName string, mods StatementModifiers) TableAlterClause {
+	if ai.Index.PrimaryKey || !(mods.Flavor.MinMySQL(8) || mods.Flavor.MinMariaDB(10, 6)) {
+		return ai
+	} else if !ai.Index.Invisible {
+		return AlterIndex{Name: ai.Index.Name, Invisible: false}
+	} else if mods.IndexDropReady == nil || mods.IndexDropReady(tableName, ai.Index.Name) {
+		return ai
+	}
+	return nil
+}
+
 ///// DropIndex ////////////////////////////////////////////////////////////////
 
 // DropIndex represents an index that was only present on the left-side ("from")
@@ -97,6 +116,23 @@ func (di DropIndex) Clause(_ StatementModifiers) string {
 	return "DROP KEY " + EscapeIdentifier(di.Index.Name)
 }
 
+// deferred returns the clause to use in place of di when mods.DeferIndexDrops
+// is enabled. A visible secondary index is made invisible instead of being
+// dropped. An index which is already invisible is only dropped if
+// mods.IndexDropReady permits it; otherwise nil is returned, indicating the
+// drop should be omitted for now. Primary keys, as well as flavors lacking
+// support for invisible indexes, are unaffected.
+func (di DropIndex) deferred(tableName string, mods StatementModifiers) TableAlterClause {
+	if di.Index.PrimaryKey || !(mods.Flavor.MinMySQL(8) || mods.Flavor.MinMariaDB(10, 6)) {
+		return di
+	} else if !di.Index.Invisible {
+		return AlterIndex{Name: di.Index.Name, Invisible: true}
+	} else if mods.IndexDropReady == nil || mods.IndexDropReady(tableName, di.Index.Name) {
+		return di
+	}
+	return nil
+}
+
 ///// ModifyIndex and AlterIndex ///////////////////////////////////////////////
 
 // ModifyIndex represents a logical change in any of an index's fields. This is
@@ -288,6 +288,15 @@ func (td *TableDiff) alterStatement(mods StatementModifiers) (string, error) {
 	var partitionClauseString string
 	var changingComment bool
 	for _, clause := range td.alterClauses {
+		if di, ok := clause.(DropIndex); ok && mods.DeferIndexDrops && !mods.ReverseIndexDrops {
+			if clause = di.deferred(td.From.Name, mods); clause == nil {
+				continue
+			}
+		} else if ai, ok := clause.(AddIndex); ok && mods.DeferIndexDrops && mods.ReverseIndexDrops {
+			if clause = ai.reversedDeferred(td.To.Name, mods); clause == nil {
+				continue
+			}
+		}
 		if !mods.AllowUnsafe {
 			if clause, ok := clause.(Unsafer); ok {
 				if unsafe, reason := clause.Unsafe(mods); unsafe {
@@ -402,6 +411,21 @@ func (td *TableDiff) alterStatement(mods StatementModifiers) (string, error) {
 	return td.From.AlterStatement() + " " + strings.Join(clauseStrings, ", ") + spacer + partitionClauseString, err
 }
 
+// DroppedIndexes returns the indexes that td removes outright via DROP KEY
+// clauses. Indexes that are only dropped and re-added as part of modifying
+// their definition are not included.
+func (td *TableDiff) DroppedIndexes() (indexes []*Index) {
+	if td == nil || td.Type != DiffTypeAlter {
+		return nil
+	}
+	for _, clause := range td.alterClauses {
+		if di, ok := clause.(DropIndex); ok {
+			indexes = append(indexes, di.Index)
+		}
+	}
+	return indexes
+}
+
 // MarkSupported provides a mechanism for callers to vouch for the correctness
 // of a TableDiff that was automatically marked as unsupported. This should only
 // be used in cases where a table with UnsupportedDDL is being altered in a way
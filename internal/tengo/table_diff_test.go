@@ -623,6 +623,115 @@ func TestAlterTableStatementOnlineMods(t *testing.T) {
 	}
 }
 
+func TestAlterTableStatementDeferIndexDrops(t *testing.T) {
+	from := aTable(1)
+	to := aTable(1)
+	to.SecondaryIndexes = to.SecondaryIndexes[0 : len(to.SecondaryIndexes)-1]
+	to.CreateStatement = to.GeneratedCreateStatement(FlavorUnknown)
+	alter := NewAlterTable(&from, &to)
+	if dropped := alter.DroppedIndexes(); len(dropped) != 1 || dropped[0].Name != "idx_actor_name" {
+		t.Fatalf("Unexpected return from DroppedIndexes: %+v", dropped)
+	}
+
+	var readyCalls int
+	mods := StatementModifiers{
+		DeferIndexDrops: true,
+		IndexDropReady: func(tableName, indexName string) bool {
+			readyCalls++
+			return tableName == "actor" && indexName == "idx_actor_name" && readyCalls > 1
+		},
+	}
+	cases := []struct {
+		flavor    Flavor
+		invisible bool
+		expected  string
+	}{
+		{ParseFlavor("mysql:5.7"), false, "DROP KEY `idx_actor_name`"},
+		{ParseFlavor("mariadb:10.5"), false, "DROP KEY `idx_actor_name`"},
+		{ParseFlavor("mysql:8.0"), false, "ALTER INDEX `idx_actor_name` INVISIBLE"},
+		{ParseFlavor("mariadb:10.6"), false, "ALTER INDEX `idx_actor_name` IGNORED"},
+		{ParseFlavor("mysql:8.0"), true, ""},                          // first IndexDropReady call returns false
+		{ParseFlavor("mysql:8.0"), true, "DROP KEY `idx_actor_name`"}, // subsequent calls return true
+	}
+	for _, c := range cases {
+		from.SecondaryIndexes[len(from.SecondaryIndexes)-1].Invisible = c.invisible
+		mods.Flavor = c.flavor
+		stmt, err := alter.Statement(mods)
+		if err != nil {
+			t.Errorf("Unexpected error from Statement with flavor %s: %v", c.flavor, err)
+		} else if c.expected == "" && stmt != "" {
+			t.Errorf("Expected blank statement with flavor %s and invisible=%t, instead found %s", c.flavor, c.invisible, stmt)
+		} else if c.expected != "" && stmt != "ALTER TABLE `actor` "+c.expected {
+			t.Errorf("Unexpected statement with flavor %s and invisible=%t: %s", c.flavor, c.invisible, stmt)
+		}
+	}
+
+	// Confirm primary key drops are never deferred
+	to = aTable(1)
+	to.PrimaryKey = nil
+	to.CreateStatement = to.GeneratedCreateStatement(FlavorUnknown)
+	alter = NewAlterTable(&from, &to)
+	if stmt, _ := alter.Statement(mods); !strings.Contains(stmt, "DROP PRIMARY KEY") {
+		t.Errorf("Expected primary key drop to be unaffected by DeferIndexDrops, instead found %s", stmt)
+	}
+}
+
+func TestAlterTableStatementReverseIndexDrops(t *testing.T) {
+	// Reversing a deferred drop: the "to" side has an index which the "from" side
+	// lacks, as in a diff from the filesystem back to the live database
+	from := aTable(1)
+	to := aTable(1)
+	from.SecondaryIndexes = from.SecondaryIndexes[0 : len(from.SecondaryIndexes)-1]
+	from.CreateStatement = from.GeneratedCreateStatement(FlavorUnknown)
+	reverse := NewAlterTable(&from, &to)
+
+	var readyCalls int
+	mods := StatementModifiers{
+		DeferIndexDrops:   true,
+		ReverseIndexDrops: true,
+		IndexDropReady: func(tableName, indexName string) bool {
+			readyCalls++
+			return tableName == "actor" && indexName == "idx_actor_name" && readyCalls > 1
+		},
+	}
+	cases := []struct {
+		flavor    Flavor
+		invisible bool
+		expected  string
+	}{
+		{ParseFlavor("mysql:5.7"), false, "ADD KEY `idx_actor_name`"},
+		{ParseFlavor("mysql:8.0"), false, "ALTER INDEX `idx_actor_name` VISIBLE"},
+		{ParseFlavor("mariadb:10.6"), false, "ALTER INDEX `idx_actor_name` NOT IGNORED"},
+		{ParseFlavor("mysql:8.0"), true, ""},                                      // first IndexDropReady call returns false
+		{ParseFlavor("mysql:8.0"), true, "ADD KEY `idx_actor_name` (`last_name`"}, // subsequent calls return true
+	}
+	for _, c := range cases {
+		to.SecondaryIndexes[len(to.SecondaryIndexes)-1].Invisible = c.invisible
+		mods.Flavor = c.flavor
+		stmt, err := reverse.Statement(mods)
+		if err != nil {
+			t.Errorf("Unexpected error from Statement with flavor %s: %v", c.flavor, err)
+		} else if c.expected == "" && stmt != "" {
+			t.Errorf("Expected blank statement with flavor %s and invisible=%t, instead found %s", c.flavor, c.invisible, stmt)
+		} else if c.expected != "" && !strings.HasPrefix(stmt, "ALTER TABLE `actor` "+c.expected) {
+			t.Errorf("Unexpected statement with flavor %s and invisible=%t: %s", c.flavor, c.invisible, stmt)
+		}
+	}
+
+	// Without ReverseIndexDrops, the same diff just adds the index, and an index
+	// dropped in a reversed diff is never deferred
+	mods.ReverseIndexDrops = false
+	to.SecondaryIndexes[len(to.SecondaryIndexes)-1].Invisible = false
+	if stmt, _ := reverse.Statement(mods); !strings.Contains(stmt, "ADD KEY `idx_actor_name`") {
+		t.Errorf("Expected index to be added without ReverseIndexDrops, instead found %s", stmt)
+	}
+	mods.ReverseIndexDrops = true
+	forward := NewAlterTable(&to, &from)
+	if stmt, _ := forward.Statement(mods); stmt != "ALTER TABLE `actor` DROP KEY `idx_actor_name`" {
+		t.Errorf("Expected index drop to be unaffected by ReverseIndexDrops, instead found %s", stmt)
+	}
+}
+
 func TestAlterTableStatementVirtualColValidation(t *testing.T) {
 	from, to := aTable(1), aTable(1)
 
@@ -28,6 +28,9 @@ func AddGlobalOptions(cmd *mybase.Command) {
 	cmd.AddOption(mybase.StringOption("default-collation", 0, "", "Schema-level default collation").Hidden())
 	cmd.AddOption(mybase.StringOption("flavor", 0, "", "Database server expressed in format vendor:major.minor, for use in vendor/version specific syntax").Hidden())
 	cmd.AddOption(mybase.StringOption("generator", 0, "", "Version of Skeema used for `skeema init` or most recent `skeema pull`").Hidden())
+	cmd.AddOption(mybase.StringOption("layout", 0, "{name}.sql", "Path template for new *.sql files, relative to schema dir; may use {name}, {type}, {types}").Hidden())
+	cmd.AddOption(mybase.BoolOption("group-routines", 0, false, "Place all stored procedures and functions in a single file").Hidden())
+	cmd.AddOption(mybase.StringOption("patches-file", 0, "", "Name of *.sql file containing ALTER TABLE, RENAME TABLE, CREATE INDEX, or DROP INDEX statements to apply after CREATEs").Hidden())
 
 	// Visible global options
 	cmd.AddOptions("global",
@@ -0,0 +1,192 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// cacheFormatVersion should be incremented whenever the structure of cached
+// results changes, or whenever workspace behavior changes in a way which
+// invalidates previously-cached results.
+const cacheFormatVersion = 1
+
+// cacheMaxAge is the duration after which unused cache entries are removed.
+const cacheMaxAge = 7 * 24 * time.Hour
+
+// cachedSchema is the on-disk representation of a cached Schema.
+type cachedSchema struct {
+	Schema *tengo.Schema `json:"schema"`
+	Flavor string        `json:"flavor"`
+	Info   string        `json:"info"`
+}
+
+// DefaultCacheDir returns the directory used for caching workspace results, or
+// an empty string if the user cache directory cannot be determined.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "skeema", "workspace")
+}
+
+// cacheKey returns a hex-encoded hash of everything that influences the result
+// of running ExecLogicalSchema on logicalSchema with opts: the statements
+// themselves, as well as the flavor, default character set and collation,
+// sql_mode, and name case mode of the workspace. An empty string is returned
+// if the result should not be cached, for example if logicalSchema includes
+// managed data.
+func cacheKey(logicalSchema *fs.LogicalSchema, opts Options) string {
+	if len(logicalSchema.Inserts) > 0 {
+		return ""
+	}
+	h := sha256.New()
+	field := func(name, value string) {
+		// Length-prefixing avoids ambiguity between adjacent values
+		fmt.Fprintf(h, "%s:%d:%s\n", name, len(value), value)
+	}
+	field("format", fmt.Sprint(cacheFormatVersion))
+	if info, ok := debug.ReadBuildInfo(); ok {
+		field("build", info.Main.Version)
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.time" {
+				field(setting.Key, setting.Value)
+			}
+		}
+	}
+	field("type", fmt.Sprint(opts.Type))
+	if opts.Type == TypeTempSchema {
+		if opts.Instance == nil {
+			return ""
+		}
+		field("flavor", opts.Instance.Flavor().String())
+		field("sqlmode", opts.Instance.SQLMode())
+	} else {
+		field("flavor", opts.Flavor.String())
+		field("params", opts.DefaultConnParams) // includes sql_mode
+		field("binary", opts.BinaryPath)
+	}
+	field("schema", opts.SchemaName)
+	field("charset", opts.DefaultCharacterSet)
+	field("collation", opts.DefaultCollation)
+	field("namecase", fmt.Sprint(opts.NameCaseMode))
+
+	keys := make([]tengo.ObjectKey, 0, len(logicalSchema.Creates))
+	for key := range logicalSchema.Creates {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b tengo.ObjectKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, key := range keys {
+		field("create "+key.String(), logicalSchema.Creates[key].Body())
+	}
+	for _, stmt := range logicalSchema.Alters {
+		field("alter", stmt.Body())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadCachedSchema returns a Schema from the cache in opts.CacheDir, or nil if
+// no usable cache entry exists.
+func loadCachedSchema(logicalSchema *fs.LogicalSchema, opts Options) *Schema {
+	key := cacheKey(logicalSchema, opts)
+	if opts.CacheDir == "" || key == "" {
+		return nil
+	}
+	start := time.Now()
+	path := filepath.Join(opts.CacheDir, key+".json")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var cs cachedSchema
+	if err := json.NewDecoder(f).Decode(&cs); err != nil || cs.Schema == nil {
+		log.Debugf("Ignoring unreadable workspace cache entry %s: %v", path, err)
+		return nil
+	}
+	if cs.Schema.ObjectCount() != len(logicalSchema.Creates) {
+		return nil
+	}
+
+	// Update the entry's modification time, so that pruning only affects
+	// entries which have not been used recently
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return &Schema{
+		Schema:        cs.Schema,
+		LogicalSchema: logicalSchema,
+		Flavor:        tengo.ParseFlavor(cs.Flavor),
+		Failures:      []*StatementError{},
+		Info:          "cache (originally from " + cs.Info + ")",
+		Timers:        Timers{Init: time.Since(start)},
+	}
+}
+
+// storeCachedSchema writes wsSchema to the cache in opts.CacheDir. Only
+// results without any statement failures are cached. Errors are logged but
+// otherwise ignored, since caching is just an optimization.
+func storeCachedSchema(wsSchema *Schema, opts Options) {
+	key := cacheKey(wsSchema.LogicalSchema, opts)
+	if opts.CacheDir == "" || key == "" || len(wsSchema.Failures) > 0 || wsSchema.Schema == nil {
+		return
+	}
+	if err := os.MkdirAll(opts.CacheDir, 0700); err != nil {
+		log.Debugf("Unable to create workspace cache directory: %v", err)
+		return
+	}
+	cs := cachedSchema{
+		Schema: wsSchema.Schema,
+		Flavor: wsSchema.Flavor.String(),
+		Info:   wsSchema.Info,
+	}
+
+	// Write to a temp file and then rename, so that concurrent readers never see
+	// a partially-written entry
+	f, err := os.CreateTemp(opts.CacheDir, key+".*.tmp")
+	if err != nil {
+		log.Debugf("Unable to write workspace cache entry: %v", err)
+		return
+	}
+	err = json.NewEncoder(f).Encode(cs)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(opts.CacheDir, key+".json"))
+	}
+	if err != nil {
+		log.Debugf("Unable to write workspace cache entry: %v", err)
+		os.Remove(f.Name())
+		return
+	}
+	pruneCache(opts.CacheDir)
+}
+
+// pruneCache removes cache entries in dir which have not been used within
+// cacheMaxAge.
+func pruneCache(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && !entry.IsDir() && time.Since(info.ModTime()) > cacheMaxAge {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"os"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestWorkspaceCache(t *testing.T) {
+	logicalSchema := fs.NewLogicalSchema()
+	for _, stmt := range []string{"CREATE TABLE foo (id int unsigned NOT NULL, PRIMARY KEY (id));\n", "CREATE TABLE bar (name varchar(30));\n"} {
+		if err := logicalSchema.AddStatement(tengo.ParseStatementInString(stmt)); err != nil {
+			t.Fatalf("Unexpected error from AddStatement: %v", err)
+		}
+	}
+	opts := Options{
+		Type:              TypeLocalDocker,
+		Flavor:            tengo.ParseFlavor("mysql:8.0"),
+		SchemaName:        "_skeema_tmp",
+		DefaultConnParams: "sql_mode=%27STRICT_TRANS_TABLES%27",
+		CacheDir:          t.TempDir(),
+	}
+
+	// Cache key should be deterministic, but differ if any relevant input changes
+	key := cacheKey(logicalSchema, opts)
+	if key == "" || key != cacheKey(logicalSchema, opts) {
+		t.Fatalf("Expected non-empty deterministic cache key, instead found %q", key)
+	}
+	otherOpts := opts
+	otherOpts.Flavor = tengo.ParseFlavor("mariadb:10.11")
+	otherOpts2 := opts
+	otherOpts2.DefaultCollation = "utf8mb4_bin"
+	otherOpts3 := opts
+	otherOpts3.DefaultConnParams = ""
+	for _, o := range []Options{otherOpts, otherOpts2, otherOpts3} {
+		if cacheKey(logicalSchema, o) == key {
+			t.Errorf("Expected cache key to differ with options %+v, but it did not", o)
+		}
+	}
+
+	// Nothing cached yet
+	if cached := loadCachedSchema(logicalSchema, opts); cached != nil {
+		t.Fatalf("Expected cache miss, instead found %+v", cached)
+	}
+
+	// Store a result, and confirm it can be loaded
+	wsSchema := &Schema{
+		Schema: &tengo.Schema{
+			Name: "_skeema_tmp",
+			Tables: []*tengo.Table{
+				{Name: "bar", Columns: []*tengo.Column{{Name: "name", Type: tengo.ParseColumnType("varchar(30)"), Nullable: true}}},
+				{Name: "foo", Columns: []*tengo.Column{{Name: "id", Type: tengo.ParseColumnType("int unsigned")}}},
+			},
+		},
+		LogicalSchema: logicalSchema,
+		Flavor:        tengo.ParseFlavor("mysql:8.0.36"),
+		Info:          "docker (image=mysql:8.0)",
+	}
+	storeCachedSchema(wsSchema, opts)
+	cached := loadCachedSchema(logicalSchema, opts)
+	if cached == nil {
+		t.Fatal("Expected cache hit, but loadCachedSchema returned nil")
+	}
+	if cached.Flavor != wsSchema.Flavor || cached.LogicalSchema != logicalSchema || len(cached.Failures) != 0 {
+		t.Errorf("Unexpected field values in cached result: %+v", cached)
+	}
+	if cached.Table("foo") == nil || cached.Table("foo").Columns[0].Type.String() != "int unsigned" {
+		t.Errorf("Cached schema does not contain expected table definition: %+v", cached.Schema)
+	}
+
+	// Changing a statement should result in a cache miss
+	logicalSchema.Creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "bar"}] = tengo.ParseStatementInString("CREATE TABLE bar (name varchar(40));\n")
+	if cached := loadCachedSchema(logicalSchema, opts); cached != nil {
+		t.Error("Expected cache miss after changing a statement, instead found a hit")
+	}
+
+	// Results with failures should not be cached
+	wsSchema.Failures = []*StatementError{{Statement: logicalSchema.Creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "bar"}], Err: os.ErrInvalid}}
+	storeCachedSchema(wsSchema, opts)
+	if cached := loadCachedSchema(logicalSchema, opts); cached != nil {
+		t.Error("Expected result with failures to not be cached, but it was")
+	}
+
+}
@@ -2,7 +2,9 @@ package workspace
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -18,6 +20,7 @@ type Type int
 const (
 	TypeTempSchema  Type = iota // A temporary schema on a real pre-supplied Instance
 	TypeLocalDocker             // A schema on an ephemeral Docker container on localhost
+	TypeLocalBinary             // A schema on an ephemeral mysqld or mariadbd process on localhost
 )
 
 // CleanupAction represents how to clean up a workspace.
@@ -38,12 +41,13 @@ const (
 	// TypeTempSchema.
 	CleanupActionDropOneShot
 
-	// CleanupActionStop means to stop the MySQL instance container in Shutdown().
-	// Only used with TypeLocalDocker.
+	// CleanupActionStop means to stop the MySQL instance container or process in
+	// Shutdown(). Only used with TypeLocalDocker or TypeLocalBinary.
 	CleanupActionStop
 
-	// CleanupActionDestroy means to destroy the MySQL instance container in
-	// Shutdown(). Only used with TypeLocalDocker.
+	// CleanupActionDestroy means to destroy the MySQL instance container, or the
+	// process and its data directory, in Shutdown(). Only used with
+	// TypeLocalDocker or TypeLocalBinary.
 	CleanupActionDestroy
 )
 
@@ -53,19 +57,22 @@ type Options struct {
 	Type                Type
 	CleanupAction       CleanupAction
 	Instance            *tengo.Instance // only TypeTempSchema
-	Flavor              tengo.Flavor    // only TypeLocalDocker
+	Flavor              tengo.Flavor    // only TypeLocalDocker or TypeLocalBinary
 	ContainerName       string          // only TypeLocalDocker
+	ContainerRuntime    string          // only TypeLocalDocker
+	BinaryPath          string          // only TypeLocalBinary
 	SchemaName          string
 	DefaultCharacterSet string
 	DefaultCollation    string
-	DefaultConnParams   string // only TypeLocalDocker
+	DefaultConnParams   string // only TypeLocalDocker or TypeLocalBinary
 	RootPassword        string // only TypeLocalDocker
 	NameCaseMode        tengo.NameCaseMode
 	LockTimeout         time.Duration // max wait for workspace user-level locking, via GET_LOCK()
 	CreateThreads       int
 	CreateChunkSize     int
-	DropChunkSize       int  // only TypeTempSchema
-	SkipBinlog          bool // only TypeTempSchema
+	DropChunkSize       int    // only TypeTempSchema
+	SkipBinlog          bool   // only TypeTempSchema
+	CacheDir            string // if non-empty, ExecLogicalSchema caches results in this directory
 }
 
 // OptionsForDir returns Options based on the configuration in an fs.Dir.
@@ -74,14 +81,40 @@ type Options struct {
 // This method relies on option definitions from AddCommandOptions(), as well
 // as the "flavor" option from util.AddGlobalOptions().
 func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
-	requestedType, err := dir.Config.GetEnum("workspace", "temp-schema", "docker")
+	requestedType, err := dir.Config.GetEnum("workspace", "temp-schema", "docker", "local-binary")
+	var opts Options
 	if err != nil {
 		return Options{}, err
 	} else if requestedType == "docker" {
-		return localDockerOptionsForDir(dir, instance)
+		opts, err = localDockerOptionsForDir(dir, instance)
+	} else if requestedType == "local-binary" {
+		opts, err = localBinaryOptionsForDir(dir, instance)
 	} else {
-		return tempSchemaOptionsForDir(dir, instance)
+		opts, err = tempSchemaOptionsForDir(dir, instance)
 	}
+	if err == nil && dir.Config.GetBool("workspace-cache") {
+		opts.CacheDir = DefaultCacheDir()
+	}
+	return opts, err
+}
+
+// OptionsForDirFirstInstance returns Options based on the configuration in an
+// fs.Dir, for commands which only need a database server for workspace
+// purposes, such as format and lint. This involves connecting to the dir's
+// first defined instance, so that any auto-detect-related settings work
+// properly. However, with workspace=docker or workspace=local-binary,
+// connection errors are ignored as long as the flavor option is set, since
+// OptionsForDir can obtain reasonable defaults without an instance.
+func OptionsForDirFirstInstance(dir *fs.Dir) (Options, error) {
+	inst, err := dir.FirstInstance()
+	if wsType, _ := dir.Config.GetEnum("workspace", "temp-schema", "docker", "local-binary"); wsType == "temp-schema" || !dir.Config.Changed("flavor") {
+		if err != nil {
+			return Options{}, err
+		} else if inst == nil {
+			return Options{}, fs.ConfigErrorf("This command needs either a host (with workspace=temp-schema) or flavor (with workspace=docker), but one is not configured for environment %q", dir.Config.Get("environment"))
+		}
+	}
+	return OptionsForDir(dir, inst)
 }
 
 func tempSchemaOptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
@@ -175,9 +208,49 @@ func tempSchemaOptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, er
 	return opts, nil
 }
 
-func localDockerOptionsForDir(dir *fs.Dir, instance *tengo.Instance) (opts Options, err error) {
+func localDockerOptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
+	opts, err := selfManagedOptionsForDir(dir, instance, TypeLocalDocker)
+	if err != nil {
+		return Options{}, err
+	}
+	opts.ContainerName = "skeema-" + tengo.ContainerNameForImage(opts.Flavor.String())
+	if opts.ContainerRuntime, err = dir.Config.GetEnum("container-runtime", "auto", "docker", "podman", "nerdctl"); err != nil {
+		return Options{}, err
+	}
+	if !dir.Config.Supplied("docker-cleanup") {
+		log.Debug("Upgrade notice: the --docker-cleanup option, which currently defaults to \"none\" in Skeema v1, will change to default to \"stop\" in Skeema v2. For more information, visit https://www.skeema.io/v2-changes")
+	}
+	if cleanup, err := dir.Config.GetEnum("docker-cleanup", "none", "stop", "destroy"); err != nil {
+		return Options{}, err
+	} else if cleanup == "stop" {
+		opts.CleanupAction = CleanupActionStop
+	} else if cleanup == "destroy" {
+		opts.CleanupAction = CleanupActionDestroy
+	}
+	return opts, nil
+}
+
+func localBinaryOptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
+	opts, err := selfManagedOptionsForDir(dir, instance, TypeLocalBinary)
+	if err != nil {
+		return Options{}, err
+	}
+	if cleanup, err := dir.Config.GetEnum("local-binary-cleanup", "none", "stop", "destroy"); err != nil {
+		return Options{}, err
+	} else if cleanup == "stop" {
+		opts.CleanupAction = CleanupActionStop
+	} else if cleanup == "destroy" {
+		opts.CleanupAction = CleanupActionDestroy
+	}
+	opts.BinaryPath, err = binaryPathForFlavor(dir.Config.GetSlice("local-binary-path", ',', true), opts.Flavor)
+	return opts, err
+}
+
+// selfManagedOptionsForDir returns Options common to workspace types which
+// operate on a database server managed by Skeema on localhost.
+func selfManagedOptionsForDir(dir *fs.Dir, instance *tengo.Instance, wsType Type) (opts Options, err error) {
 	opts = Options{
-		Type:            TypeLocalDocker,
+		Type:            wsType,
 		CleanupAction:   CleanupActionNone,
 		Flavor:          tengo.ParseFlavor(dir.Config.Get("flavor")),
 		SchemaName:      dir.Config.GetAllowEnvVar("temp-schema"),
@@ -211,20 +284,39 @@ func localDockerOptionsForDir(dir *fs.Dir, instance *tengo.Instance) (opts Optio
 			opts.Flavor = instFlavor.Family()
 		}
 	}
-	opts.ContainerName = "skeema-" + tengo.ContainerNameForImage(opts.Flavor.String())
-	if !dir.Config.Supplied("docker-cleanup") {
-		log.Debug("Upgrade notice: the --docker-cleanup option, which currently defaults to \"none\" in Skeema v1, will change to default to \"stop\" in Skeema v2. For more information, visit https://www.skeema.io/v2-changes")
-	}
-	if cleanup, err := dir.Config.GetEnum("docker-cleanup", "none", "stop", "destroy"); err != nil {
-		return Options{}, err
-	} else if cleanup == "stop" {
-		opts.CleanupAction = CleanupActionStop
-	} else if cleanup == "destroy" {
-		opts.CleanupAction = CleanupActionDestroy
-	}
 	return opts, nil
 }
 
+// binaryPathForFlavor returns the server binary path to use for flavor, based
+// on the supplied local-binary-path entries. Each entry is either a path, or
+// of the form "flavor=path" to only apply to a specific flavor; for example
+// "mysql:8.0=/opt/mysql80/bin/mysqld" or "mariadb=/usr/sbin/mariadbd". The
+// first flavor-specific entry matching flavor's vendor, variant, and (if
+// specified) major.minor version is used. Otherwise, the last entry lacking a
+// flavor is used, if any. An empty string is returned if nothing matches, in
+// which case the binary should be located using the PATH.
+func binaryPathForFlavor(entries []string, flavor tengo.Flavor) (string, error) {
+	var fallback string
+	for _, entry := range entries {
+		key, path, hasFlavor := strings.Cut(entry, "=")
+		if !hasFlavor {
+			fallback = entry
+			continue
+		}
+		keyFlavor := tengo.ParseFlavor(key)
+		if keyFlavor.Vendor == tengo.VendorUnknown {
+			return "", fmt.Errorf("Unable to parse flavor %q in option local-binary-path", key)
+		}
+		if keyFlavor.Vendor != flavor.Vendor || keyFlavor.Variants != flavor.Variants {
+			continue
+		}
+		if keyFlavor.Version.Major() == 0 || (keyFlavor.Version.Major() == flavor.Version.Major() && keyFlavor.Version.Minor() == flavor.Version.Minor()) {
+			return path, nil
+		}
+	}
+	return fallback, nil
+}
+
 // AddCommandOptions adds workspace-related option definitions to the supplied
 // mybase.Command.
 func AddCommandOptions(cmd *mybase.Command) {
@@ -233,8 +325,12 @@ func AddCommandOptions(cmd *mybase.Command) {
 		mybase.StringOption("temp-schema-binlog", 0, "auto", `Controls whether temp schema DDL operations are replicated (valid values: "on", "off", "auto")`).MarkDeprecated("This option will be removed in Skeema v2, with \"auto\" behavior always being used. For more information, visit https://www.skeema.io/v2-changes"),
 		mybase.StringOption("temp-schema-mode", 0, "regular", `Tunes workspace load with workspace=temp-schema; heavier load makes Skeema faster but may disrupt other workloads on the database (valid values: "serial", "light", "regular", "heavy", "extreme")`),
 		mybase.StringOption("temp-schema-threads", 0, "5", "Deprecated manner of controlling workspace load with workspace=temp-schema").MarkDeprecated("This option will be removed in Skeema v2. Use the new temp-schema-mode enum option instead. See --help or visit https://www.skeema.io/docs/options/#temp-schema-mode"),
-		mybase.StringOption("workspace", 'w', "temp-schema", `Specifies where to run intermediate operations (valid values: "temp-schema", "docker")`),
+		mybase.StringOption("workspace", 'w', "temp-schema", `Specifies where to run intermediate operations (valid values: "temp-schema", "docker", "local-binary")`),
 		mybase.StringOption("docker-cleanup", 0, "none", `With --workspace=docker, specifies how to clean up containers (valid values: "none", "stop", "destroy")`),
+		mybase.StringOption("container-runtime", 0, "auto", `With --workspace=docker, specifies which container command-line client to use (valid values: "auto", "docker", "podman", "nerdctl")`),
+		mybase.StringOption("local-binary-path", 0, "", "With --workspace=local-binary, path to mysqld or mariadbd binary; use comma-separated flavor=path entries to vary by flavor (default searches PATH)"),
+		mybase.StringOption("local-binary-cleanup", 0, "none", `With --workspace=local-binary, specifies how to clean up server processes (valid values: "none", "stop", "destroy")`),
+		mybase.BoolOption("workspace-cache", 0, true, "Re-use cached results from a previous identical run of *.sql files in a workspace"),
 		mybase.BoolOption("reuse-temp-schema", 0, false, "(deprecated and hidden)").Hidden().MarkDeprecated("This option will be removed in Skeema v2."),
 	)
 }
@@ -32,7 +32,10 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 	// Test error conditions
 	assertOptsError("--workspace=invalid", true)
 	assertOptsError("--workspace=docker --docker-cleanup=invalid", true)
+	assertOptsError("--workspace=docker --container-runtime=invalid", true)
 	assertOptsError("--workspace=docker --connect-options='autocommit=0'", false)
+	assertOptsError("--workspace=local-binary --local-binary-cleanup=invalid", true)
+	assertOptsError("--workspace=local-binary --local-binary-path=banana:1.2=/usr/sbin/mysqld", true)
 	assertOptsError("--workspace=temp-schema --temp-schema-threads=0", true)
 	assertOptsError("--workspace=temp-schema --temp-schema-threads=-20", true)
 	assertOptsError("--workspace=temp-schema --temp-schema-threads=banana", true)
@@ -89,11 +92,25 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
 	}
 
+	// Test docker with a specific container runtime
+	if opts = getOpts("--workspace=docker --container-runtime=podman"); opts.ContainerRuntime != "podman" {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+
 	// Test docker with specific flavor
 	if opts = getOpts("--workspace=docker --flavor=mysql:8.4"); opts.Flavor.String() != "mysql:8.4" {
 		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
 	}
 
+	// Test local-binary with defaults and with a cleanup action
+	opts = getOpts("--workspace=local-binary")
+	if opts.Type != TypeLocalBinary || opts.CleanupAction != CleanupActionNone || opts.Flavor.String() != expectFlavorString || opts.BinaryPath != "" || opts.ContainerName != "" {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+	if opts = getOpts("--workspace=local-binary --local-binary-cleanup=destroy --local-binary-path=/opt/bin/mysqld"); opts.CleanupAction != CleanupActionDestroy || opts.BinaryPath != "/opt/bin/mysqld" {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+
 	// Mess with the instance and its sql_mode, to simulate docker workspace using
 	// a real instance's nonstandard sql_mode
 	s.d.ExecSQL(t, "SET GLOBAL sql_mode = 'REAL_AS_FLOAT,PIPES_AS_CONCAT'")
@@ -117,3 +134,31 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 		}
 	}
 }
+
+func TestBinaryPathForFlavor(t *testing.T) {
+	entries := []string{"/usr/sbin/mysqld", "mariadb:10.11=/opt/mariadb1011/bin/mariadbd", "mariadb=/opt/mariadb/bin/mariadbd", "percona:8.0=/opt/ps80/bin/mysqld"}
+	cases := map[string]string{
+		"mariadb:10.11":   "/opt/mariadb1011/bin/mariadbd",
+		"mariadb:10.11.8": "/opt/mariadb1011/bin/mariadbd",
+		"mariadb:11.4":    "/opt/mariadb/bin/mariadbd",
+		"percona:8.0":     "/opt/ps80/bin/mysqld",
+		"percona:8.4":     "/usr/sbin/mysqld",
+		"mysql:8.0":       "/usr/sbin/mysqld",
+		"":                "/usr/sbin/mysqld",
+	}
+	for input, expected := range cases {
+		if actual, err := binaryPathForFlavor(entries, tengo.ParseFlavor(input)); err != nil || actual != expected {
+			t.Errorf("Expected binaryPathForFlavor(%q) to return %q, nil; instead found %q, %v", input, expected, actual, err)
+		}
+	}
+
+	// Without an entry lacking a flavor, result should be blank if nothing matches
+	if actual, err := binaryPathForFlavor(entries[1:], tengo.ParseFlavor("mysql:8.0")); err != nil || actual != "" {
+		t.Errorf("Expected binaryPathForFlavor to return blank string, instead found %q, %v", actual, err)
+	}
+
+	// Invalid flavor should return an error
+	if _, err := binaryPathForFlavor([]string{"oracle:23=/opt/oracle"}, tengo.ParseFlavor("mysql:8.0")); err == nil {
+		t.Error("Expected binaryPathForFlavor to return an error for an invalid flavor, but it did not")
+	}
+}
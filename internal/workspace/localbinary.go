@@ -0,0 +1,251 @@
+package workspace
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+// LocalBinary is a Workspace created inside of a database server process
+// running on localhost, launched directly from a mysqld or mariadbd binary
+// without use of Docker. The schema is dropped when done interacting with the
+// workspace in Cleanup(), but the server remains running for re-use in
+// subsequent workspaces. The server may optionally be stopped or destroyed via
+// Shutdown().
+type LocalBinary struct {
+	schemaName        string
+	ls                *tengo.LocalServer
+	releaseLock       releaseFunc
+	cleanupAction     CleanupAction
+	defaultConnParams string
+}
+
+// lsstore is a mutex-protected mapping of workspace servers started or found by
+// this process, keyed by base directory.
+var lsstore struct {
+	servers map[string]*tengo.LocalServer
+	sync.Mutex
+}
+
+// NewLocalBinary finds or starts a local database server process, creates a
+// temporary schema on it, and returns it.
+func NewLocalBinary(opts Options) (_ *LocalBinary, retErr error) {
+	// Note: see comment in NewLocalDocker regarding the named error return.
+
+	binaryPath, err := findServerBinary(opts)
+	if err != nil {
+		return nil, err
+	}
+	flavor, err := tengo.LocalServerFlavor(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Flavor.Known() && opts.Flavor.Family() != flavor.Family() {
+		log.Warnf("workspace=local-binary: %s is flavor %s, which does not match expected flavor %s", binaryPath, flavor.Family(), opts.Flavor.Family())
+	}
+	if supported, details := flavor.Supported(); !supported {
+		return nil, errors.New(details)
+	} else if details != "" { // flavor IS supported, but has some warning note e.g. deprecation or too new
+		log.Warn("workspace=local-binary: ", details)
+	}
+
+	lsstore.Lock()
+	defer lsstore.Unlock()
+	if lsstore.servers == nil {
+		lsstore.servers = make(map[string]*tengo.LocalServer)
+		tengo.UseFilteredDriverLogger()
+	}
+
+	lb := &LocalBinary{
+		schemaName:        opts.SchemaName,
+		cleanupAction:     opts.CleanupAction,
+		defaultConnParams: opts.DefaultConnParams,
+	}
+
+	lsopts := tengo.LocalServerOptions{
+		BinaryPath: binaryPath,
+	}
+	if opts.NameCaseMode == tengo.NameCaseLower {
+		lsopts.LowerCaseTableNames = 1
+	}
+	if lsopts.BaseDir, err = localBinaryBaseDir(flavor, lsopts.LowerCaseTableNames); err != nil {
+		return nil, err
+	}
+
+	if lsstore.servers[lsopts.BaseDir] != nil {
+		lb.ls = lsstore.servers[lsopts.BaseDir]
+	} else {
+		// DefaultConnParams is intentionally not set here at the LocalServer level,
+		// for the same reasons described in LocalDocker.ConnectionPool().
+		log.Infof("Using local server %s (binary=%s) for workspace operations", lsopts.BaseDir, binaryPath)
+		lb.ls, err = tengo.GetOrCreateLocalServer(lsopts)
+		if err != nil {
+			return nil, err
+		}
+		lsstore.servers[lsopts.BaseDir] = lb.ls
+		RegisterShutdownFunc(lb.shutdown)
+	}
+
+	lockName := "skeema." + lb.schemaName
+	if lb.releaseLock, err = getLock(lb.ls.Instance, lockName, opts.LockTimeout); err != nil {
+		return nil, fmt.Errorf("Unable to obtain workspace lock on local server %s: %w\n"+
+			"This may happen when running multiple copies of Skeema concurrently from the same client machine, in which case configuring --temp-schema differently for each copy on the command-line may help.",
+			lb.ls.Instance, err)
+	}
+	// If this function returns an error, don't continue to hold the lock. (Without
+	// an error, the lock intentionally remains held until Cleanup is called.)
+	defer func() {
+		if retErr != nil {
+			lb.releaseLock()
+		}
+	}()
+
+	if has, err := lb.ls.HasSchema(lb.schemaName); err != nil {
+		return nil, fmt.Errorf("Unable to check for existence of temp schema on %s: %s", lb.ls.Instance, err)
+	} else if has {
+		// Attempt to drop the schema, so we can recreate it below. Fail if any tables
+		// actually have 1 or more rows.
+		dropOpts := tengo.BulkDropOptions{
+			OneShot:     true,
+			OnlyIfEmpty: true,
+			SkipBinlog:  false, // binlog always disabled in our managed servers
+		}
+		if err := lb.ls.DropSchema(lb.schemaName, dropOpts); err != nil {
+			return nil, fmt.Errorf("Cannot drop existing temporary schema on %s: %s", lb.ls.Instance, err)
+		}
+	}
+
+	createOpts := tengo.SchemaCreationOptions{
+		DefaultCharSet:   opts.DefaultCharacterSet,
+		DefaultCollation: opts.DefaultCollation,
+		SkipBinlog:       false, // binlog always disabled in our managed servers
+	}
+	if _, err := lb.ls.CreateSchema(lb.schemaName, createOpts); err != nil {
+		return nil, fmt.Errorf("Cannot create temporary schema on %s: %s", lb.ls.Instance, err)
+	}
+	return lb, nil
+}
+
+// localBinaryBaseDir returns the base directory for a local server of the
+// supplied flavor. Each flavor family gets its own base directory, and
+// lower_case_table_names cannot be changed after data directory initialization,
+// so it also requires a separate base directory if used. Base directories are
+// located in the user's cache directory, rather than a shared temp directory,
+// since the servers' root users have no password.
+func localBinaryBaseDir(flavor tengo.Flavor, lctn uint8) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("workspace=local-binary: unable to determine user cache directory: %w", err)
+	}
+	name := "skeema-" + tengo.ContainerNameForImage(flavor.Family().String())
+	if lctn > 0 {
+		name += fmt.Sprintf("-lctn%d", lctn)
+	}
+	return filepath.Join(cacheDir, "skeema", "local-binary", name), nil
+}
+
+// findServerBinary returns the absolute path of the server binary to use. If
+// opts.BinaryPath is blank, a binary named mysqld (or mariadbd, for MariaDB
+// flavors) is searched for among directories in PATH.
+func findServerBinary(opts Options) (string, error) {
+	if opts.BinaryPath != "" {
+		path, err := exec.LookPath(opts.BinaryPath)
+		if err != nil {
+			return "", fmt.Errorf("Unable to use local-binary-path %s: %w", opts.BinaryPath, err)
+		}
+		return filepath.Abs(path)
+	}
+	names := []string{"mysqld"}
+	if opts.Flavor.IsMariaDB() {
+		names = []string{"mariadbd", "mysqld"}
+	}
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return filepath.Abs(path)
+		}
+	}
+	return "", fmt.Errorf("Unable to find %s among directories in PATH. Use the local-binary-path option to specify the location of the database server binary.", names[0])
+}
+
+// ConnectionPool returns a connection pool (*sql.DB) to the temporary
+// workspace schema, using the supplied connection params (which may be blank).
+// As with LocalDocker, the params arg is merged over top of the LocalBinary's
+// dir-dependent params.
+func (lb *LocalBinary) ConnectionPool(params string) (*sql.DB, error) {
+	finalParams := tengo.MergeParamStrings(lb.defaultConnParams, params, "tls=false")
+	return connectionPoolPortableSQLMode(lb.ls.Instance, lb.schemaName, finalParams)
+}
+
+// IntrospectSchema introspects and returns the temporary workspace schema.
+func (lb *LocalBinary) IntrospectSchema() (IntrospectionResult, error) {
+	schema, err := lb.ls.Schema(lb.schemaName)
+	result := IntrospectionResult{
+		Schema:  schema,
+		Flavor:  lb.ls.Flavor(),
+		SQLMode: lb.ls.SQLMode(),
+		Info:    "local-binary (socket=" + lb.ls.SocketPath() + ")",
+	}
+	return result, err
+}
+
+// Cleanup drops the temporary schema from the local server. Cleanup does not
+// handle stopping or destroying the server. If requested, that is handled by
+// Shutdown() instead, so that servers aren't needlessly started and stopped
+// multiple times during a program's execution.
+func (lb *LocalBinary) Cleanup(schema *tengo.Schema) error {
+	if lb.releaseLock == nil {
+		return errors.New("Cleanup() called multiple times on same LocalBinary")
+	}
+	defer func() {
+		lb.releaseLock()
+		lb.releaseLock = nil
+	}()
+
+	dropOpts := tengo.BulkDropOptions{
+		OneShot:     true,   // call DROP DATABASE without first dropping tables
+		OnlyIfEmpty: false,  // NewLocalBinary *never* reuses existing schemas, so we know we created it
+		SkipBinlog:  false,  // binlog always disabled in our managed servers
+		Schema:      schema, // may be nil, not a problem
+	}
+	if err := lb.ls.DropSchema(lb.schemaName, dropOpts); err != nil {
+		return fmt.Errorf("Cannot drop temporary schema on %s: %s", lb.ls.Instance, err)
+	}
+	return nil
+}
+
+// shutdown handles shutdown logic for a specific LocalBinary instance. A single
+// string arg may optionally be supplied as a name prefix: if the final element
+// of the server's base directory does not begin with the prefix, no shutdown
+// occurs.
+func (lb *LocalBinary) shutdown(args ...any) bool {
+	if len(args) > 0 {
+		if prefix, ok := args[0].(string); !ok || !strings.HasPrefix(filepath.Base(lb.ls.BaseDir()), prefix) {
+			return false
+		}
+	}
+
+	lsstore.Lock()
+	defer lsstore.Unlock()
+
+	if lb.cleanupAction == CleanupActionStop {
+		log.Infof("Stopping local server %s", lb.ls.BaseDir())
+		if err := lb.ls.Stop(); err != nil {
+			log.Warnf("Failed to stop local server %s: %v", lb.ls.BaseDir(), err)
+		}
+	} else if lb.cleanupAction == CleanupActionDestroy {
+		log.Infof("Destroying local server %s", lb.ls.BaseDir())
+		if err := lb.ls.Destroy(); err != nil {
+			log.Warnf("Failed to destroy local server %s: %v", lb.ls.BaseDir(), err)
+		}
+	}
+	delete(lsstore.servers, lb.ls.BaseDir())
+	return true
+}
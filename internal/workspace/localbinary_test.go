@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/tengo"
+)
+
+func TestLocalBinaryBaseDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Skipf("Unable to determine user cache dir: %v", err)
+	}
+	flavor := tengo.ParseFlavor("mysql:8.0.36")
+	expected := filepath.Join(cacheDir, "skeema", "local-binary", "skeema-mysql-8.0")
+	if baseDir, err := localBinaryBaseDir(flavor, 0); err != nil || baseDir != expected {
+		t.Errorf("Unexpected result from localBinaryBaseDir: %q, %v", baseDir, err)
+	}
+	if baseDir, err := localBinaryBaseDir(flavor, 1); err != nil || baseDir != expected+"-lctn1" {
+		t.Errorf("Unexpected result from localBinaryBaseDir with lctn=1: %q, %v", baseDir, err)
+	}
+	if !strings.HasPrefix(filepath.Base(expected), "skeema-") {
+		t.Errorf("Base dir name %q must begin with skeema- for Shutdown prefix matching", expected)
+	}
+}
+
+func TestFindServerBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Test relies on executable permission bits")
+	}
+	binDir := t.TempDir()
+	for _, name := range []string{"mysqld", "mariadbd"} {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0700); err != nil {
+			t.Fatalf("Unable to write fake binary: %v", err)
+		}
+	}
+	t.Setenv("PATH", binDir)
+
+	cases := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{Flavor: tengo.ParseFlavor("mysql:8.0")}, "mysqld"},
+		{Options{Flavor: tengo.ParseFlavor("mariadb:11.4")}, "mariadbd"},
+		{Options{Flavor: tengo.ParseFlavor("mariadb:11.4"), BinaryPath: filepath.Join(binDir, "mysqld")}, "mysqld"},
+	}
+	for _, c := range cases {
+		if path, err := findServerBinary(c.opts); err != nil || path != filepath.Join(binDir, c.expected) {
+			t.Errorf("Unexpected result from findServerBinary(%+v): %q, %v", c.opts, path, err)
+		}
+	}
+
+	if _, err := findServerBinary(Options{BinaryPath: filepath.Join(binDir, "doesnotexist")}); err == nil {
+		t.Error("Expected error from findServerBinary with nonexistent BinaryPath, but err was nil")
+	}
+	t.Setenv("PATH", t.TempDir())
+	if _, err := findServerBinary(Options{}); err == nil {
+		t.Error("Expected error from findServerBinary with no binary on PATH, but err was nil")
+	}
+}
+
+// TestNewLocalBinary requires env var SKEEMA_TEST_LOCAL_BINARY to be set to
+// the path of a mysqld or mariadbd binary.
+func TestNewLocalBinary(t *testing.T) {
+	binaryPath := os.Getenv("SKEEMA_TEST_LOCAL_BINARY")
+	if binaryPath == "" {
+		t.Skip("Skipping local-binary workspace testing. To run, set env SKEEMA_TEST_LOCAL_BINARY to the path of a mysqld or mariadbd binary.")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	opts := Options{
+		Type:                TypeLocalBinary,
+		CleanupAction:       CleanupActionDestroy,
+		BinaryPath:          binaryPath,
+		SchemaName:          "_skeema_tmp",
+		DefaultCharacterSet: "latin1",
+		DefaultCollation:    "latin1_swedish_ci",
+		LockTimeout:         100 * time.Millisecond,
+		CreateThreads:       4,
+	}
+	ws, err := New(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error from New(): %s", err)
+	}
+	lb := ws.(*LocalBinary)
+	if _, err := New(opts); err == nil {
+		t.Error("Expected error from already-locked workspace, instead err is nil")
+	}
+	db, err := lb.ConnectionPool("")
+	if err != nil {
+		t.Fatalf("Unexpected error from ConnectionPool(): %s", err)
+	}
+	if _, err := db.Exec("CREATE TABLE foo (id int unsigned NOT NULL, PRIMARY KEY (id))"); err != nil {
+		t.Fatalf("Unexpected error creating table in workspace: %s", err)
+	}
+	result, err := lb.IntrospectSchema()
+	if err != nil {
+		t.Fatalf("Unexpected error from IntrospectSchema(): %s", err)
+	} else if result.Schema.Table("foo") == nil || !strings.Contains(result.Info, lb.ls.SocketPath()) {
+		t.Errorf("Unexpected result from IntrospectSchema(): %+v", result)
+	}
+	if err := lb.Cleanup(result.Schema); err != nil {
+		t.Fatalf("Unexpected error from Cleanup(): %s", err)
+	} else if err := lb.Cleanup(result.Schema); err == nil {
+		t.Error("Expected error from second call to Cleanup(), instead err is nil")
+	}
+
+	baseDir := lb.ls.BaseDir()
+	if fi, err := os.Stat(baseDir); err != nil || fi.Mode().Perm() != 0700 {
+		t.Errorf("Expected base dir %s to exist with mode 0700; Stat returned %v, %v", baseDir, fi, err)
+	}
+	Shutdown()
+	if _, err := os.Stat(baseDir); !os.IsNotExist(err) {
+		t.Errorf("Expected base dir to be removed by Shutdown with CleanupActionDestroy, but Stat returned %v", err)
+	}
+}
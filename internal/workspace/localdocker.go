@@ -67,17 +67,24 @@ func NewLocalDocker(opts Options) (_ *LocalDocker, retErr error) {
 		defaultConnParams: opts.DefaultConnParams,
 	}
 
-	// Determine image and container name
+	// Determine container runtime, image, and container name
+	if err := tengo.SetContainerRuntime(opts.ContainerRuntime); err != nil {
+		return nil, err
+	}
 	arch, err := tengo.DockerEngineArchitecture()
 	if err != nil {
 		return nil, err
 	}
-	ld.image, err = DockerImageForFlavor(opts.Flavor, arch)
+	runtime, err := tengo.ContainerRuntime()
+	if err != nil {
+		return nil, err
+	}
+	ld.image, err = DockerImageForFlavor(opts.Flavor, arch, runtime)
 	if err != nil {
 		// Try again without a specific patch number, as that is the only potential
 		// source of errors (old patches not available for arm64)
 		var err2 error
-		ld.image, err2 = DockerImageForFlavor(opts.Flavor.Family(), arch)
+		ld.image, err2 = DockerImageForFlavor(opts.Flavor.Family(), arch, runtime)
 		if err2 != nil {
 			return nil, err
 		} else {
@@ -181,23 +188,27 @@ func (ld *LocalDocker) ConnectionPool(params string) (*sql.DB, error) {
 	// We also forcibly disable tls in a way which cannot be overridden, since the
 	// Docker container is local.
 	finalParams := tengo.MergeParamStrings(ld.defaultConnParams, params, "tls=false")
-	db, err := ld.d.CachedConnectionPool(ld.schemaName, finalParams)
+	return connectionPoolPortableSQLMode(ld.d.Instance, ld.schemaName, finalParams)
+}
 
-	// In the rare situation where OptionsForDir obtained sql_mode from a live
-	// instance of different flavor than our Docker image's flavor, connections may
-	// hit Error 1231 (42000): Variable 'sql_mode' can't be set to the value ...
-	// This can happen if overriding flavor on the command-line. In this case, try
-	// conn again with all non-portable sql_mode values removed.
+// connectionPoolPortableSQLMode returns a connection pool to schemaName on a
+// self-managed local instance, using finalParams.
+// In the rare situation where OptionsForDir obtained sql_mode from a live
+// instance of different flavor than the local instance's flavor, connections
+// may hit Error 1231 (42000): Variable 'sql_mode' can't be set to the value ...
+// This can happen if overriding flavor on the command-line. In this case, try
+// conn again with all non-portable sql_mode values removed.
+func connectionPoolPortableSQLMode(inst *tengo.Instance, schemaName, finalParams string) (*sql.DB, error) {
+	db, err := inst.CachedConnectionPool(schemaName, finalParams)
 	if tengo.IsSessionVarValueError(err) && strings.Contains(err.Error(), "sql_mode") && strings.Contains(finalParams, "sql_mode") {
 		v, _ := url.ParseQuery(finalParams)
 		if sqlMode := v.Get("sql_mode"); len(sqlMode) > 1 {
 			sqlMode = sqlMode[1 : len(sqlMode)-1] // strip leading/trailing single-quotes
 			v.Set("sql_mode", "'"+tengo.FilterSQLMode(sqlMode, tengo.NonPortableSQLModes)+"'")
 			finalParams = v.Encode()
-			db, err = ld.d.CachedConnectionPool(ld.schemaName, finalParams)
+			db, err = inst.CachedConnectionPool(schemaName, finalParams)
 		}
 	}
-
 	return db, err
 }
 
@@ -278,12 +289,15 @@ func (ld *LocalDocker) shutdown(args ...any) bool {
 }
 
 // DockerImageForFlavor attempts to return the name of a Docker image for the
-// supplied flavor and arch. The arch should be supplied in the same format as
-// returned by tengo.DockerEngineArchitecture(), i.e. "amd64" or "arm64".
+// supplied flavor, arch, and container runtime. The arch should be supplied in
+// the same format as returned by tengo.DockerEngineArchitecture(), i.e. "amd64"
+// or "arm64". The runtime should be a value returned by
+// tengo.ContainerRuntime(), and affects whether the image name is qualified
+// with a registry.
 // In most cases this function returns "Docker official" Dockerhub images (top-
 // level repos without an account name), but in some cases we must use a
 // different source, or return an error.
-func DockerImageForFlavor(flavor tengo.Flavor, arch string) (string, error) {
+func DockerImageForFlavor(flavor tengo.Flavor, arch, runtime string) (string, error) {
 	// Some of the logic below operates on ranges of old patch numbers, but if the
 	// flavor intentionally omitted a specific patch number (as is common),
 	// we want to treat it as the highest value in these comparisons instead
@@ -324,7 +338,8 @@ func DockerImageForFlavor(flavor tengo.Flavor, arch string) (string, error) {
 		// The top-level "percona" images lack arm64 support, and they don't have 8.1+
 		// at all anyway. So we always use percona/percona-server instead, even on
 		// amd64 just for consistency across archs.
-		return strings.Replace(image, "percona:", "percona/percona-server:", 1), nil
+		image = strings.Replace(image, "percona:", "percona/percona-server:", 1)
+		return tengo.QualifiedImageName(image, runtime), nil
 	}
 
 	// Aurora flavors from Skeema Premium: use corresponding MySQL image
@@ -346,5 +361,5 @@ func DockerImageForFlavor(flavor tengo.Flavor, arch string) (string, error) {
 		}
 	}
 
-	return image, nil
+	return tengo.QualifiedImageName(image, runtime), nil
 }
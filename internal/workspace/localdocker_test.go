@@ -258,9 +258,24 @@ func TestDockerImageForFlavor(t *testing.T) {
 	}
 	for _, tc := range testcases {
 		flavor := tengo.ParseFlavor(tc.flavor)
-		image, err := DockerImageForFlavor(flavor, tc.arch)
+		image, err := DockerImageForFlavor(flavor, tc.arch, "docker")
 		if image != tc.expectImage || ((err != nil) != tc.expectErr) {
-			t.Errorf("Unexpected return from DockerImageForFlavor(%q, %q): found %q, %v", tc.flavor, tc.arch, image, err)
+			t.Errorf("Unexpected return from DockerImageForFlavor(%q, %q, \"docker\"): found %q, %v", tc.flavor, tc.arch, image, err)
+		}
+	}
+
+	// Podman requires fully-qualified image names
+	podmanTestcases := map[string]string{
+		"mysql:8.0":      "docker.io/library/mysql:8.0",
+		"mysql:8.0.28":   "docker.io/mysql/mysql-server:8.0.28",
+		"percona:8.0.33": "docker.io/percona/percona-server:8.0.33-aarch64",
+		"aurora:8.0":     "docker.io/library/mysql:8.0",
+		"mariadb:11.2":   "docker.io/library/mariadb:11.2",
+	}
+	for input, expected := range podmanTestcases {
+		image, err := DockerImageForFlavor(tengo.ParseFlavor(input), "arm64", "podman")
+		if image != expected || err != nil {
+			t.Errorf("Unexpected return from DockerImageForFlavor(%q, \"arm64\", \"podman\"): found %q, %v", input, image, err)
 		}
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/tengo"
 )
 
@@ -244,3 +245,47 @@ func TestTempSchemaNilInstance(t *testing.T) {
 		t.Fatal("Expected non-nil error from NewTempSchema, but return was nil")
 	}
 }
+
+func TestTempSchemaExecStatementsRestricted(t *testing.T) {
+	// Only statements confined to the workspace schema are permitted with
+	// TypeTempSchema. This is checked before the workspace is created, so no
+	// Instance is needed.
+	opts := Options{
+		Type:       TypeTempSchema,
+		SchemaName: "_skeema_tmp",
+	}
+	inputs := []string{
+		"DROP TABLE foo",
+		"UPDATE other.foo SET id=2",
+		"SET GLOBAL read_only=1",
+		"CREATE TABLE foo SELECT * FROM other.bar",
+		"RENAME TABLE foo TO bar, prod.users TO foo",
+		"ALTER TABLE foo RENAME TO otherdb.foo",
+		"ALTER TABLE foo ADD COLUMN name varchar(30), RENAME AS otherdb.foo",
+	}
+	for _, input := range inputs {
+		statements, err := tengo.ParseStatementsInString("CREATE TABLE foo (id int);\n" + input + ";\n")
+		if err != nil {
+			t.Fatalf("Unexpected error parsing statements: %v", err)
+		}
+		_, err = ExecStatements(statements, opts)
+		if _, ok := err.(fs.ConfigError); !ok {
+			t.Errorf("Expected ExecStatements to return a ConfigError for %q, instead found %v", input, err)
+		}
+	}
+
+	// Renaming columns and indexes is permitted
+	statements, err := tengo.ParseStatementsInString("ALTER TABLE foo RENAME COLUMN id TO foo_id, RENAME INDEX `rename` TO idx;\n")
+	if err != nil {
+		t.Fatalf("Unexpected error parsing statements: %v", err)
+	}
+	if renamesTable(statements[0]) {
+		t.Errorf("Expected renamesTable to return false for %q, but it returned true", statements[0].Text)
+	}
+	for _, input := range inputs[4:] {
+		statements, _ := tengo.ParseStatementsInString(input + ";\n")
+		if !renamesTable(statements[0]) {
+			t.Errorf("Expected renamesTable to return true for %q, but it returned false", input)
+		}
+	}
+}
@@ -60,6 +60,8 @@ func New(opts Options) (Workspace, error) {
 		return NewTempSchema(opts)
 	case TypeLocalDocker:
 		return NewLocalDocker(opts)
+	case TypeLocalBinary:
+		return NewLocalBinary(opts)
 	}
 	return nil, fmt.Errorf("Unsupported workspace type %v", opts.Type)
 }
@@ -162,12 +164,15 @@ func (timers Timers) String() string {
 // Schema captures the result of executing the SQL from an fs.LogicalSchema
 // in a workspace, and then introspecting the resulting schema. It wraps the
 // introspected tengo.Schema, alongside the original fs.LogicalSchema, the
-// Flavor of the workspace used, and any SQL errors that occurred.
+// Flavor of the workspace used, and any SQL errors that occurred. If the
+// LogicalSchema contains INSERT statements, Data maps table names to the
+// resulting managed rows.
 type Schema struct {
 	*tengo.Schema
 	LogicalSchema *fs.LogicalSchema
 	Flavor        tengo.Flavor
 	Failures      []*StatementError
+	Data          map[string]*tengo.TableData
 	Info          string // human-readable info on execution environment used for workspace
 	Timers        Timers // performance timing for each stage of workspace execution
 }
@@ -209,6 +214,12 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema
 		}
 	}
 
+	// If an identical workspace operation was already performed in a previous
+	// run, re-use its result
+	if cached := loadCachedSchema(logicalSchema, opts); cached != nil {
+		return cached, nil
+	}
+
 	timerStart := time.Now()
 	ws, err := New(opts)
 	if err != nil {
@@ -426,9 +437,11 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema
 	}
 
 	// Run additional sequential statements without concurrency.
-	// This includes any ALTER statements, which have concurrency issues with FKs.
+	// This includes any ALTER statements, which have concurrency issues with FKs,
+	// followed by any INSERT statements declaring managed table data.
 	var sequentialStatements []*tengo.Statement
 	sequentialStatements = append(sequentialStatements, logicalSchema.Alters...)
+	sequentialStatements = append(sequentialStatements, logicalSchema.Inserts...)
 	for _, stmt := range sequentialStatements {
 		if _, err := db.Exec(stmt.Body()); err != nil {
 			wsSchema.Failures = append(wsSchema.Failures, wrapFailure(stmt, err))
@@ -448,10 +461,144 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (_ *Schema
 	if err == nil && expectedObjectCount != wsSchema.Schema.ObjectCount() {
 		err = fmt.Errorf("Expected workspace to contain %d objects, but instead found %d", expectedObjectCount, wsSchema.Schema.ObjectCount())
 	}
+	if err == nil && len(logicalSchema.Inserts) > 0 {
+		err = wsSchema.queryData(db)
+	}
+	if err == nil {
+		storeCachedSchema(wsSchema, opts)
+	}
 
 	return wsSchema, err
 }
 
+// ExecStatements executes statements sequentially in a workspace, and returns
+// the resulting schema. Unlike ExecLogicalSchema, statements may be of any
+// type, such as ALTER TABLE or DML, and are all run in order on a single
+// connection, as is required for replaying schema migration files. Execution
+// stops at the first statement which fails, and a *StatementError is returned.
+// Any rows inserted by statements are deleted from the workspace before
+// cleanup.
+// Each statement's first object name has any schema name qualifier removed,
+// but other names in the statement are executed as-is. Since statements may
+// therefore refer to any schema, opts.Type must be TypeLocalDocker or
+// TypeLocalBinary for statements other than CREATE, ALTER, and INSERT, or for
+// statements which rename tables. With TypeTempSchema, the workspace is on a
+// real database server, so a fs.ConfigError is returned before anything is
+// executed.
+func ExecStatements(statements []*tengo.Statement, opts Options) (_ *tengo.Schema, retErr error) {
+	if opts.Type == TypeTempSchema {
+		for _, stmt := range statements {
+			if stmt.Type != tengo.StatementTypeCreate && stmt.Type != tengo.StatementTypeAlter && stmt.Type != tengo.StatementTypeInsert {
+				return nil, fs.ConfigErrorf("%s: only CREATE, ALTER, and INSERT statements may be run with workspace=temp-schema, since other statements could affect schemas outside of the workspace. Use workspace=docker or workspace=local-binary instead", stmt.Location())
+			} else if renamesTable(stmt) {
+				return nil, fs.ConfigErrorf("%s: statements which rename tables may not be run with workspace=temp-schema, since they could move tables into or out of the workspace. Use workspace=docker or workspace=local-binary instead", stmt.Location())
+			}
+		}
+	}
+	ws, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	var schema *tengo.Schema
+	defer func() {
+		// We only care about a cleanup error if the original returned error was nil
+		if cleanupErr := ws.Cleanup(schema); retErr == nil && cleanupErr != nil {
+			retErr = cleanupErr
+		}
+	}()
+
+	db, err := ws.ConnectionPool("")
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to workspace: %w", err)
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to workspace: %w", err)
+	}
+	defer conn.Close()
+	for _, stmt := range statements {
+		if _, err := conn.ExecContext(ctx, stmt.Body()); err != nil {
+			return nil, wrapFailure(stmt, err)
+		}
+	}
+
+	result, err := ws.IntrospectSchema()
+	if err != nil {
+		return nil, err
+	}
+	schema = result.Schema
+	if _, err := conn.ExecContext(ctx, "SET foreign_key_checks=0"); err != nil {
+		return nil, err
+	}
+	for _, table := range schema.Tables {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM "+tengo.EscapeIdentifier(table.Name)); err != nil {
+			return nil, fmt.Errorf("Unable to remove data rows from workspace table %s: %w", tengo.EscapeIdentifier(table.Name), err)
+		}
+	}
+	return schema, nil
+}
+
+// renamesTable returns true if stmt is a RENAME TABLE statement, or an ALTER
+// TABLE statement with a clause renaming the table. Clauses renaming columns,
+// indexes, or constraints are permitted. Since RENAME is a reserved word, it
+// cannot appear as an unquoted identifier.
+func renamesTable(stmt *tengo.Statement) bool {
+	tokens := tengo.TokenizeString(stmt.Body())
+	if len(tokens) == 0 {
+		return false
+	} else if strings.EqualFold(tokens[0], "RENAME") {
+		return true
+	} else if !strings.EqualFold(tokens[0], "ALTER") {
+		return false
+	}
+	for n, token := range tokens {
+		if !strings.EqualFold(token, "RENAME") {
+			continue
+		}
+		if n+1 >= len(tokens) {
+			return true
+		}
+		switch strings.ToUpper(tokens[n+1]) {
+		case "COLUMN", "INDEX", "KEY", "CONSTRAINT":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// queryData populates wsSchema.Data with the rows of each table that has
+// managed data in wsSchema.LogicalSchema. Afterwards, the rows are deleted
+// from the workspace, since workspace cleanup requires tables to be empty in
+// some configurations. Problems specific to one table, such as lacking a
+// primary key, are tracked in wsSchema.Failures.
+func (wsSchema *Schema) queryData(db *sql.DB) error {
+	wsSchema.Data = make(map[string]*tengo.TableData)
+	firstInserts := make(map[string]*tengo.Statement)
+	for _, stmt := range wsSchema.LogicalSchema.Inserts {
+		if firstInserts[stmt.ObjectName] == nil {
+			firstInserts[stmt.ObjectName] = stmt
+		}
+	}
+	for _, name := range wsSchema.LogicalSchema.InsertTableNames() {
+		table := wsSchema.Table(name)
+		if table == nil {
+			continue // CREATE TABLE failed, or INSERT refers to a nonexistent table
+		}
+		data, err := tengo.QueryTableData(db, table, nil)
+		if err != nil {
+			wsSchema.Failures = append(wsSchema.Failures, wrapFailure(firstInserts[name], err))
+		} else {
+			wsSchema.Data[name] = data
+		}
+		if _, err := db.Exec("DELETE FROM " + tengo.EscapeIdentifier(name)); err != nil {
+			return fmt.Errorf("Unable to remove managed data rows from workspace table %s: %w", tengo.EscapeIdentifier(name), err)
+		}
+	}
+	return nil
+}
+
 func wrapFailure(statement *tengo.Statement, err error) *StatementError {
 	stmtErr := &StatementError{
 		Statement: statement,
@@ -60,7 +60,7 @@ func (s *WorkspaceIntegrationSuite) getParsedDir(t *testing.T, dirPath, cliFlags
 	util.AddGlobalOptions(cmd)
 	AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
-	commandLine := fmt.Sprintf("workspacetest --host=%s --port=%d --password=fakepw %s", s.d.Instance.Host, s.d.Instance.Port, cliFlags)
+	commandLine := fmt.Sprintf("workspacetest --host=%s --port=%d --password=fakepw --skip-workspace-cache %s", s.d.Instance.Host, s.d.Instance.Port, cliFlags)
 	cfg := mybase.ParseFakeCLI(t, cmd, commandLine)
 
 	dir, err := fs.ParseDir(dirPath, cfg)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -149,6 +150,51 @@ func (s SkeemaIntegrationSuite) TestInitHandler(t *testing.T) {
 	s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir hasoptionfile --schema product -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 }
 
+func (s SkeemaIntegrationSuite) TestInitFromMigrations(t *testing.T) {
+	fs.WriteTestFile(t, "migrations/1_init.up.sql", "CREATE TABLE users (id int unsigned NOT NULL PRIMARY KEY, name varchar(30));\nINSERT INTO users VALUES (1, 'root');\n")
+	fs.WriteTestFile(t, "migrations/1_init.down.sql", "DROP TABLE users;\n")
+	fs.WriteTestFile(t, "migrations/2_posts.up.sql", "CREATE TABLE posts (id int unsigned NOT NULL PRIMARY KEY, user_id int unsigned NOT NULL);\nALTER TABLE users ADD COLUMN email varchar(100);\n")
+
+	// --schema is required; bad migrations dirs are errors
+	s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir legacy -h %s -P %d --from-migrations=migrations", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir legacy -h %s -P %d --from-migrations=doesnotexist --schema=legacy", s.d.Instance.Host, s.d.Instance.Port)
+
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir legacy -h %s -P %d --from-migrations=migrations --schema=legacy", s.d.Instance.Host, s.d.Instance.Port)
+	if contents := fs.ReadTestFile(t, "legacy/users.sql"); !strings.Contains(contents, "`email` varchar(100)") {
+		t.Errorf("Unexpected contents of legacy/users.sql:\n%s", contents)
+	}
+	if contents := fs.ReadTestFile(t, "legacy/posts.sql"); !strings.Contains(contents, "CREATE TABLE `posts`") {
+		t.Errorf("Unexpected contents of legacy/posts.sql:\n%s", contents)
+	}
+	if has, err := s.d.HasSchema("legacy"); has || err != nil {
+		t.Errorf("Expected --from-migrations to not create schema on the database server; HasSchema returned %t, %v", has, err)
+	}
+
+	// A failing migration should be reported with its location
+	fs.WriteTestFile(t, "migrations/3_broken.up.sql", "ALTER TABLE nonexistent ADD COLUMN foo int;\n")
+	s.handleCommand(t, CodeFatalError, ".", "skeema init --dir legacy2 -h %s -P %d --from-migrations=migrations --schema=legacy", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Migrations which could affect other schemas on the server are rejected
+	// before anything is run: USE commands, qualifiers naming a different schema,
+	// or statements other than CREATE, ALTER, or INSERT with temp-schema
+	s.d.ExecSQL(t, "CREATE TABLE analytics.victim (id int)")
+	for _, contents := range []string{"USE analytics;\nCREATE TABLE foo (id int);\n", "CREATE TABLE analytics.foo (id int);\n", "DROP TABLE analytics.victim;\n"} {
+		fs.WriteTestFile(t, "migrations/3_broken.up.sql", contents)
+		s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir legacy2 -h %s -P %d --from-migrations=migrations --schema=legacy", s.d.Instance.Host, s.d.Instance.Port)
+	}
+	if has, err := s.d.HasSchema("legacy"); has || err != nil {
+		t.Errorf("Expected --from-migrations to not create schema on the database server; HasSchema returned %t, %v", has, err)
+	}
+	s.d.ExecSQL(t, "SELECT 1 FROM analytics.victim") // fails the test if the table was dropped
+
+	// Qualifiers naming the same schema as --schema are permitted
+	fs.WriteTestFile(t, "migrations/3_broken.up.sql", "CREATE TABLE legacy.comments (id int);\n")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir legacy3 -h %s -P %d --from-migrations=migrations --schema=legacy", s.d.Instance.Host, s.d.Instance.Port)
+	if contents := fs.ReadTestFile(t, "legacy3/comments.sql"); !strings.Contains(contents, "CREATE TABLE `comments`") {
+		t.Errorf("Unexpected contents of legacy3/comments.sql:\n%s", contents)
+	}
+}
+
 func (s SkeemaIntegrationSuite) TestAddEnvHandler(t *testing.T) {
 	cfg := s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -397,6 +443,79 @@ func (s SkeemaIntegrationSuite) TestLintHandler(t *testing.T) {
 	s.handleCommand(t, CodeBadConfig, ".", "skeema lint")
 }
 
+func (s SkeemaIntegrationSuite) TestLintBaseline(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Enabling a new rule yields problems in the existing schema, which can be
+	// recorded to a baseline file
+	s.handleCommand(t, CodeFatalError, ".", "skeema lint --lint-has-time=error")
+	s.handleCommand(t, CodeFatalError, ".", "skeema lint --lint-has-time=error --write-baseline=baseline.json")
+	if contents := fs.ReadTestFile(t, "baseline.json"); !strings.Contains(contents, `"rule": "has-time"`) {
+		t.Errorf("Unexpected contents of baseline.json:\n%s", contents)
+	}
+
+	// Using the baseline suppresses the known problems, but not new ones
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint --lint-has-time=error --baseline=baseline.json")
+	fs.WriteTestFile(t, "mydb/product/events.sql", "CREATE TABLE events (id int PRIMARY KEY, created_at timestamp);\n")
+	s.handleCommand(t, CodeFatalError, ".", "skeema lint --lint-has-time=error --baseline=baseline.json")
+
+	// Nonexistent baseline file is a config error
+	s.handleCommand(t, CodeBadConfig, ".", "skeema lint --baseline=doesnt-exist.json")
+}
+
+func (s SkeemaIntegrationSuite) TestLintFix(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Without --fix, problems are only reported
+	contents := "CREATE TABLE events (\n" +
+		"  id int unsigned NOT NULL,\n" +
+		"  name varchar(30) CHARACTER SET utf8mb3 NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=MyISAM DEFAULT CHARSET=latin1;\n"
+	fs.WriteTestFile(t, "mydb/product/events.sql", contents)
+	s.handleCommand(t, CodePartialError, ".", "skeema lint --allow-engine=innodb --allow-charset=latin1,utf8mb4")
+
+	// With --fix, the problems are fixed and the file is rewritten. Since files
+	// were modified, the exit code indicates differences were found.
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema lint --fix --allow-engine=innodb --allow-charset=latin1,utf8mb4")
+	newContents := fs.ReadTestFile(t, "mydb/product/events.sql")
+	if !strings.Contains(newContents, "ENGINE=InnoDB") || !strings.Contains(newContents, "CHARACTER SET utf8mb4") {
+		t.Errorf("Unexpected contents of events.sql after lint --fix:\n%s", newContents)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint --allow-engine=innodb --allow-charset=latin1,utf8mb4")
+}
+
+func (s SkeemaIntegrationSuite) TestLintPatchesFile(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	createContents := "CREATE TABLE events (\n" +
+		"  id int unsigned NOT NULL,\n" +
+		"  PRIMARY KEY (id)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1;\n"
+	patchesContents := "ALTER TABLE events ADD COLUMN name varchar(30);\n"
+	fs.WriteTestFile(t, "mydb/product/events.sql", createContents)
+	fs.WriteTestFile(t, "mydb/product/patches.sql", patchesContents)
+
+	// Lint should not modify the patches file, nor reformat the CREATE of the
+	// patched table, even though --format is enabled by default
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint --patches-file=patches.sql --allow-charset=latin1,utf8mb4")
+	if contents := fs.ReadTestFile(t, "mydb/product/patches.sql"); contents != patchesContents {
+		t.Errorf("Unexpected contents of patches.sql after lint:\n%s", contents)
+	}
+	if contents := fs.ReadTestFile(t, "mydb/product/events.sql"); contents != createContents {
+		t.Errorf("Unexpected contents of events.sql after lint:\n%s", contents)
+	}
+
+	// Format should fold the patch into the CREATE, and then delete the emptied
+	// patches file
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema format --patches-file=patches.sql")
+	if contents := fs.ReadTestFile(t, "mydb/product/events.sql"); !strings.Contains(contents, "`name` varchar(30)") {
+		t.Errorf("Unexpected contents of events.sql after format:\n%s", contents)
+	}
+	if _, err := os.Stat("mydb/product/patches.sql"); !os.IsNotExist(err) {
+		t.Errorf("Expected patches.sql to be deleted by format, but stat returned err=%v", err)
+	}
+}
+
 func (s SkeemaIntegrationSuite) TestFormatHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -528,6 +647,141 @@ func (s SkeemaIntegrationSuite) TestDiffHandler(t *testing.T) {
 	}
 }
 
+func (s SkeemaIntegrationSuite) TestDiffEmitMigration(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.d.ExecSQL(t, "ALTER TABLE product.posts DROP COLUMN edited_at")
+
+	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema diff --emit-migration=migrations --migration-format=liquibase")
+	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema diff --emit-migration=migrations --alter-wrapper='echo {DDL}'")
+	if _, err := os.Stat("mydb/product/migrations"); err == nil {
+		t.Error("Expected no migrations to be written from failed commands, but migrations dir exists")
+	}
+
+	s.handleCommand(t, CodeDifferencesFound, "mydb/product", "skeema diff --emit-migration=migrations")
+	ups, _ := filepath.Glob("mydb/product/migrations/*_product.up.sql")
+	downs, _ := filepath.Glob("mydb/product/migrations/*_product.down.sql")
+	if len(ups) != 1 || len(downs) != 1 {
+		t.Fatalf("Expected one up and one down migration; instead found %v and %v", ups, downs)
+	}
+	if contents := fs.ReadTestFile(t, ups[0]); !strings.HasPrefix(contents, "ALTER TABLE `posts` ADD COLUMN `edited_at`") {
+		t.Errorf("Unexpected contents of %s:\n%s", ups[0], contents)
+	}
+	if contents := fs.ReadTestFile(t, downs[0]); contents != "ALTER TABLE `posts` DROP COLUMN `edited_at`;\n" {
+		t.Errorf("Unexpected contents of %s:\n%s", downs[0], contents)
+	}
+
+	s.handleCommand(t, CodeDifferencesFound, "mydb/product", "skeema diff --emit-migration=flyway --migration-format=flyway")
+	if undos, _ := filepath.Glob("mydb/product/flyway/U*__product.sql"); len(undos) != 1 {
+		t.Errorf("Expected one Flyway undo migration; instead found %v", undos)
+	}
+	// Push is unaffected by emit-migration; only diff writes migrations
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema push --emit-migration=pushed")
+	if _, err := os.Stat("mydb/product/pushed"); err == nil {
+		t.Error("Expected skeema push to ignore emit-migration, but migrations were written")
+	}
+
+	// With drop-index-grace-period, the up migration makes an index invisible
+	// instead of dropping it, so the down migration must make it visible again
+	// rather than re-adding it
+	if flavor := s.d.Flavor(); flavor.MinMySQL(8) || flavor.MinMariaDB(10, 6) {
+		s.d.ExecSQL(t, "ALTER TABLE product.posts ADD KEY idx_created (created_at)")
+		s.handleCommand(t, CodeDifferencesFound, "mydb/product", "skeema diff --emit-migration=deferred --drop-index-grace-period=1h")
+		ups, _ = filepath.Glob("mydb/product/deferred/*_product.up.sql")
+		downs, _ = filepath.Glob("mydb/product/deferred/*_product.down.sql")
+		if len(ups) != 1 || len(downs) != 1 {
+			t.Fatalf("Expected one up and one down migration; instead found %v and %v", ups, downs)
+		}
+		invisible, visible := "INVISIBLE", "VISIBLE"
+		if flavor.IsMariaDB() {
+			invisible, visible = "IGNORED", "NOT IGNORED"
+		}
+		if contents := fs.ReadTestFile(t, ups[0]); !strings.Contains(contents, "ALTER INDEX `idx_created` "+invisible) {
+			t.Errorf("Unexpected contents of %s:\n%s", ups[0], contents)
+		}
+		if contents := fs.ReadTestFile(t, downs[0]); !strings.Contains(contents, "ALTER INDEX `idx_created` "+visible+";") || strings.Contains(contents, "ADD KEY") {
+			t.Errorf("Unexpected contents of %s:\n%s", downs[0], contents)
+		}
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestStatusHandler(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Freshly-initialized dir should be in sync
+	s.handleCommand(t, CodeSuccess, ".", "skeema status")
+	s.handleCommand(t, CodeSuccess, ".", "skeema status --format=json")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema status --format=xml")
+
+	// Drift should be reported with a nonzero exit code
+	s.d.ExecSQL(t, "ALTER TABLE analytics.pageviews DROP COLUMN domain")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema status")
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema status")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema status")
+
+	// An unreachable environment should be reported with a nonzero exit code,
+	// but can be excluded via --environments
+	s.handleCommand(t, CodeSuccess, ".", "skeema add-environment --host my.staging.invalid --dir mydb staging --connect-options='timeout=10ms'")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema status")
+	s.handleCommand(t, CodeSuccess, ".", "skeema status --environments=production")
+}
+
+func (s SkeemaIntegrationSuite) TestERDHandler(t *testing.T) {
+	s.d.SourceSQL(t, "../foreignkey.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Bad option values
+	s.handleCommand(t, CodeBadConfig, ".", "skeema erd --format=svg")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema erd --only-tables='+'")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema erd --format=mermaid --cluster")
+
+	erdOutput := func(cmdline string) string {
+		t.Helper()
+		return s.handleCommandOutput(t, CodeSuccess, "mydb/product", "%s", cmdline)
+	}
+
+	out := erdOutput("skeema erd")
+	if !strings.HasPrefix(out, "digraph erd {") || !strings.Contains(out, `"product.posts":"user_id" -> "product.users":"id"`) {
+		t.Errorf("Unexpected output from `skeema erd`:\n%s", out)
+	}
+	out = erdOutput("skeema erd --format=mermaid --key-columns-only")
+	if !strings.HasPrefix(out, "erDiagram\n") || !strings.Contains(out, `users ||--o{ posts : "user_fk"`) || strings.Contains(out, " body") {
+		t.Errorf("Unexpected output from `skeema erd --format=mermaid --key-columns-only`:\n%s", out)
+	}
+	out = erdOutput("skeema erd --only-tables=posts --cluster")
+	if !strings.Contains(out, `subgraph "cluster_product"`) || strings.Contains(out, "->") {
+		t.Errorf("Unexpected output from `skeema erd --only-tables=posts --cluster`:\n%s", out)
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestDocHandler(t *testing.T) {
+	s.d.SourceSQL(t, "../foreignkey.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.d.ExecSQL(t, "ALTER TABLE product.posts COMMENT 'Blog posts', MODIFY COLUMN body text COMMENT 'Post | body'")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+
+	// Bad option values
+	s.handleCommand(t, CodeBadConfig, ".", "skeema doc")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema doc --output-dir=docs --format=pdf")
+
+	s.handleCommand(t, CodeSuccess, ".", "skeema doc --output-dir=docs")
+	if contents := fs.ReadTestFile(t, "docs/index.md"); !strings.Contains(contents, "[product](product/index.md)") || !strings.Contains(contents, "[analytics](analytics/index.md)") {
+		t.Errorf("Unexpected contents of docs/index.md:\n%s", contents)
+	}
+	if contents := fs.ReadTestFile(t, "docs/product/index.md"); !strings.Contains(contents, "[posts](posts.md) | InnoDB | Blog posts |") {
+		t.Errorf("Unexpected contents of docs/product/index.md:\n%s", contents)
+	}
+	contents := fs.ReadTestFile(t, "docs/product/posts.md")
+	if !strings.Contains(contents, "| `body` | `text` | YES |") || !strings.Contains(contents, "| Post \\| body |") || !strings.Contains(contents, "[users (id)](users.md)") {
+		t.Errorf("Unexpected contents of docs/product/posts.md:\n%s", contents)
+	}
+
+	s.handleCommand(t, CodeSuccess, ".", "skeema doc --output-dir=html --format=html")
+	if contents := fs.ReadTestFile(t, "html/product/posts.html"); !strings.Contains(contents, "<title>Table product.posts</title>") || !strings.Contains(contents, `<a href="users.html">`) {
+		t.Errorf("Unexpected contents of html/product/posts.html:\n%s", contents)
+	}
+}
+
 func (s SkeemaIntegrationSuite) TestPushHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -648,6 +902,142 @@ func (s SkeemaIntegrationSuite) TestPushHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff --lint-pk=error")
 }
 
+func (s SkeemaIntegrationSuite) TestPushHistory(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Blank state-schema is a config error, but only once there is something to
+	// record
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema push --history --state-schema=''")
+	s.d.ExecSQL(t, "ALTER TABLE product.posts DROP COLUMN edited_at")
+	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema push --history --state-schema=''")
+
+	// Push twice with history enabled, and confirm both pushes were recorded; a
+	// push without history enabled should not record anything
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema push --history")
+	s.d.ExecSQL(t, "ALTER TABLE product.users DROP COLUMN credits")
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema push --history")
+	s.d.ExecSQL(t, "ALTER TABLE product.users DROP COLUMN credits")
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema push")
+	db, err := s.d.CachedConnectionPool("", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to database: %v", err)
+	}
+	type historyRow struct {
+		schemaName, objectType, objectName, statement, outcome string
+	}
+	var rows []historyRow
+	query := "SELECT schema_name, object_type, object_name, statement, outcome FROM _skeema._skeema_history ORDER BY id"
+	res, err := db.Query(query)
+	if err != nil {
+		t.Fatalf("Unexpected error querying history table: %v", err)
+	}
+	for res.Next() {
+		var row historyRow
+		if err := res.Scan(&row.schemaName, &row.objectType, &row.objectName, &row.statement, &row.outcome); err != nil {
+			t.Fatalf("Unexpected error scanning history table: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	res.Close()
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows in history table, instead found %d", len(rows))
+	}
+	for n, expectName := range []string{"posts", "users"} {
+		row := rows[n]
+		if row.schemaName != "product" || row.objectType != "table" || row.objectName != expectName || row.outcome != "success" {
+			t.Errorf("Unexpected history row[%d]: %+v", n, row)
+		} else if !strings.HasPrefix(row.statement, "ALTER TABLE `"+expectName+"` ADD COLUMN") {
+			t.Errorf("Unexpected statement in history row[%d]: %s", n, row.statement)
+		}
+	}
+
+	// Confirm output of skeema history, which is in reverse chronological order
+	out := s.handleCommandOutput(t, CodeSuccess, "mydb/product", "skeema history")
+	usersPos, postsPos := strings.Index(out, "ALTER TABLE `users` ADD COLUMN `credits`"), strings.Index(out, "ALTER TABLE `posts` ADD COLUMN `edited_at`")
+	if usersPos < 0 || postsPos < 0 || usersPos > postsPos || !strings.Contains(out, "TABLE `users`  env=production") {
+		t.Errorf("Unexpected output from `skeema history`:\n%s", out)
+	}
+	out = s.handleCommandOutput(t, CodeSuccess, "mydb/product", "skeema history --object=posts --skip-statements")
+	if strings.Count(out, "\n-- ") != 1 || !strings.Contains(out, "TABLE `posts`") || strings.Contains(out, "ALTER TABLE") {
+		t.Errorf("Unexpected output from `skeema history --object=posts --skip-statements`:\n%s", out)
+	}
+	out = s.handleCommandOutput(t, CodeSuccess, "mydb/product", "skeema history --limit=1")
+	if !strings.Contains(out, "TABLE `users`") || strings.Contains(out, "TABLE `posts`") {
+		t.Errorf("Unexpected output from `skeema history --limit=1`:\n%s", out)
+	}
+	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema history --limit=0")
+	if out := s.handleCommandOutput(t, CodeSuccess, "mydb/analytics", "skeema history"); out != "" {
+		t.Errorf("Expected no output from `skeema history` for schema without history, instead found:\n%s", out)
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestManagedData(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	fs.WriteTestFile(t, "mydb/analytics/rollups.data.sql", "INSERT INTO rollups (metric_id, value) VALUES (1, 10), (2, NULL);\n")
+
+	// Rows declared in the filesystem should be inserted by push
+	s.handleCommand(t, CodeDifferencesFound, "mydb/analytics", "skeema diff")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema push")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema diff")
+
+	// Changed and added rows are reconciled without needing allow-unsafe, but
+	// extra rows can only be deleted with allow-unsafe
+	s.d.ExecSQL(t, "UPDATE analytics.rollups SET value = 5 WHERE metric_id = 1")
+	s.handleCommand(t, CodeDifferencesFound, "mydb/analytics", "skeema diff")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema push")
+	s.d.ExecSQL(t, "INSERT INTO analytics.rollups (metric_id, value) VALUES (3, 30)")
+	s.handleCommand(t, CodeFatalError, "mydb/analytics", "skeema push")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema diff")
+
+	// Pull should rewrite the INSERTs to reflect the live rows
+	s.d.ExecSQL(t, "INSERT INTO analytics.rollups (metric_id, value) VALUES (4, 40)")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	expected := "INSERT INTO `rollups` (`metric_id`, `value`) VALUES\n  (1, 10),\n  (2, NULL),\n  (4, 40);\n"
+	if contents := fs.ReadTestFile(t, "mydb/analytics/rollups.data.sql"); contents != expected {
+		t.Errorf("Unexpected contents of rollups.data.sql after pull:\n%s", contents)
+	}
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema diff")
+
+	// INSERTs outside of *.data.sql files are ignored, and do not cause any rows
+	// to be managed
+	fs.RemoveTestFile(t, "mydb/analytics/rollups.data.sql")
+	contents := fs.ReadTestFile(t, "mydb/analytics/rollups.sql")
+	fs.WriteTestFile(t, "mydb/analytics/rollups.sql", contents+"INSERT INTO rollups (metric_id, value) VALUES (1, 10);\n")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema diff")
+	s.d.ExecSQL(t, "DELETE FROM analytics.rollups WHERE metric_id = 4")
+	s.handleCommand(t, CodeSuccess, "mydb/analytics", "skeema diff")
+}
+
+func (s SkeemaIntegrationSuite) TestVerifyData(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.d.ExecSQL(t, "INSERT INTO product.posts (user_id, body) VALUES (1, 'hello'), (1, 'world')")
+
+	// Adding a unique index on user_id cannot succeed with the existing rows. This
+	// is only detected when verifying against real data.
+	contents := fs.ReadTestFile(t, "mydb/product/posts.sql")
+	contents = strings.Replace(contents, "KEY `user_created`", "UNIQUE KEY `user_id` (`user_id`),\n  KEY `user_created`", 1)
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents)
+	s.handleCommand(t, CodeDifferencesFound, "mydb/product", "skeema diff")
+	s.handleCommand(t, CodeFatalError, "mydb/product", "skeema diff --verify-data=100")
+	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema diff --verify-data=-1")
+	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema diff --verify-data-full-below-size=potato")
+	s.handleCommand(t, CodeFatalError, "mydb/product", "skeema diff --verify-data-full-below-size=10M")
+	s.handleCommand(t, CodeDifferencesFound, "mydb/product", "skeema diff --verify-data-full-below-size=1")
+	s.handleCommand(t, CodeFatalError, "mydb/product", "skeema push --verify-data=100")
+	if product, err := s.d.Schema("product"); err != nil {
+		t.Fatalf("Unexpected error obtaining schema: %s", err)
+	} else if strings.Contains(product.Table("posts").CreateStatement, "UNIQUE KEY") {
+		t.Error("Expected push with --verify-data to be skipped, but unique index was added anyway")
+	}
+
+	// Once the duplicate rows are removed, verification should pass
+	s.d.ExecSQL(t, "DELETE FROM product.posts WHERE body = 'world'")
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema push --verify-data=100")
+	s.handleCommand(t, CodeSuccess, "mydb/product", "skeema diff")
+}
+
 func (s SkeemaIntegrationSuite) TestHelpHandler(t *testing.T) {
 	// Simple tests just to confirm the commands don't error
 	fs.WriteTestFile(t, "fake-etc/skeema", "# hello world")
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -121,7 +122,10 @@ func (s *SkeemaIntegrationSuite) handleCommand(t *testing.T, expectedExitCode in
 	} else {
 		fmt.Fprintf(os.Stderr, "\x1b[37;1m%s$\x1b[0m %s\n", filepath.Join("testdata", ".scratch", pwd), fullCommandLine)
 	}
-	fakeFileSource := mybase.SimpleSource(map[string]string{"password": s.d.Instance.Password})
+	fakeFileSource := mybase.SimpleSource(map[string]string{
+		"password":        s.d.Instance.Password,
+		"workspace-cache": "0", // avoid interference between test cases
+	})
 	cfg := mybase.ParseFakeCLI(t, CommandSuite, fullCommandLine, fakeFileSource)
 	util.AddGlobalConfigFiles(cfg)
 	err := util.ProcessSpecialGlobalOptions(cfg)
@@ -162,6 +166,27 @@ func (s *SkeemaIntegrationSuite) handleCommand(t *testing.T, expectedExitCode in
 	return cfg
 }
 
+// handleCommandOutput behaves like handleCommand, but also captures and
+// returns anything that the command writes to STDOUT.
+func (s *SkeemaIntegrationSuite) handleCommandOutput(t *testing.T, expectedExitCode int, pwd, commandLine string, a ...any) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unable to redirect stdout to a pipe: %v", err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	outputChan := make(chan []byte)
+	go func() {
+		output, _ := io.ReadAll(r)
+		outputChan <- output
+	}()
+	s.handleCommand(t, expectedExitCode, pwd, commandLine, a...)
+	w.Close()
+	os.Stdout = oldStdout
+	return string(<-outputChan)
+}
+
 // verifyFiles compares the files in testdata/.scratch to the files in the
 // specified dir, and fails the test if any differences are found.
 func (s *SkeemaIntegrationSuite) verifyFiles(t *testing.T, cfg *mybase.Config, dirExpectedBase string) {
@@ -431,7 +456,11 @@ func imageForFlavor(t *testing.T, flavor tengo.Flavor) string {
 	if err != nil {
 		t.Fatalf("Unable to determine Docker Engine architecture: %v", err)
 	}
-	image, err := workspace.DockerImageForFlavor(flavor.Family(), arch)
+	runtime, err := tengo.ContainerRuntime()
+	if err != nil {
+		t.Fatalf("Unable to determine container runtime: %v", err)
+	}
+	image, err := workspace.DockerImageForFlavor(flavor.Family(), arch, runtime)
 	if err != nil {
 		t.Fatalf("Unable to locate a Docker image corresponding to flavor %s: %v", flavor.Family(), err)
 	}
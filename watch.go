@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// watchInterval controls how often the filesystem is polled for changes when
+// using --watch.
+var watchInterval = time.Second
+
+// watchedFile tracks the state of a file in a watched directory tree.
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchChange describes a dir which must be processed again due to changes in
+// its *.sql or .skeema files. If recursive is true, its subdirs must also be
+// processed again, since a .skeema file changed and options cascade to subdirs.
+type watchChange struct {
+	dirPath   string
+	recursive bool
+}
+
+// watchTree polls the directory tree rooted at rootPath for changes to *.sql
+// and .skeema files, until the process receives SIGINT or SIGTERM. Each time
+// changes are found, rerun is called once for each affected dir, and should
+// return the paths of any files that it wrote. Changes made by rerun itself,
+// such as reformatting of files, are not considered to be new changes. Any
+// other changes made while rerun is running will be found by the next poll.
+func watchTree(rootPath string, rerun func(watchChange) []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Infof("Watching %s for changes to *.sql and .skeema files. Press Ctrl-C to stop.", rootPath)
+	before := watchSnapshot(rootPath)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopped watching for changes")
+			return nil
+		case <-ticker.C:
+		}
+		after := watchSnapshot(rootPath)
+		changes := watchChanges(rootPath, before, after)
+		before = after
+		for _, change := range changes {
+			watchRefresh(rootPath, before, rerun(change))
+		}
+	}
+}
+
+// watchRefresh updates a snapshot of the directory tree rooted at rootPath
+// with the current state of the supplied file paths, which may have been
+// written or deleted since the snapshot was taken. Paths outside of rootPath,
+// such as symlink destinations, are ignored.
+func watchRefresh(rootPath string, snapshot map[string]watchedFile, paths []string) {
+	for _, path := range paths {
+		if rel, err := filepath.Rel(rootPath, path); err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		} else if info, err := os.Stat(path); err == nil {
+			snapshot[path] = watchedFile{modTime: info.ModTime(), size: info.Size()}
+		} else {
+			delete(snapshot, path)
+		}
+	}
+}
+
+// watchSnapshot returns the modification time and size of each *.sql and
+// .skeema file in the directory tree rooted at rootPath, keyed by file path.
+// Hidden subdirs, such as .git, are skipped.
+func watchSnapshot(rootPath string) map[string]watchedFile {
+	files := make(map[string]watchedFile)
+	filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // files may be removed mid-walk; any other problems will be noticed when parsing dirs
+		} else if d.IsDir() {
+			if path != rootPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if name := d.Name(); name == ".skeema" || strings.HasSuffix(strings.ToLower(name), ".sql") {
+			if info, err := d.Info(); err == nil {
+				files[path] = watchedFile{modTime: info.ModTime(), size: info.Size()}
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+// watchChanges compares two snapshots of the directory tree rooted at
+// rootPath, and returns the dirs affected by any added, modified, or removed
+// files, sorted by path. A *.sql file in a dir lacking a .skeema file is
+// attributed to its closest ancestor dir which has one, since its layout may
+// place files in subdirs. Dirs are omitted if their ancestor dir is already
+// being processed recursively.
+func watchChanges(rootPath string, before, after map[string]watchedFile) []watchChange {
+	recursiveByDir := make(map[string]bool)
+	mark := func(path string) {
+		dirPath := filepath.Dir(path)
+		isOptionFile := filepath.Base(path) == ".skeema"
+		if !isOptionFile {
+			for dirPath != rootPath && strings.HasPrefix(dirPath, rootPath) {
+				if _, err := os.Stat(filepath.Join(dirPath, ".skeema")); err == nil {
+					break
+				}
+				dirPath = filepath.Dir(dirPath)
+			}
+		}
+		recursiveByDir[dirPath] = recursiveByDir[dirPath] || isOptionFile
+	}
+	for path, file := range after {
+		if prev, ok := before[path]; !ok || !prev.modTime.Equal(file.modTime) || prev.size != file.size {
+			mark(path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			mark(path)
+		}
+	}
+
+	dirPaths := make([]string, 0, len(recursiveByDir))
+	for dirPath := range recursiveByDir {
+		dirPaths = append(dirPaths, dirPath)
+	}
+	slices.Sort(dirPaths)
+	var changes []watchChange
+	for _, dirPath := range dirPaths {
+		if n := len(changes); n > 0 && changes[n-1].recursive {
+			if rel, err := filepath.Rel(changes[n-1].dirPath, dirPath); err == nil && !strings.HasPrefix(rel, "..") {
+				continue
+			}
+		}
+		changes = append(changes, watchChange{dirPath: dirPath, recursive: recursiveByDir[dirPath]})
+	}
+	return changes
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skeema/skeema/internal/fs"
+)
+
+func TestWatchChanges(t *testing.T) {
+	rootPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(rootPath, ".skeema"), "host=localhost\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", ".skeema"), "schema=one\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", "foo.sql"), "CREATE TABLE foo (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", "tables", "bar.sql"), "CREATE TABLE bar (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", ".skeema"), "schema=two\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", "baz.sql"), "CREATE TABLE baz (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", "notes.txt"), "not watched\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, ".git", "ignored.sql"), "not watched\n")
+	before := watchSnapshot(rootPath)
+	if len(before) != 6 {
+		t.Fatalf("Expected 6 files in snapshot, instead found %d: %v", len(before), before)
+	}
+
+	// No changes yet
+	if changes := watchChanges(rootPath, before, watchSnapshot(rootPath)); len(changes) > 0 {
+		t.Errorf("Expected no changes, instead found %+v", changes)
+	}
+
+	// Changes to files not being watched are ignored
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", "notes.txt"), "still not watched\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, ".git", "ignored.sql"), "still not watched\n")
+	if changes := watchChanges(rootPath, before, watchSnapshot(rootPath)); len(changes) > 0 {
+		t.Errorf("Expected no changes, instead found %+v", changes)
+	}
+
+	// A file in a subdir lacking a .skeema file is attributed to the parent dir,
+	// and removed files are also changes
+	touch := func(path string) {
+		t.Helper()
+		future := time.Now().Add(time.Minute)
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Fatalf("Unable to change times of %s: %v", path, err)
+		}
+	}
+	touch(filepath.Join(rootPath, "one", "tables", "bar.sql"))
+	fs.RemoveTestFile(t, filepath.Join(rootPath, "two", "baz.sql"))
+	expected := []watchChange{
+		{dirPath: filepath.Join(rootPath, "one")},
+		{dirPath: filepath.Join(rootPath, "two")},
+	}
+	after := watchSnapshot(rootPath)
+	if changes := watchChanges(rootPath, before, after); !slices.Equal(changes, expected) {
+		t.Errorf("Expected changes %+v, instead found %+v", expected, changes)
+	}
+
+	// Changes to a .skeema file are recursive, and subsume changes in subdirs
+	before = after
+	touch(filepath.Join(rootPath, ".skeema"))
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", "new.sql"), "CREATE TABLE new (id int);\n")
+	expected = []watchChange{{dirPath: rootPath, recursive: true}}
+	if changes := watchChanges(rootPath, before, watchSnapshot(rootPath)); !slices.Equal(changes, expected) {
+		t.Errorf("Expected changes %+v, instead found %+v", expected, changes)
+	}
+}
+
+func TestWatchRefresh(t *testing.T) {
+	rootPath := t.TempDir()
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", ".skeema"), "schema=one\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", "foo.sql"), "CREATE TABLE foo (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", "old.sql"), "CREATE TABLE old (id int);\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", ".skeema"), "schema=two\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", "bar.sql"), "CREATE TABLE bar (id int);\n")
+	outsidePath := filepath.Join(t.TempDir(), "outside.sql")
+	fs.WriteTestFile(t, outsidePath, "CREATE TABLE outside (id int);\n")
+	snapshot := watchSnapshot(rootPath)
+
+	// Simulate a rerun which rewrites one file, creates another, and deletes a
+	// third, while a separate file in another dir is edited by the user at the
+	// same time. Only the user's edit should remain a change after the rerun's
+	// files are refreshed in the snapshot.
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", "foo.sql"), "CREATE TABLE foo (\n  id int\n);\n")
+	fs.WriteTestFile(t, filepath.Join(rootPath, "one", "tables", "new.sql"), "CREATE TABLE new (id int);\n")
+	fs.RemoveTestFile(t, filepath.Join(rootPath, "one", "old.sql"))
+	fs.WriteTestFile(t, filepath.Join(rootPath, "two", "bar.sql"), "CREATE TABLE bar (id bigint);\n")
+	written := []string{
+		filepath.Join(rootPath, "one", "foo.sql"),
+		filepath.Join(rootPath, "one", "tables", "new.sql"),
+		filepath.Join(rootPath, "one", "old.sql"),
+		outsidePath,
+	}
+	watchRefresh(rootPath, snapshot, written)
+	if _, ok := snapshot[outsidePath]; ok {
+		t.Errorf("Expected path outside of %s to be ignored, but it was added to the snapshot", rootPath)
+	}
+	expected := []watchChange{{dirPath: filepath.Join(rootPath, "two")}}
+	if changes := watchChanges(rootPath, snapshot, watchSnapshot(rootPath)); !slices.Equal(changes, expected) {
+		t.Errorf("Expected changes %+v, instead found %+v", expected, changes)
+	}
+}